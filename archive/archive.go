@@ -0,0 +1,99 @@
+// Package archive defines the portable channel history format used to move
+// channels between deployments and to hand them to auditors.
+//
+// An archive comes in two encodings carrying the same records.
+//
+// JSON (format "json") is a single document:
+//
+//	{
+//	  "version": 1,
+//	  "exportedAt": "2024-01-01T00:00:00Z",
+//	  "channel":  { Channel },
+//	  "members":  [ Member, ... ],
+//	  "messages": [ Message, ... ],
+//	  "pins":     [ Pin, ... ]
+//	}
+//
+// JSON Lines (format "jsonl") is one envelope per line, in the same order,
+// so it can be produced and consumed without holding the channel in memory:
+//
+//	{"type":"header","data":{"version":1,"exportedAt":"..."}}
+//	{"type":"channel","data":{ Channel }}
+//	{"type":"member","data":{ Member }}
+//	{"type":"message","data":{ Message }}
+//	{"type":"pin","data":{ Pin }}
+//
+// Users are referenced by email so an importer can remap them onto the
+// accounts of the target deployment. Message ids are the ids of the source
+// deployment and are only used to resolve pins. Timestamps are RFC 3339.
+// Attachments are kept as the JSON posted by the integration. Reactions are
+// not stored by this deployment and are therefore not part of version 1.
+package archive
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+const (
+	Version = 1
+
+	FormatJSON      = "json"
+	FormatJSONLines = "jsonl"
+
+	recordHeader  = "header"
+	recordChannel = "channel"
+	recordMember  = "member"
+	recordMessage = "message"
+	recordPin     = "pin"
+)
+
+var ErrUnsupportedFormat = errors.New("unsupported archive format")
+var ErrUnsupportedVersion = errors.New("unsupported archive version")
+var ErrMissingChannel = errors.New("archive has no channel record")
+
+type Header struct {
+	Version    int       `json:"version"`
+	ExportedAt time.Time `json:"exportedAt"`
+}
+
+type Channel struct {
+	Id                int64     `json:"id"`
+	Name              string    `json:"name"`
+	MessageTtlSeconds *int32    `json:"messageTtlSeconds,omitempty"`
+	RetentionPolicy   string    `json:"retentionPolicy"`
+	RetentionValue    *int32    `json:"retentionValue,omitempty"`
+	CreatedAt         time.Time `json:"createdAt"`
+}
+
+type Member struct {
+	Email    string    `json:"email"`
+	Username string    `json:"username"`
+	Role     string    `json:"role"`
+	JoinedAt time.Time `json:"joinedAt"`
+}
+
+type Message struct {
+	Id             int64      `json:"id"`
+	AuthorEmail    string     `json:"authorEmail"`
+	AuthorUsername string     `json:"authorUsername"`
+	Content        string     `json:"content"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	ExpiresAt      *time.Time `json:"expiresAt,omitempty"`
+	// bot messages, posted by integrations on behalf of the author
+	IsBot            bool            `json:"isBot,omitempty"`
+	UsernameOverride *string         `json:"usernameOverride,omitempty"`
+	Attachments      json.RawMessage `json:"attachments,omitempty"`
+}
+
+type Pin struct {
+	MessageId     int64     `json:"messageId"`
+	PinnedByEmail string    `json:"pinnedByEmail"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+// ValidFormat reports whether format names one of the supported encodings.
+func ValidFormat(format string) bool {
+	return format == FormatJSON || format == FormatJSONLines
+}
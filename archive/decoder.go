@@ -0,0 +1,222 @@
+package archive
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Handler receives archive records as they are decoded. Decode stops at the
+// first error returned by a handler method.
+type Handler interface {
+	Channel(header *Header, channel *Channel) error
+	Member(member *Member) error
+	Message(message *Message) error
+	Pin(pin *Pin) error
+}
+
+// maxLineSize bounds a single JSON Lines record.
+const maxLineSize = 4 * 1024 * 1024
+
+// Decode reads an archive in the given format and streams its records to h
+// in archive order. The channel record must come before any other record.
+func Decode(format string, r io.Reader, h Handler) error {
+	switch format {
+	case FormatJSON:
+		return decodeJSON(r, h)
+	case FormatJSONLines:
+		return decodeJSONLines(r, h)
+	default:
+		return ErrUnsupportedFormat
+	}
+}
+
+func checkVersion(header *Header) error {
+	if header.Version != Version {
+		return ErrUnsupportedVersion
+	}
+	return nil
+}
+
+func decodeJSONLines(r io.Reader, h Handler) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+
+	var header *Header
+	channelSeen := false
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var record struct {
+			Type string          `json:"type"`
+			Data json.RawMessage `json:"data"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return fmt.Errorf("archive: line %d: %w", line, err)
+		}
+
+		if record.Type != recordHeader && record.Type != recordChannel && !channelSeen {
+			return ErrMissingChannel
+		}
+
+		var err error
+		switch record.Type {
+		case recordHeader:
+			header = &Header{}
+			if err = json.Unmarshal(record.Data, header); err == nil {
+				err = checkVersion(header)
+			}
+		case recordChannel:
+			if header == nil {
+				return fmt.Errorf("archive: line %d: channel before header", line)
+			}
+			channel := &Channel{}
+			if err = json.Unmarshal(record.Data, channel); err == nil {
+				channelSeen = true
+				err = h.Channel(header, channel)
+			}
+		case recordMember:
+			member := &Member{}
+			if err = json.Unmarshal(record.Data, member); err == nil {
+				err = h.Member(member)
+			}
+		case recordMessage:
+			message := &Message{}
+			if err = json.Unmarshal(record.Data, message); err == nil {
+				err = h.Message(message)
+			}
+		case recordPin:
+			pin := &Pin{}
+			if err = json.Unmarshal(record.Data, pin); err == nil {
+				err = h.Pin(pin)
+			}
+		default:
+			err = fmt.Errorf("unknown record type %q", record.Type)
+		}
+		if err != nil {
+			return fmt.Errorf("archive: line %d: %w", line, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	if !channelSeen {
+		return ErrMissingChannel
+	}
+	return nil
+}
+
+// decodeJSON walks the document token by token so the arrays are streamed
+// rather than loaded whole.
+func decodeJSON(r io.Reader, h Handler) error {
+	dec := json.NewDecoder(r)
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+
+	header := &Header{}
+	channelSeen := false
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		key, _ := token.(string)
+
+		switch key {
+		case "version":
+			if err := dec.Decode(&header.Version); err != nil {
+				return err
+			}
+		case "exportedAt":
+			if err := dec.Decode(&header.ExportedAt); err != nil {
+				return err
+			}
+		case "channel":
+			if err := checkVersion(header); err != nil {
+				return err
+			}
+			channel := &Channel{}
+			if err := dec.Decode(channel); err != nil {
+				return err
+			}
+			channelSeen = true
+			if err := h.Channel(header, channel); err != nil {
+				return err
+			}
+		case "members":
+			if !channelSeen {
+				return ErrMissingChannel
+			}
+			err = decodeArray(dec, func() error {
+				member := &Member{}
+				if err := dec.Decode(member); err != nil {
+					return err
+				}
+				return h.Member(member)
+			})
+		case "messages":
+			if !channelSeen {
+				return ErrMissingChannel
+			}
+			err = decodeArray(dec, func() error {
+				message := &Message{}
+				if err := dec.Decode(message); err != nil {
+					return err
+				}
+				return h.Message(message)
+			})
+		case "pins":
+			if !channelSeen {
+				return ErrMissingChannel
+			}
+			err = decodeArray(dec, func() error {
+				pin := &Pin{}
+				if err := dec.Decode(pin); err != nil {
+					return err
+				}
+				return h.Pin(pin)
+			})
+		default:
+			var skip json.RawMessage
+			err = dec.Decode(&skip)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if !channelSeen {
+		return ErrMissingChannel
+	}
+	return expectDelim(dec, '}')
+}
+
+func decodeArray(dec *json.Decoder, decodeElement func() error) error {
+	if err := expectDelim(dec, '['); err != nil {
+		return err
+	}
+	for dec.More() {
+		if err := decodeElement(); err != nil {
+			return err
+		}
+	}
+	return expectDelim(dec, ']')
+}
+
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if d, ok := token.(json.Delim); !ok || d != delim {
+		return fmt.Errorf("archive: expected %q, got %v", delim, token)
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package archive
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Encoder writes archive records. Records must be written in archive order:
+// the channel first, then members, messages and pins.
+type Encoder interface {
+	WriteChannel(header *Header, channel *Channel) error
+	WriteMember(member *Member) error
+	WriteMessage(message *Message) error
+	WritePin(pin *Pin) error
+	Close() error
+}
+
+func NewEncoder(format string, w io.Writer) (Encoder, error) {
+	switch format {
+	case FormatJSON:
+		return &jsonEncoder{w: bufio.NewWriter(w), section: -1}, nil
+	case FormatJSONLines:
+		bw := bufio.NewWriter(w)
+		return &jsonLinesEncoder{w: bw, enc: json.NewEncoder(bw)}, nil
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+}
+
+type envelope struct {
+	Type string      `json:"type"`
+	Data interface{} `json:"data"`
+}
+
+type jsonLinesEncoder struct {
+	w   *bufio.Writer
+	enc *json.Encoder
+}
+
+func (e *jsonLinesEncoder) WriteChannel(header *Header, channel *Channel) error {
+	if err := e.enc.Encode(envelope{recordHeader, header}); err != nil {
+		return err
+	}
+	return e.enc.Encode(envelope{recordChannel, channel})
+}
+
+func (e *jsonLinesEncoder) WriteMember(member *Member) error {
+	return e.enc.Encode(envelope{recordMember, member})
+}
+
+func (e *jsonLinesEncoder) WriteMessage(message *Message) error {
+	return e.enc.Encode(envelope{recordMessage, message})
+}
+
+func (e *jsonLinesEncoder) WritePin(pin *Pin) error {
+	return e.enc.Encode(envelope{recordPin, pin})
+}
+
+func (e *jsonLinesEncoder) Close() error {
+	return e.w.Flush()
+}
+
+// jsonSections are the arrays of the JSON document in the order they are
+// written.
+var jsonSections = []string{"members", "messages", "pins"}
+
+// jsonEncoder streams a single JSON document, opening and closing the arrays
+// as records move from one section to the next.
+type jsonEncoder struct {
+	w       *bufio.Writer
+	section int // index in jsonSections, -1 before the first array
+	count   int // records written to the current section
+	started bool
+}
+
+func (e *jsonEncoder) WriteChannel(header *Header, channel *Channel) error {
+	if e.started {
+		return fmt.Errorf("archive: channel already written")
+	}
+	e.started = true
+
+	exportedAt, err := json.Marshal(header.ExportedAt)
+	if err != nil {
+		return err
+	}
+	channelBytes, err := json.Marshal(channel)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(e.w, `{"version":%d,"exportedAt":%s,"channel":%s`, header.Version, exportedAt, channelBytes)
+	return err
+}
+
+func (e *jsonEncoder) WriteMember(member *Member) error {
+	return e.writeRecord(0, member)
+}
+
+func (e *jsonEncoder) WriteMessage(message *Message) error {
+	return e.writeRecord(1, message)
+}
+
+func (e *jsonEncoder) WritePin(pin *Pin) error {
+	return e.writeRecord(2, pin)
+}
+
+func (e *jsonEncoder) writeRecord(section int, record interface{}) error {
+	if !e.started {
+		return ErrMissingChannel
+	}
+	if err := e.advanceTo(section); err != nil {
+		return err
+	}
+
+	if e.count > 0 {
+		if err := e.w.WriteByte(','); err != nil {
+			return err
+		}
+	}
+	recordBytes, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	if _, err := e.w.Write(recordBytes); err != nil {
+		return err
+	}
+	e.count++
+	return nil
+}
+
+// advanceTo closes the arrays before section and opens it, writing empty
+// arrays for any section that had no records.
+func (e *jsonEncoder) advanceTo(section int) error {
+	if section < e.section {
+		return fmt.Errorf("archive: %s written after %s", jsonSections[section], jsonSections[e.section])
+	}
+
+	for e.section < section {
+		if e.section >= 0 {
+			if err := e.w.WriteByte(']'); err != nil {
+				return err
+			}
+		}
+		e.section++
+		e.count = 0
+		if _, err := fmt.Fprintf(e.w, `,"%s":[`, jsonSections[e.section]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *jsonEncoder) Close() error {
+	if !e.started {
+		return ErrMissingChannel
+	}
+	if err := e.advanceTo(len(jsonSections) - 1); err != nil {
+		return err
+	}
+	if _, err := e.w.WriteString("]}\n"); err != nil {
+		return err
+	}
+	return e.w.Flush()
+}
@@ -0,0 +1,90 @@
+// Package avatar turns uploaded images into square avatars and stores them.
+package avatar
+
+import (
+	"bytes"
+	"image"
+	"image/draw"
+	_ "image/gif"
+	_ "image/jpeg"
+	"image/png"
+	"io"
+	"project/config"
+	"project/constants"
+)
+
+// Resize decodes a png, jpeg or gif read from r, crops it to a centered
+// square and scales it down to at most cfg.AvatarSize pixels a side. It
+// returns the png encoding. The size of the upload and of the image are
+// checked before the image is decoded.
+func Resize(r io.Reader, cfg config.ProfileConfig) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, cfg.AvatarMaxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > cfg.AvatarMaxBytes {
+		return nil, constants.ErrImageTooLarge
+	}
+
+	imageConfig, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || imageConfig.Width <= 0 || imageConfig.Height <= 0 {
+		return nil, constants.ErrInvalidImage
+	}
+	if imageConfig.Width*imageConfig.Height > cfg.AvatarMaxPixels {
+		return nil, constants.ErrImageTooLarge
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, constants.ErrInvalidImage
+	}
+
+	bounds := img.Bounds()
+	side := min(bounds.Dx(), bounds.Dy())
+	square := image.NewRGBA(image.Rect(0, 0, side, side))
+	origin := image.Pt(bounds.Min.X+(bounds.Dx()-side)/2, bounds.Min.Y+(bounds.Dy()-side)/2)
+	draw.Draw(square, square.Bounds(), img, origin, draw.Src)
+
+	var out bytes.Buffer
+	err = png.Encode(&out, scaleDown(square, min(side, cfg.AvatarSize)))
+	if err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// scaleDown shrinks the square src to size pixels a side, each pixel the
+// average of the source pixels it covers. RGBA is premultiplied, so
+// averaging does not bleed the color of transparent pixels.
+func scaleDown(src *image.RGBA, size int) *image.RGBA {
+	side := src.Bounds().Dx()
+	if size == side {
+		return src
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	for dy := 0; dy < size; dy++ {
+		sy0, sy1 := dy*side/size, (dy+1)*side/size
+		for dx := 0; dx < size; dx++ {
+			sx0, sx1 := dx*side/size, (dx+1)*side/size
+
+			var sum [4]int
+			for sy := sy0; sy < sy1; sy++ {
+				row := src.Pix[sy*src.Stride+sx0*4 : sy*src.Stride+sx1*4]
+				for i := 0; i < len(row); i += 4 {
+					sum[0] += int(row[i])
+					sum[1] += int(row[i+1])
+					sum[2] += int(row[i+2])
+					sum[3] += int(row[i+3])
+				}
+			}
+
+			count := (sy1 - sy0) * (sx1 - sx0)
+			offset := dy*dst.Stride + dx*4
+			for c := range sum {
+				dst.Pix[offset+c] = uint8((sum[c] + count/2) / count)
+			}
+		}
+	}
+	return dst
+}
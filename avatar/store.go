@@ -0,0 +1,96 @@
+package avatar
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// keyBytes is the entropy of avatar keys, which make the avatar urls
+// unguessable and new on every upload, so they can be cached forever.
+const keyBytes = 16
+
+// Store keeps avatars as png files in a directory.
+type Store struct {
+	dir string
+}
+
+func NewStore(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &Store{dir}, nil
+}
+
+// Save stores a png under a new key and returns the key.
+func (s *Store) Save(data []byte) (string, error) {
+	random := make([]byte, keyBytes)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	key := hex.EncodeToString(random)
+
+	// written aside then renamed, a half written file is never served
+	tmp, err := os.CreateTemp(s.dir, "upload-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), s.path(key)); err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+// Remove deletes the avatar of key, if it is still there.
+func (s *Store) Remove(key string) error {
+	err := os.Remove(s.path(key))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// Path returns the file of the avatar named file in its url, false when file
+// is not an avatar name.
+func (s *Store) Path(file string) (string, bool) {
+	key, ok := keyOf(file)
+	if !ok {
+		return "", false
+	}
+	return s.path(key), true
+}
+
+func (s *Store) path(key string) string {
+	return filepath.Join(s.dir, key+".png")
+}
+
+// URL returns the path avatars of key are served at.
+func URL(key string) string {
+	return "/avatars/" + key + ".png"
+}
+
+func keyOf(file string) (string, bool) {
+	key, ok := strings.CutSuffix(file, ".png")
+	if !ok || len(key) != 2*keyBytes {
+		return "", false
+	}
+	// lowercase only, as keys are made
+	decoded, err := hex.DecodeString(key)
+	if err != nil || hex.EncodeToString(decoded) != key {
+		return "", false
+	}
+	return key, true
+}
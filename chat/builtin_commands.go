@@ -0,0 +1,294 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"project/constants"
+	db "project/db/sqlc"
+	"project/utils"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	maxTopicLength      = 250
+	defaultMuteDuration = time.Hour
+)
+
+func (r *Commands) registerBuiltins() {
+	r.Register("help", "/help", r.help)
+	r.Register("join", "/join <channel>", joinCommand)
+	r.Register("leave", "/leave [channel]", leaveCommand)
+	r.Register("topic", "/topic [text]", topicCommand)
+	r.Register("me", "/me <action>", meCommand)
+	r.Register("mute", "/mute @user [duration|off]", muteCommand)
+	r.Register("invite", "/invite @user [channel]", inviteCommand)
+}
+
+// joinCommand: /join <channel>, by name or id.
+func joinCommand(ctx context.Context, hub *Hub, cmd *Command) error {
+	if len(cmd.Args) == 0 {
+		return constants.ErrInvalidCommand
+	}
+
+	channel, err := hub.findChannel(ctx, cmd.Args)
+	if err != nil {
+		return err
+	}
+
+	membership, err := hub.AddMember(ctx, cmd.Client.Id, channel.ID)
+	if err != nil {
+		return err
+	}
+
+	hub.MembershipUpdates <- membership
+	cmd.Reply(fmt.Sprintf("joined #%s", channel.Name))
+	return nil
+}
+
+// leaveCommand: /leave [channel], the current channel when none is given.
+func leaveCommand(ctx context.Context, hub *Hub, cmd *Command) error {
+	channelId := cmd.ChannelId
+	if len(cmd.Args) > 0 {
+		channel, err := hub.findChannel(ctx, cmd.Args)
+		if err != nil {
+			return err
+		}
+		channelId = channel.ID
+	}
+
+	membership, err := hub.repo.DeleteMembership(ctx, &db.DeleteMembershipParams{UserID: cmd.Client.Id, ChannelID: channelId})
+	if err != nil {
+		if errors.Is(err, constants.ErrNoRows) {
+			return constants.ErrNotChannelMember
+		}
+		return err
+	}
+
+	hub.MembershipRemovals <- membership
+	cmd.Reply("left the channel")
+	return nil
+}
+
+// topicCommand: /topic shows the topic of the current channel, /topic <text>
+// sets it. Only channel admins may set the topic.
+func topicCommand(ctx context.Context, hub *Hub, cmd *Command) error {
+	if len(cmd.Args) == 0 {
+		channel, err := hub.repo.GetChannelById(ctx, cmd.ChannelId)
+		if err != nil {
+			return err
+		}
+		if channel.Topic == nil {
+			cmd.Reply("no topic is set")
+			return nil
+		}
+		cmd.Reply(fmt.Sprintf("topic: %s", *channel.Topic))
+		return nil
+	}
+
+	if len([]rune(cmd.Args)) > maxTopicLength {
+		return fmt.Errorf("%w: topic is longer than %d characters", constants.ErrInvalidCommand, maxTopicLength)
+	}
+	if err := hub.requireChannelAdmin(ctx, cmd.Client.Id, cmd.ChannelId); err != nil {
+		return err
+	}
+
+	channel, err := hub.repo.UpdateChannelTopic(ctx, &db.UpdateChannelTopicParams{Topic: &cmd.Args, ID: cmd.ChannelId})
+	if err != nil {
+		return err
+	}
+
+	hub.WriteBroadcast <- &Message{
+		Type:      EVENT_CHANNEL_UPDATED,
+		Content:   fmt.Sprintf("topic changed to: %s", *channel.Topic),
+		ChannelId: channel.ID,
+		Username:  cmd.Client.Username,
+		Payload:   map[string]interface{}{"topic": channel.Topic},
+	}
+	return nil
+}
+
+// meCommand: /me <action> posts "_username action_" as a regular message.
+func meCommand(ctx context.Context, hub *Hub, cmd *Command) error {
+	if len(cmd.Args) == 0 {
+		return constants.ErrInvalidCommand
+	}
+	if err := hub.requireUnmuted(ctx, cmd.Client.Id, cmd.ChannelId); err != nil {
+		return err
+	}
+
+	_, err := hub.PostMessage(ctx, cmd.Client.Username, &db.CreateMessageParams{
+		ChannelID: cmd.ChannelId,
+		UserID:    cmd.Client.Id,
+		Content:   fmt.Sprintf("_%s %s_", cmd.Client.Username, cmd.Args),
+		IsBot:     cmd.Client.IsBot,
+	})
+	return err
+}
+
+// muteCommand: /mute @user [duration|off] keeps a member from posting in the
+// current channel for the duration, an hour by default. Only channel admins
+// may mute.
+func muteCommand(ctx context.Context, hub *Hub, cmd *Command) error {
+	username, arg, _ := strings.Cut(cmd.Args, " ")
+	if len(username) == 0 {
+		return constants.ErrInvalidCommand
+	}
+	if err := hub.requireChannelAdmin(ctx, cmd.Client.Id, cmd.ChannelId); err != nil {
+		return err
+	}
+
+	user, err := hub.findUser(ctx, username)
+	if err != nil {
+		return err
+	}
+
+	var mutedUntil *time.Time
+	arg = strings.TrimSpace(arg)
+	if arg != "off" {
+		duration := defaultMuteDuration
+		if len(arg) > 0 {
+			duration, err = time.ParseDuration(arg)
+			if err != nil || duration <= 0 {
+				return fmt.Errorf("%w: invalid duration %s", constants.ErrInvalidCommand, arg)
+			}
+		}
+		until := time.Now().Add(duration)
+		mutedUntil = &until
+	}
+
+	_, err = hub.repo.UpdateMembershipMutedUntil(ctx, &db.UpdateMembershipMutedUntilParams{
+		MutedUntil: mutedUntil,
+		UserID:     user.ID,
+		ChannelID:  cmd.ChannelId,
+	})
+	if err != nil {
+		if errors.Is(err, constants.ErrNoRows) {
+			return constants.ErrNotChannelMember
+		}
+		return err
+	}
+
+	// the muted user may be connected to another node
+	notice, reply := "you were unmuted", fmt.Sprintf("unmuted %s", user.Username)
+	if mutedUntil != nil {
+		until := mutedUntil.Format(time.RFC3339)
+		notice, reply = fmt.Sprintf("you are muted until %s", until), fmt.Sprintf("muted %s until %s", user.Username, until)
+	}
+	hub.WriteBroadcast <- &Message{
+		Type:        EVENT_COMMAND_RESPONSE,
+		Content:     notice,
+		ChannelId:   cmd.ChannelId,
+		Username:    cmd.Client.Username,
+		RecipientId: user.ID,
+	}
+	cmd.Reply(reply)
+	return nil
+}
+
+// inviteCommand: /invite @user [channel] adds a user to a channel the
+// inviting member belongs to, the current channel when none is given.
+func inviteCommand(ctx context.Context, hub *Hub, cmd *Command) error {
+	username, channelArg, _ := strings.Cut(cmd.Args, " ")
+	if len(username) == 0 {
+		return constants.ErrInvalidCommand
+	}
+
+	channelId := cmd.ChannelId
+	if channelArg = strings.TrimSpace(channelArg); len(channelArg) > 0 {
+		channel, err := hub.findChannel(ctx, channelArg)
+		if err != nil {
+			return err
+		}
+		channelId = channel.ID
+	}
+
+	_, err := hub.repo.GetMembership(ctx, &db.GetMembershipParams{UserID: cmd.Client.Id, ChannelID: channelId})
+	if err != nil {
+		if errors.Is(err, constants.ErrNoRows) {
+			return constants.ErrNotChannelMember
+		}
+		return err
+	}
+
+	user, err := hub.findUser(ctx, username)
+	if err != nil {
+		return err
+	}
+
+	membership, err := hub.AddMember(ctx, user.ID, channelId)
+	if err != nil {
+		return err
+	}
+
+	hub.MembershipUpdates <- membership
+	cmd.Reply(fmt.Sprintf("invited %s", user.Username))
+	return nil
+}
+
+// findChannel looks a channel up by id or by name, with or without a leading
+// "#".
+func (hub *Hub) findChannel(ctx context.Context, arg string) (*db.Channel, error) {
+	arg = strings.TrimPrefix(arg, "#")
+
+	var channel *db.Channel
+	var err error
+	if id, parseErr := strconv.ParseInt(arg, 10, 64); parseErr == nil {
+		channel, err = hub.repo.GetChannelById(ctx, id)
+	} else {
+		channel, err = hub.repo.GetChannelByName(ctx, arg)
+	}
+	if errors.Is(err, constants.ErrNoRows) {
+		return nil, fmt.Errorf("channel %s not found", arg)
+	}
+	return channel, err
+}
+
+// findUser looks a user up by username, with or without a leading "@".
+func (hub *Hub) findUser(ctx context.Context, arg string) (*db.User, error) {
+	arg = strings.TrimPrefix(arg, "@")
+
+	user, err := hub.repo.GetUserByUsername(ctx, arg)
+	if errors.Is(err, constants.ErrNoRows) {
+		return nil, fmt.Errorf("user %s not found", arg)
+	}
+	return user, err
+}
+
+// AddMember makes the user a member of the channel. It returns
+// constants.ErrAlreadyChannelMember when the user has joined it already.
+func (hub *Hub) AddMember(ctx context.Context, userId int64, channelId int64) (*db.Membership, error) {
+	membership, err := hub.repo.CreateMembership(ctx, &db.CreateMembershipParams{
+		UserID:    userId,
+		ChannelID: channelId,
+		Role:      constants.MembershipRoleMember,
+	})
+	if utils.ConstraintName(err) == constants.MembershipsUserChannelKey {
+		return nil, constants.ErrAlreadyChannelMember
+	}
+	return membership, err
+}
+
+// requireMember returns the membership of the user in the channel, or
+// constants.ErrNotChannelMember when the user has not joined it.
+func (hub *Hub) requireMember(ctx context.Context, userId int64, channelId int64) (*db.Membership, error) {
+	membership, err := hub.repo.GetMembership(ctx, &db.GetMembershipParams{UserID: userId, ChannelID: channelId})
+	if errors.Is(err, constants.ErrNoRows) {
+		return nil, constants.ErrNotChannelMember
+	}
+	return membership, err
+}
+
+func (hub *Hub) requireChannelAdmin(ctx context.Context, userId int64, channelId int64) error {
+	membership, err := hub.requireMember(ctx, userId, channelId)
+	if err != nil {
+		return err
+	}
+
+	if membership.Role != constants.MembershipRoleAdmin {
+		return constants.ErrNotChannelAdmin
+	}
+	return nil
+}
@@ -7,24 +7,46 @@ package chat
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"project/constants"
 	db "project/db/sqlc"
 	"project/logger"
+	"project/markdown"
+	"project/unfurl"
+	"project/utils"
+	"strings"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 )
 
 type Message struct {
-	Content   string `json:"content"`
-	ChannelId int64  `json:"channelId"`
-	Username  string `json:"username"`
+	Id          int64            `json:"id,omitempty"`
+	Type        string           `json:"type,omitempty"`
+	Content     string           `json:"content"`
+	ChannelId   int64            `json:"channelId"`
+	Username    string           `json:"username"`
+	RecipientId int64            `json:"recipientId,omitempty"` // delivered only to this user when set
+	Ttl         int32            `json:"ttl,omitempty"`         // seconds until the message expires
+	ExpiresAt   *time.Time       `json:"expiresAt,omitempty"`
+	IsBot       bool             `json:"isBot,omitempty"`
+	Attachments json.RawMessage  `json:"attachments,omitempty"`
+	Spans       json.RawMessage  `json:"spans,omitempty"`    // sanitized markdown of Content
+	Previews    []unfurl.Preview `json:"previews,omitempty"` // link previews, sent with message.updated
+	Payload     interface{}      `json:"payload,omitempty"`
+	spans       []markdown.Span  // parsed Spans, for unfurling
 }
 
 type Client struct {
 	Id          int64
+	SessionId   uuid.UUID // uuid.Nil for api keys
 	Username    string
 	Conn        *websocket.Conn
 	MessageChan chan *Message
 	Memberships []*db.Membership
+	IsBot       bool
+	ReadOnly    bool // api keys without the ws:write scope only receive
 }
 
 func (c *Client) WritePump(hub *Hub) {
@@ -69,11 +91,78 @@ func (c *Client) ReadPump(hub *Hub) {
 			logger.Error(context.Background(), "ReadPump", logger.Field("unmarshal error", err.Error()))
 			continue
 		}
+		if c.ReadOnly {
+			logger.Error(context.Background(), "ReadPump", logger.Field("error", "read only client"))
+			continue
+		}
 		if msg.Username != c.Username {
 			logger.Error(context.Background(), "ReadPump", logger.Field("error", "unauthorized user"))
 			continue
 		}
 
-		hub.WriteBroadcast <- msg
+		// commands count against the limit of the user too
+		wait, err := hub.throttleUser(context.Background(), c.Id)
+		if err != nil {
+			c.MessageChan <- &Message{Type: EVENT_MESSAGE_REJECTED, Content: err.Error(), ChannelId: msg.ChannelId, Payload: retryPayload(wait)}
+			continue
+		}
+
+		// slash commands are answered on this connection and never broadcast
+		if cmd, ok := parseCommand(msg.Content); ok {
+			cmd.ChannelId = msg.ChannelId
+			cmd.Client = c
+			hub.RunCommand(context.Background(), cmd)
+			continue
+		}
+		if strings.HasPrefix(msg.Content, "//") {
+			msg.Content = msg.Content[1:]
+		}
+
+		// the channel comes from the client, only unmuted members may post
+		err = hub.requireUnmuted(context.Background(), c.Id, msg.ChannelId)
+		if err != nil {
+			logger.Error(context.Background(), "ReadPump", logger.Field("error", err.Error()))
+			c.MessageChan <- &Message{Type: EVENT_MESSAGE_REJECTED, Content: err.Error(), ChannelId: msg.ChannelId}
+			continue
+		}
+
+		wait, err = hub.throttleChannel(context.Background(), c.Id, msg.ChannelId)
+		if err != nil {
+			logger.Error(context.Background(), "ReadPump", logger.Field("error", err.Error()))
+			if wait > 0 {
+				c.MessageChan <- &Message{Type: EVENT_MESSAGE_REJECTED, Content: err.Error(), ChannelId: msg.ChannelId, Payload: retryPayload(wait)}
+			}
+			continue
+		}
+
+		// events, bot flags and attachments are only produced by the server,
+		// PostMessage builds the broadcast from the stored row
+		createMessageParams := &db.CreateMessageParams{
+			ChannelID: msg.ChannelId,
+			UserID:    c.Id,
+			Content:   msg.Content,
+			IsBot:     c.IsBot,
+		}
+		if msg.Ttl > 0 {
+			createMessageParams.TtlSeconds = &msg.Ttl
+		}
+		_, err = hub.PostMessage(context.Background(), c.Username, createMessageParams)
+		if err != nil {
+			logger.Error(context.Background(), "ReadPump", logger.Field("create message error", err.Error()))
+			// rejected content, such as a message over the length limit or
+			// one refused by moderation
+			switch {
+			case errors.Is(err, constants.ErrMessageTooLong), errors.Is(err, constants.ErrInvalidMessageEncoding), errors.Is(err, constants.ErrMessageRejected):
+				c.MessageChan <- &Message{Type: EVENT_MESSAGE_REJECTED, Content: err.Error(), ChannelId: msg.ChannelId}
+			case errors.Is(err, constants.ErrMessageHeld):
+				c.MessageChan <- &Message{Type: EVENT_MESSAGE_HELD, Content: err.Error(), ChannelId: msg.ChannelId}
+			}
+			continue
+		}
 	}
 }
+
+// retryPayload tells a throttled client how many seconds to wait.
+func retryPayload(wait time.Duration) map[string]int {
+	return map[string]int{"retryAfter": utils.RetryAfterSeconds(wait)}
+}
@@ -0,0 +1,237 @@
+package chat
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"project/config"
+	"project/constants"
+	db "project/db/sqlc"
+	"project/logger"
+	"project/webhooks"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	// external command response types
+	ResponseTypeEphemeral = "ephemeral"
+	ResponseTypeInChannel = "in_channel"
+
+	// maxCommandResponseSize bounds the body read from external commands.
+	maxCommandResponseSize = 64 << 10
+)
+
+// Command is a slash command typed by a client, "/topic release day" gives
+// Name "topic" and Args "release day".
+type Command struct {
+	Name      string
+	Args      string
+	ChannelId int64
+	Client    *Client
+}
+
+// CommandHandler runs a command on the reader goroutine of the invoking
+// client. A returned error is shown to that client only.
+type CommandHandler func(ctx context.Context, hub *Hub, cmd *Command) error
+
+type commandEntry struct {
+	usage   string
+	handler CommandHandler
+}
+
+// Commands is the slash command registry of a hub. It is filled before the
+// server starts accepting connections and only read afterwards.
+type Commands struct {
+	entries map[string]*commandEntry
+	client  *http.Client
+}
+
+func newCommands(cfg *config.StartupConfig) *Commands {
+	commands := &Commands{
+		entries: make(map[string]*commandEntry),
+		client:  &http.Client{Timeout: cfg.Commands.Timeout},
+	}
+	commands.registerBuiltins()
+	for _, external := range cfg.Commands.External {
+		commands.Register(external.Name, external.Usage, commands.externalHandler(external))
+	}
+	return commands
+}
+
+// Register adds a command, replacing any command of the same name.
+func (r *Commands) Register(name string, usage string, handler CommandHandler) {
+	r.entries[strings.ToLower(name)] = &commandEntry{usage: usage, handler: handler}
+}
+
+// parseCommand reports whether content is a slash command. Content starting
+// with "//" is a regular message with the first slash removed.
+func parseCommand(content string) (*Command, bool) {
+	if !strings.HasPrefix(content, "/") || strings.HasPrefix(content, "//") {
+		return nil, false
+	}
+
+	name, args := content[1:], ""
+	if i := strings.IndexFunc(name, unicode.IsSpace); i >= 0 {
+		name, args = name[:i], name[i:]
+	}
+	if len(name) == 0 {
+		return nil, false
+	}
+
+	return &Command{Name: strings.ToLower(name), Args: strings.TrimSpace(args)}, true
+}
+
+// RunCommand dispatches the command and reports the outcome to the invoking
+// client.
+func (hub *Hub) RunCommand(ctx context.Context, cmd *Command) {
+	entry, ok := hub.commands.entries[cmd.Name]
+	if !ok {
+		cmd.Reply(fmt.Sprintf("%s /%s, try /help", constants.ErrUnknownCommand.Error(), cmd.Name))
+		return
+	}
+
+	err := entry.handler(ctx, hub, cmd)
+	if err != nil {
+		logger.Error(ctx, "RunCommand", logger.Field("command", cmd.Name), logger.Field("error", err.Error()))
+		cmd.Reply(err.Error())
+	}
+}
+
+// Reply sends an ephemeral message to the invoking connection only. It is
+// neither stored nor fanned out through redis.
+func (cmd *Command) Reply(content string) {
+	cmd.Client.MessageChan <- &Message{
+		Type:      EVENT_COMMAND_RESPONSE,
+		Content:   content,
+		ChannelId: cmd.ChannelId,
+		Payload:   map[string]string{"command": cmd.Name},
+	}
+}
+
+// requireUnmuted returns constants.ErrNotChannelMember when the user has not
+// joined the channel and constants.ErrChannelMuted while the user is muted in it.
+func (hub *Hub) requireUnmuted(ctx context.Context, userId int64, channelId int64) error {
+	membership, err := hub.requireMember(ctx, userId, channelId)
+	if err != nil {
+		return err
+	}
+
+	if membership.MutedUntil != nil && time.Now().Before(*membership.MutedUntil) {
+		return constants.ErrChannelMuted
+	}
+	return nil
+}
+
+func (r *Commands) help(ctx context.Context, hub *Hub, cmd *Command) error {
+	names := make([]string, 0, len(r.entries))
+	for name := range r.entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString("available commands:")
+	for _, name := range names {
+		b.WriteString("\n")
+		b.WriteString(r.entries[name].usage)
+	}
+	cmd.Reply(b.String())
+	return nil
+}
+
+type externalCommandRequest struct {
+	Command   string `json:"command"`
+	Text      string `json:"text"`
+	ChannelId int64  `json:"channelId"`
+	UserId    int64  `json:"userId"`
+	Username  string `json:"username"`
+}
+
+type externalCommandResponse struct {
+	ResponseType string `json:"responseType"`
+	Text         string `json:"text"`
+}
+
+// externalHandler forwards the command to the configured endpoint and posts
+// the text it answers with. Ephemeral responses go back to the invoking
+// client only, in_channel responses are posted as a bot message under the
+// command name.
+func (r *Commands) externalHandler(cfg config.ExternalCommandConfig) CommandHandler {
+	return func(ctx context.Context, hub *Hub, cmd *Command) error {
+		body, err := json.Marshal(&externalCommandRequest{
+			Command:   cmd.Name,
+			Text:      cmd.Args,
+			ChannelId: cmd.ChannelId,
+			UserId:    cmd.Client.Id,
+			Username:  cmd.Client.Username,
+		})
+		if err != nil {
+			return err
+		}
+
+		resp, err := r.call(ctx, cfg, body)
+		if err != nil {
+			logger.Error(ctx, "externalHandler", logger.Field("command", cmd.Name), logger.Field("error", err.Error()))
+			return constants.ErrCommandFailed
+		}
+		if len(resp.Text) == 0 {
+			return nil
+		}
+
+		if resp.ResponseType != ResponseTypeInChannel {
+			cmd.Reply(resp.Text)
+			return nil
+		}
+
+		if err := hub.requireUnmuted(ctx, cmd.Client.Id, cmd.ChannelId); err != nil {
+			return err
+		}
+		_, err = hub.PostMessage(ctx, cmd.Name, &db.CreateMessageParams{
+			ChannelID:        cmd.ChannelId,
+			UserID:           cmd.Client.Id,
+			Content:          resp.Text,
+			IsBot:            true,
+			UsernameOverride: &cmd.Name,
+		})
+		return err
+	}
+}
+
+func (r *Commands) call(ctx context.Context, cfg config.ExternalCommandConfig, body []byte) (*externalCommandResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(webhooks.HeaderEvent, webhooks.EventCommand)
+	req.Header.Set(webhooks.HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(webhooks.HeaderSignature, webhooks.Sign(cfg.Secret, timestamp, body))
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	commandResponse := &externalCommandResponse{}
+	err = json.NewDecoder(io.LimitReader(resp.Body, maxCommandResponseSize)).Decode(commandResponse)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	return commandResponse, nil
+}
@@ -3,21 +3,51 @@ package chat
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"project/config"
 	db "project/db/sqlc"
 	"project/logger"
+	"project/markdown"
+	"project/moderation"
+	"project/ratelimit"
+	"project/unfurl"
+	"project/webhooks"
 	"sync"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
 )
 
 const (
-	MEMBERSHIP_CHANNEL = "membership"
+	// message types
+	MESSAGE_POLL = "poll"
+
+	MEMBERSHIP_CHANNEL         = "membership"
+	MEMBERSHIP_REMOVED_CHANNEL = "membership.removed"
+	SESSION_REVOKED_CHANNEL    = "session.revoked"
+
+	// message event types
+	EVENT_PIN_ADDED       = "pin.added"
+	EVENT_PIN_REMOVED     = "pin.removed"
+	EVENT_REMINDER        = "reminder"
+	EVENT_MESSAGE_EXPIRED = "message.expired"
+	EVENT_MESSAGE_UPDATED = "message.updated"
+
+	EVENT_POLL_UPDATED = "poll.updated"
+	EVENT_POLL_CLOSED  = "poll.closed"
+
+	EVENT_MESSAGE_REJECTED = "message.rejected"
+	EVENT_MESSAGE_HELD     = "message.held"
+	EVENT_COMMAND_RESPONSE = "command.response"
+	EVENT_CHANNEL_UPDATED  = "channel.updated"
+	EVENT_USER_UPDATED     = "user.updated"
 )
 
 type Hub struct {
 	redisClient          *redis.Client
+	repo                 db.Repository
 	ChannelSubscriptions map[int64]int           // map[channelId]no of client subscribers
 	ChannelPubSub        map[int64]*redis.PubSub // map[channel id]pub sub object
 	Membership           map[int64]*db.Membership
@@ -28,12 +58,28 @@ type Hub struct {
 	WriteBroadcast       chan *Message
 	wg                   *sync.WaitGroup
 	MembershipUpdates    chan *db.Membership
+	MembershipRemovals   chan *db.Membership
+	removedMemberships   chan *db.Membership
+	revokedSessions      chan []uuid.UUID
 	serverName           string
+	commands             *Commands
+	maxMessageLength     int
+	fetcher              unfurl.Fetcher
+	unfurls              chan *unfurlJob // nil when link previews are disabled
+	maxLinks             int
+	unfurlTimeout        time.Duration
+	moderation           *moderation.Chain
+	moderationRules      *moderation.RuleCache
+	limiter              *ratelimit.Limiter
+	userLimit            ratelimit.Limit
+	channelLimit         ratelimit.Limit
 }
 
-func newHub(wg *sync.WaitGroup, cfg *config.StartupConfig, redisClient *redis.Client) *Hub {
+func newHub(wg *sync.WaitGroup, cfg *config.StartupConfig, redisClient *redis.Client, repo db.Repository) *Hub {
+	moderationRules := moderation.NewRuleCache(repo, cfg.Moderation.RulesCacheTTL)
 	return &Hub{
 		redisClient:          redisClient,
+		repo:                 repo,
 		ChannelSubscriptions: make(map[int64]int),
 		ChannelPubSub:        make(map[int64]*redis.PubSub),
 		Membership:           make(map[int64]*db.Membership),
@@ -44,23 +90,57 @@ func newHub(wg *sync.WaitGroup, cfg *config.StartupConfig, redisClient *redis.Cl
 		WriteBroadcast:       make(chan *Message, 10),
 		wg:                   wg,
 		MembershipUpdates:    make(chan *db.Membership, 10),
+		MembershipRemovals:   make(chan *db.Membership, 10),
+		removedMemberships:   make(chan *db.Membership, 10),
+		revokedSessions:      make(chan []uuid.UUID, 10),
 		serverName:           cfg.Server.Name,
+		commands:             newCommands(cfg),
+		maxMessageLength:     cfg.Chat.MaxMessageLength,
+		maxLinks:             cfg.Unfurl.MaxLinks,
+		unfurlTimeout:        unfurlTimeout(cfg.Unfurl.Timeout, cfg.Unfurl.MaxLinks),
+		moderation: moderation.NewChain(
+			moderation.NewBlocklistFilter(moderationRules),
+			moderation.NewLinkFilter(moderationRules),
+			moderation.NewSpamFilter(redisClient, cfg.Moderation.SpamWindow, cfg.Moderation.SpamMaxRepeats, cfg.Moderation.SpamAction),
+		),
+		moderationRules: moderationRules,
+		limiter:         ratelimit.NewLimiter(redisClient),
+		userLimit:       ratelimit.PerPeriod(cfg.RateLimit.UserMessages.Requests, cfg.RateLimit.UserMessages.Per),
+		channelLimit:    ratelimit.PerPeriod(cfg.RateLimit.ChannelMessages.Requests, cfg.RateLimit.ChannelMessages.Per),
 	}
 }
 
-func InitHub(wg *sync.WaitGroup, cfg *config.StartupConfig, redisClient *redis.Client) *Hub {
-	hub := newHub(wg, cfg, redisClient)
+func InitHub(wg *sync.WaitGroup, cfg *config.StartupConfig, redisClient *redis.Client, repo db.Repository) *Hub {
+	hub := newHub(wg, cfg, redisClient, repo)
+	if cfg.Unfurl.Enabled {
+		hub.fetcher = unfurl.NewCachedFetcher(unfurl.NewHTTPFetcher(cfg.Unfurl), redisClient, cfg.Unfurl.CacheTTL, cfg.Unfurl.FailureTTL)
+		hub.unfurls = make(chan *unfurlJob, cfg.Unfurl.QueueSize)
+		hub.startUnfurlers(cfg.Unfurl.Workers)
+	}
 	pubsub := hub.redisClient.Subscribe(context.Background(), MEMBERSHIP_CHANNEL)
 	go hub.run()
 	go hub.membershipUpdatesReader(pubsub)
+	removalsPubsub := hub.redisClient.Subscribe(context.Background(), MEMBERSHIP_REMOVED_CHANNEL)
+	go hub.membershipRemovalsReader(removalsPubsub)
+	revocationsPubsub := hub.redisClient.Subscribe(context.Background(), SESSION_REVOKED_CHANNEL)
+	go hub.sessionRevocationsReader(revocationsPubsub)
 	return hub
 }
 
+// RegisterCommand adds a slash command to the hub. It must be called before
+// clients connect.
+func (hub *Hub) RegisterCommand(name string, usage string, handler CommandHandler) {
+	hub.commands.Register(name, usage, handler)
+}
+
 func (hub *Hub) membershipUpdatesReader(pubsub *redis.PubSub) {
 	hub.wg.Add(1)
 	defer hub.wg.Done()
 	for {
 		msg, err := pubsub.ReceiveMessage(context.Background())
+		if errors.Is(err, redis.ErrClosed) {
+			return
+		}
 		if err != nil {
 			logger.Error(context.Background(), "membershipUpdatesReader", logger.Field("redis receive message error", err.Error()))
 			continue
@@ -89,6 +169,29 @@ func (hub *Hub) membershipUpdatesReader(pubsub *redis.PubSub) {
 	}
 }
 
+// membershipRemovalsReader hands memberships removed on any node to the run
+// loop, which drops them from the clients connected here.
+func (hub *Hub) membershipRemovalsReader(pubsub *redis.PubSub) {
+	hub.wg.Add(1)
+	defer hub.wg.Done()
+	for {
+		msg, err := pubsub.ReceiveMessage(context.Background())
+		if err != nil {
+			logger.Error(context.Background(), "membershipRemovalsReader", logger.Field("redis receive message error", err.Error()))
+			continue
+		}
+
+		membership := &db.Membership{}
+		err = json.Unmarshal([]byte(msg.Payload), membership)
+		if err != nil {
+			logger.Error(context.Background(), "membershipRemovalsReader", logger.Field("unmarshal error", err.Error()))
+			continue
+		}
+
+		hub.removedMemberships <- membership
+	}
+}
+
 func (h *Hub) run() {
 	h.wg.Add(1)
 	defer h.wg.Done()
@@ -109,6 +212,15 @@ func (h *Hub) run() {
 
 		case membership := <-h.MembershipUpdates:
 			h.membershipUpdates(membership)
+
+		case membership := <-h.MembershipRemovals:
+			h.membershipRemovals(membership)
+
+		case membership := <-h.removedMemberships:
+			h.removeMembership(membership)
+
+		case sessionIds := <-h.revokedSessions:
+			h.closeSessions(sessionIds)
 		}
 	}
 }
@@ -129,6 +241,42 @@ func (hub *Hub) membershipUpdates(membership *db.Membership) {
 	}
 }
 
+func (hub *Hub) membershipRemovals(membership *db.Membership) {
+	membershipBytes, err := json.Marshal(membership)
+	if err != nil {
+		logger.Error(context.Background(), "membershipRemovals", logger.Field("marshal error", err.Error()))
+		return
+	}
+
+	cmd := hub.redisClient.Publish(context.Background(), MEMBERSHIP_REMOVED_CHANNEL, string(membershipBytes))
+	if cmd.Err() != nil {
+		logger.Error(context.Background(), "membershipRemovals", logger.Field("redis publish error", cmd.Err().Error()))
+	}
+}
+
+func (hub *Hub) removeMembership(membership *db.Membership) {
+	// membership client not connected to this hub
+	client, ok := hub.Clients[membership.UserID]
+	if !ok {
+		return
+	}
+
+	memberships := make([]*db.Membership, 0, len(client.Memberships))
+	for _, m := range client.Memberships {
+		if m.ID != membership.ID {
+			memberships = append(memberships, m)
+		}
+	}
+	client.Memberships = memberships
+
+	hub.WriteBroadcast <- &Message{
+		Content:   "user left the channel",
+		ChannelId: membership.ChannelID,
+		Username:  client.Username,
+	}
+	hub.removeSubscription(membership)
+}
+
 func (hub *Hub) addClient(client *Client) {
 	// client exists
 	if _, ok := hub.Clients[client.Id]; ok {
@@ -210,10 +358,154 @@ func (hub *Hub) removeSubscription(membership *db.Membership) {
 	}
 
 	subscribers := hub.ChannelSubscriptions[membership.ChannelID]
-	if subscribers == 1 {
-		delete(hub.ChannelPubSub, membership.ChannelID)
-		delete(hub.ChannelSubscriptions, membership.ChannelID)
+	if subscribers > 1 {
+		hub.ChannelSubscriptions[membership.ChannelID] = subscribers - 1
+		return
+	}
+
+	// last subscriber on this node, closing ends startSubscription
+	if pubsub, ok := hub.ChannelPubSub[membership.ChannelID]; ok {
+		if err := pubsub.Close(); err != nil {
+			logger.Error(context.Background(), "removeSubscription", logger.Field("redis close error", err.Error()))
+		}
+	}
+	delete(hub.ChannelPubSub, membership.ChannelID)
+	delete(hub.ChannelSubscriptions, membership.ChannelID)
+}
+
+// PostMessage persists a new chat message and hands it to writeBroadcast.
+// Messages typed by clients and messages posted by integrations both go
+// through here, so they are stored and delivered the same way. The content
+// is normalized and its markdown spans are stored alongside it, then checked
+// by the moderation chain, see Moderate. Outgoing webhook deliveries are
+// queued in the same transaction as the insert. Links are unfurled
+// afterwards, their previews follow in a message.updated.
+func (hub *Hub) PostMessage(ctx context.Context, username string, arg *db.CreateMessageParams) (*Message, error) {
+	return hub.postMessage(ctx, username, arg, "", nil, true)
+}
+
+// PostMessageWith is PostMessage for messages that carry rows of their own,
+// such as polls. attach runs on the insert transaction and returns the
+// payload broadcast with the message, which is sent with messageType. These
+// messages cannot be held for review, moderation rejects them instead.
+func (hub *Hub) PostMessageWith(ctx context.Context, username string, arg *db.CreateMessageParams, messageType string, attach func(q *db.Queries, message *db.Message) (interface{}, error)) (*Message, error) {
+	return hub.postMessage(ctx, username, arg, messageType, attach, true)
+}
+
+// PostReviewedMessage posts a message a moderator approved, skipping the
+// moderation chain. attach runs on the insert transaction.
+func (hub *Hub) PostReviewedMessage(ctx context.Context, username string, arg *db.CreateMessageParams, attach func(q *db.Queries, message *db.Message) (interface{}, error)) (*Message, error) {
+	return hub.postMessage(ctx, username, arg, "", attach, false)
+}
+
+func (hub *Hub) postMessage(ctx context.Context, username string, arg *db.CreateMessageParams, messageType string, attach func(q *db.Queries, message *db.Message) (interface{}, error), moderate bool) (*Message, error) {
+	spans, err := hub.formatMessage(arg)
+	if err != nil {
+		return nil, err
+	}
+
+	if moderate {
+		err = hub.Moderate(ctx, hub.repo, username, arg, spans, attach == nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var msg *Message
+	err = hub.repo.ExecTx(ctx, func(q *db.Queries) error {
+		var err error
+		msg, err = hub.insertMessage(ctx, q, username, arg, messageType, attach, spans)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	hub.Publish(msg)
+	return msg, nil
+}
+
+// PostMessageOn is PostMessage for callers posting as part of a transaction
+// of their own: the message is formatted, moderated and inserted, and its
+// webhook deliveries queued, all on q. A held message is stored on q as well,
+// so it is only kept if q commits. The message is not broadcast; pass it to
+// Publish once q is committed.
+func (hub *Hub) PostMessageOn(ctx context.Context, q *db.Queries, username string, arg *db.CreateMessageParams) (*Message, error) {
+	spans, err := hub.formatMessage(arg)
+	if err != nil {
+		return nil, err
+	}
+
+	err = hub.Moderate(ctx, q, username, arg, spans, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return hub.insertMessage(ctx, q, username, arg, "", nil, spans)
+}
+
+// Publish broadcasts a posted message and unfurls the links of plain
+// messages.
+func (hub *Hub) Publish(msg *Message) {
+	hub.WriteBroadcast <- msg
+	if len(msg.Type) == 0 {
+		hub.queueUnfurl(msg, msg.spans)
+	}
+}
+
+// formatMessage normalizes the content of arg and stores its markdown spans
+// alongside.
+func (hub *Hub) formatMessage(arg *db.CreateMessageParams) ([]markdown.Span, error) {
+	content, spans, err := markdown.Format(arg.Content, hub.maxMessageLength)
+	if err != nil {
+		return nil, err
+	}
+	arg.Content = content
+	if len(spans) > 0 {
+		arg.Spans, err = json.Marshal(spans)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return spans, nil
+}
+
+func (hub *Hub) insertMessage(ctx context.Context, q *db.Queries, username string, arg *db.CreateMessageParams, messageType string, attach func(q *db.Queries, message *db.Message) (interface{}, error), spans []markdown.Span) (*Message, error) {
+	message, err := q.CreateMessage(ctx, arg)
+	if err != nil {
+		return nil, err
 	}
+
+	var payload interface{}
+	if attach != nil {
+		payload, err = attach(q, message)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = webhooks.Enqueue(ctx, q, message, username)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := &Message{
+		Id:          message.ID,
+		Type:        messageType,
+		Content:     message.Content,
+		ChannelId:   message.ChannelID,
+		Username:    username,
+		ExpiresAt:   message.ExpiresAt,
+		IsBot:       message.IsBot,
+		Attachments: message.Attachments,
+		Spans:       message.Spans,
+		Payload:     payload,
+		spans:       spans,
+	}
+	if message.UsernameOverride != nil {
+		msg.Username = *message.UsernameOverride
+	}
+	return msg, nil
 }
 
 func (hub *Hub) readBroadcast(msgStr string) {
@@ -226,6 +518,9 @@ func (hub *Hub) readBroadcast(msgStr string) {
 
 	channelId := msg.ChannelId
 	for _, membership := range hub.Membership {
+		if msg.RecipientId != 0 && membership.UserID != msg.RecipientId {
+			continue
+		}
 		if membership.ChannelID == channelId {
 			hub.Clients[membership.UserID].MessageChan <- msg
 		}
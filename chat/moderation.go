@@ -0,0 +1,68 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+	"project/constants"
+	db "project/db/sqlc"
+	"project/markdown"
+	"project/moderation"
+)
+
+// UseFilter appends a custom filter to the moderation chain. It must be
+// called before clients connect.
+func (hub *Hub) UseFilter(filter moderation.Filter) {
+	hub.moderation.Use(filter)
+}
+
+// InvalidateModerationRules makes the next message of the channel reload its
+// moderation rules on this node.
+func (hub *Hub) InvalidateModerationRules(channelId int64) {
+	hub.moderationRules.Invalidate(channelId)
+}
+
+// Moderate runs the moderation chain over a formatted message. Rejections
+// return an error wrapping constants.ErrMessageRejected. Held messages are
+// stored with q for review and return an error wrapping
+// constants.ErrMessageHeld; when canHold is false, because the message
+// carries rows of its own, they are rejected instead. Both errors carry the
+// reason given by the filter.
+func (hub *Hub) Moderate(ctx context.Context, q db.Querier, username string, arg *db.CreateMessageParams, spans []markdown.Span, canHold bool) error {
+	verdict := hub.moderation.Check(ctx, &moderation.Candidate{
+		ChannelId: arg.ChannelID,
+		UserId:    arg.UserID,
+		Username:  username,
+		Content:   arg.Content,
+		Spans:     spans,
+		IsBot:     arg.IsBot,
+	})
+
+	switch verdict.Action {
+	case moderation.ActionReject:
+		return fmt.Errorf("%w: %s", constants.ErrMessageRejected, verdict.Reason)
+
+	case moderation.ActionHold:
+		if !canHold {
+			return fmt.Errorf("%w: %s", constants.ErrMessageRejected, verdict.Reason)
+		}
+
+		_, err := q.CreateHeldMessage(ctx, &db.CreateHeldMessageParams{
+			ChannelID:        arg.ChannelID,
+			UserID:           arg.UserID,
+			Content:          arg.Content,
+			TtlSeconds:       arg.TtlSeconds,
+			IsBot:            arg.IsBot,
+			UsernameOverride: arg.UsernameOverride,
+			Attachments:      arg.Attachments,
+			WebhookID:        arg.WebhookID,
+			Filter:           verdict.Filter,
+			Reason:           verdict.Reason,
+		})
+		if err != nil {
+			return err
+		}
+		return fmt.Errorf("%w: %s", constants.ErrMessageHeld, verdict.Reason)
+	}
+
+	return nil
+}
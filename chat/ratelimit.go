@@ -0,0 +1,80 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"project/constants"
+	db "project/db/sqlc"
+	"project/logger"
+	"project/utils"
+	"time"
+)
+
+// throttleUser takes a token from the message bucket of the user. It returns
+// how long to wait with an error wrapping constants.ErrRateLimited when the
+// bucket is empty. Redis errors let the message through.
+func (hub *Hub) throttleUser(ctx context.Context, userId int64) (time.Duration, error) {
+	result, err := hub.limiter.Allow(ctx, fmt.Sprintf("messages:user:%d", userId), hub.userLimit)
+	if err != nil {
+		logger.Error(ctx, "throttleUser :: failed to check rate limit", logger.Field("error", err.Error()))
+		return 0, nil
+	}
+
+	if !result.Allowed {
+		return result.RetryAfter, utils.RetryAfterError(constants.ErrRateLimited, result.RetryAfter)
+	}
+	return 0, nil
+}
+
+// throttleChannel enforces the slow mode of the channel, from which channel
+// admins are exempt, then takes a token from the message bucket of the
+// channel. Messages slow mode refuses never reach the bucket, so one member
+// cannot drain it for everyone else.
+func (hub *Hub) throttleChannel(ctx context.Context, userId int64, channelId int64) (time.Duration, error) {
+	wait, err := hub.slowMode(ctx, userId, channelId)
+	if wait > 0 || err != nil {
+		return wait, err
+	}
+
+	result, err := hub.limiter.Allow(ctx, fmt.Sprintf("messages:channel:%d", channelId), hub.channelLimit)
+	if err != nil {
+		logger.Error(ctx, "throttleChannel :: failed to check rate limit", logger.Field("error", err.Error()))
+		return 0, nil
+	}
+	if !result.Allowed {
+		return result.RetryAfter, utils.RetryAfterError(constants.ErrRateLimited, result.RetryAfter)
+	}
+	return 0, nil
+}
+
+// slowMode starts the cooldown of the user in the channel, or returns how
+// long is left of it with an error wrapping constants.ErrSlowMode.
+func (hub *Hub) slowMode(ctx context.Context, userId int64, channelId int64) (time.Duration, error) {
+	channel, err := hub.repo.GetChannelById(ctx, channelId)
+	if err != nil {
+		return 0, err
+	}
+	if channel.SlowModeSeconds <= 0 {
+		return 0, nil
+	}
+
+	membership, err := hub.repo.GetMembership(ctx, &db.GetMembershipParams{UserID: userId, ChannelID: channelId})
+	if err != nil && !errors.Is(err, constants.ErrNoRows) {
+		return 0, err
+	}
+	if err == nil && membership.Role == constants.MembershipRoleAdmin {
+		return 0, nil
+	}
+
+	interval := time.Duration(channel.SlowModeSeconds) * time.Second
+	wait, err := hub.limiter.Cooldown(ctx, fmt.Sprintf("slowmode:%d:%d", channelId, userId), interval)
+	if err != nil {
+		logger.Error(ctx, "slowMode :: failed to check slow mode", logger.Field("error", err.Error()))
+		return 0, nil
+	}
+	if wait > 0 {
+		return wait, utils.RetryAfterError(constants.ErrSlowMode, wait)
+	}
+	return 0, nil
+}
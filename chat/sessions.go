@@ -0,0 +1,75 @@
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"project/logger"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/redis/go-redis/v9"
+)
+
+// closeTimeout bounds the write of the close frame to a revoked connection.
+const closeTimeout = time.Second
+
+// RevokeSessions closes the websocket connections opened with the sessions,
+// on every node. The sessions must already be marked revoked in the
+// database.
+func (hub *Hub) RevokeSessions(ctx context.Context, sessionIds ...uuid.UUID) error {
+	if len(sessionIds) == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(sessionIds)
+	if err != nil {
+		return err
+	}
+	return hub.redisClient.Publish(ctx, SESSION_REVOKED_CHANNEL, string(payload)).Err()
+}
+
+// sessionRevocationsReader hands sessions revoked on any node to the run
+// loop, which disconnects the clients connected here.
+func (hub *Hub) sessionRevocationsReader(pubsub *redis.PubSub) {
+	hub.wg.Add(1)
+	defer hub.wg.Done()
+	for {
+		msg, err := pubsub.ReceiveMessage(context.Background())
+		if err != nil {
+			logger.Error(context.Background(), "sessionRevocationsReader", logger.Field("redis receive message error", err.Error()))
+			continue
+		}
+
+		var sessionIds []uuid.UUID
+		err = json.Unmarshal([]byte(msg.Payload), &sessionIds)
+		if err != nil {
+			logger.Error(context.Background(), "sessionRevocationsReader", logger.Field("unmarshal error", err.Error()))
+			continue
+		}
+
+		hub.revokedSessions <- sessionIds
+	}
+}
+
+// closeSessions closes the connections of the sessions; their read pumps
+// then remove the clients as for any disconnect.
+func (hub *Hub) closeSessions(sessionIds []uuid.UUID) {
+	revoked := make(map[uuid.UUID]bool, len(sessionIds))
+	for _, sessionId := range sessionIds {
+		revoked[sessionId] = true
+	}
+
+	for _, client := range hub.Clients {
+		if client.SessionId == uuid.Nil || !revoked[client.SessionId] {
+			continue
+		}
+
+		message := websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "session revoked")
+		err := client.Conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(closeTimeout))
+		if err != nil {
+			logger.Error(context.Background(), "closeSessions", logger.Field("userId", client.Id), logger.Field("error", err.Error()))
+		}
+		client.Conn.Close()
+	}
+}
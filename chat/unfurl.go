@@ -0,0 +1,113 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"project/constants"
+	"project/logger"
+	"project/markdown"
+	"project/unfurl"
+	"strings"
+	"time"
+)
+
+// unfurlJob is a posted message waiting for the previews of its links.
+type unfurlJob struct {
+	message *Message
+	urls    []string
+}
+
+// SetFetcher replaces the fetcher used for link previews, e.g. with a stand-in
+// serving local pages. It must be called before clients connect.
+func (hub *Hub) SetFetcher(fetcher unfurl.Fetcher) {
+	hub.fetcher = fetcher
+}
+
+func (hub *Hub) startUnfurlers(workers int) {
+	for i := 0; i < workers; i++ {
+		go hub.unfurler()
+	}
+}
+
+// queueUnfurl hands the links of a posted message to the unfurl workers. It
+// never blocks the sender: when the queue is full the previews are skipped.
+func (hub *Hub) queueUnfurl(msg *Message, spans []markdown.Span) {
+	if hub.unfurls == nil {
+		return
+	}
+
+	urls := linkURLs(spans, hub.maxLinks)
+	if len(urls) == 0 {
+		return
+	}
+
+	// the caller keeps msg, the workers get their own copy
+	copied := *msg
+	select {
+	case hub.unfurls <- &unfurlJob{message: &copied, urls: urls}:
+	default:
+		logger.Error(context.Background(), "queueUnfurl :: unfurl queue full, skipping previews", logger.Field("messageId", msg.Id))
+	}
+}
+
+func (hub *Hub) unfurler() {
+	hub.wg.Add(1)
+	defer hub.wg.Done()
+
+	for job := range hub.unfurls {
+		hub.unfurl(job)
+	}
+}
+
+// unfurl fetches the previews of a message and broadcasts them as a
+// message.updated carrying the message and its previews.
+func (hub *Hub) unfurl(job *unfurlJob) {
+	ctx, cancel := context.WithTimeout(context.Background(), hub.unfurlTimeout)
+	defer cancel()
+
+	previews := make([]unfurl.Preview, 0, len(job.urls))
+	for _, url := range job.urls {
+		preview, err := hub.fetcher.Fetch(ctx, url)
+		if err != nil {
+			if !errors.Is(err, constants.ErrNoLinkPreview) {
+				logger.Error(ctx, "unfurl :: failed to fetch preview", logger.Field("url", url), logger.Field("error", err.Error()))
+			}
+			continue
+		}
+		previews = append(previews, *preview)
+	}
+	if len(previews) == 0 {
+		return
+	}
+
+	job.message.Type = EVENT_MESSAGE_UPDATED
+	job.message.Previews = previews
+	hub.WriteBroadcast <- job.message
+}
+
+// linkURLs returns the distinct http and https link targets of spans, at most
+// max of them.
+func linkURLs(spans []markdown.Span, max int) []string {
+	seen := make(map[string]bool)
+	urls := make([]string, 0)
+	for _, span := range spans {
+		if len(urls) == max {
+			break
+		}
+		if span.Type != markdown.SpanLink || seen[span.URL] {
+			continue
+		}
+		if !strings.HasPrefix(span.URL, "http://") && !strings.HasPrefix(span.URL, "https://") {
+			continue
+		}
+		seen[span.URL] = true
+		urls = append(urls, span.URL)
+	}
+	return urls
+}
+
+// unfurlTimeout bounds the work on one message: every link gets the fetch
+// timeout, plus a little slack for the cache round trips.
+func unfurlTimeout(fetchTimeout time.Duration, maxLinks int) time.Duration {
+	return time.Duration(maxLinks)*fetchTimeout + time.Second
+}
@@ -0,0 +1,116 @@
+// Command chatadmin runs administrative tasks against the chat database
+// without going through the HTTP API. It reads the same config.yml and
+// environment variables as the server.
+//
+//	chatadmin export -channel 42 -format jsonl -out channel-42.jsonl
+//	chatadmin import -in channel-42.jsonl -format jsonl -create-missing-users
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"project/config"
+	db "project/db/sqlc"
+	"project/models"
+	"project/models/request"
+	service "project/service/impl"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalln("failed loading config file", err)
+	}
+
+	database, err := newDatabase(cfg)
+	if err != nil {
+		log.Fatalln("failed connecting to database", err)
+	}
+	defer database.ConnPool.Close()
+
+	repository := db.NewRepository(database)
+	archiveService := service.ConfigureArchiveService(cfg, repository)
+	ctx := context.Background()
+
+	switch os.Args[1] {
+	case "export":
+		flags := flag.NewFlagSet("export", flag.ExitOnError)
+		channelId := flags.Int64("channel", 0, "id of the channel to export")
+		format := flags.String("format", "json", "archive format: json or jsonl")
+		out := flags.String("out", "", "output file, stdout when empty")
+		flags.Parse(os.Args[2:])
+
+		var w io.Writer = os.Stdout
+		if *out != "" {
+			file, err := os.Create(*out)
+			if err != nil {
+				log.Fatalln("failed to create output file", err)
+			}
+			defer file.Close()
+			w = file
+		}
+
+		err = archiveService.ExportChannel(ctx, &request.ExportChannelRequest{ChannelId: *channelId, Format: *format}, w)
+		if err != nil {
+			log.Fatalln("failed to export channel", err)
+		}
+
+	case "import":
+		flags := flag.NewFlagSet("import", flag.ExitOnError)
+		in := flags.String("in", "", "input file, stdin when empty")
+		format := flags.String("format", "json", "archive format: json or jsonl")
+		name := flags.String("name", "", "name of the new channel, defaults to the archived name")
+		createMissingUsers := flags.Bool("create-missing-users", false, "create placeholder accounts for unknown emails")
+		flags.Parse(os.Args[2:])
+
+		var r io.Reader = os.Stdin
+		if *in != "" {
+			file, err := os.Open(*in)
+			if err != nil {
+				log.Fatalln("failed to open input file", err)
+			}
+			defer file.Close()
+			r = file
+		}
+
+		req := &request.ImportChannelRequest{Format: *format, Name: *name, CreateMissingUsers: *createMissingUsers}
+		result, err := archiveService.ImportChannel(ctx, req, r)
+		if err != nil {
+			log.Fatalln("failed to import channel", err)
+		}
+
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(result)
+
+	default:
+		usage()
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: chatadmin export|import [flags]")
+	os.Exit(2)
+}
+
+func newDatabase(config *config.StartupConfig) (*models.Database, error) {
+	cfg := config.Database
+	connString := fmt.Sprintf("%s://%s:%s@%s:%s/%s?sslmode=disable", cfg.Type, cfg.Username, cfg.Password, cfg.Host, cfg.Port, cfg.Name)
+	connPool, err := pgxpool.New(context.Background(), connString)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.Database{ConnPool: connPool}, nil
+}
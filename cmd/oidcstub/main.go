@@ -0,0 +1,166 @@
+// Command oidcstub is a local stand-in for an OpenID Connect provider. It
+// approves every authorization request as the user given on the command
+// line, so single sign-on can be tried without a real identity provider.
+//
+//	oidcstub -addr :9096 -email alice@example.com -sub alice
+//
+// Configure a provider with issuer http://localhost:9096 and any client id,
+// then open the authorization url from GET /auth/oidc/<name>/authorize. The
+// redirect carries the code and state to POST to the callback.
+package main
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"log"
+	"math/big"
+	"net/http"
+	"net/url"
+	"project/oidc"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const keyID = "oidcstub"
+
+// grant is what an authorization code is exchanged for.
+type grant struct {
+	clientID      string
+	redirectURI   string
+	codeChallenge string
+	nonce         string
+}
+
+func main() {
+	addr := flag.String("addr", ":9096", "listen address")
+	issuer := flag.String("issuer", "http://localhost:9096", "issuer, the url the server is reached at")
+	subject := flag.String("sub", "stub-user", "subject of the logged in user")
+	email := flag.String("email", "stub-user@example.com", "email of the logged in user")
+	verified := flag.Bool("verified", true, "whether the email is verified")
+	name := flag.String("name", "Stub User", "name of the logged in user")
+	flag.Parse()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	var mu sync.Mutex
+	grants := make(map[string]grant)
+
+	http.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{
+			"issuer":                                *issuer,
+			"authorization_endpoint":                *issuer + "/authorize",
+			"token_endpoint":                        *issuer + "/token",
+			"jwks_uri":                              *issuer + "/jwks",
+			"response_types_supported":              []string{"code"},
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+			"code_challenge_methods_supported":      []string{"S256"},
+		})
+	})
+
+	http.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": keyID,
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+
+	http.HandleFunc("/authorize", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		redirectURI, err := url.Parse(query.Get("redirect_uri"))
+		if err != nil || query.Get("client_id") == "" || query.Get("code_challenge_method") != "S256" {
+			http.Error(w, "invalid authorization request", http.StatusBadRequest)
+			return
+		}
+
+		code, err := oidc.RandomString()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		mu.Lock()
+		grants[code] = grant{query.Get("client_id"), query.Get("redirect_uri"), query.Get("code_challenge"), query.Get("nonce")}
+		mu.Unlock()
+
+		log.Printf("authorized %s for %s", *email, query.Get("client_id"))
+		params := redirectURI.Query()
+		params.Set("code", code)
+		params.Set("state", query.Get("state"))
+		redirectURI.RawQuery = params.Encode()
+		http.Redirect(w, r, redirectURI.String(), http.StatusFound)
+	})
+
+	http.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			tokenError(w, "invalid_request")
+			return
+		}
+		code := r.PostForm.Get("code")
+		mu.Lock()
+		g, ok := grants[code]
+		delete(grants, code)
+		mu.Unlock()
+
+		clientID := r.PostForm.Get("client_id")
+		if basicID, _, hasBasic := r.BasicAuth(); hasBasic {
+			clientID, _ = url.QueryUnescape(basicID)
+		}
+		if !ok || clientID != g.clientID || r.PostForm.Get("redirect_uri") != g.redirectURI ||
+			oidc.CodeChallenge(r.PostForm.Get("code_verifier")) != g.codeChallenge {
+			tokenError(w, "invalid_grant")
+			return
+		}
+
+		now := time.Now()
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+			"iss":                *issuer,
+			"sub":                *subject,
+			"aud":                g.clientID,
+			"iat":                now.Unix(),
+			"exp":                now.Add(5 * time.Minute).Unix(),
+			"nonce":              g.nonce,
+			"email":              *email,
+			"email_verified":     *verified,
+			"name":               *name,
+			"preferred_username": *subject,
+		})
+		token.Header["kid"] = keyID
+		idToken, err := token.SignedString(key)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		writeJSON(w, map[string]any{
+			"access_token": idToken,
+			"token_type":   "Bearer",
+			"expires_in":   300,
+			"id_token":     idToken,
+		})
+	})
+
+	log.Println("listening on", *addr)
+	log.Fatalln(http.ListenAndServe(*addr, nil))
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func tokenError(w http.ResponseWriter, code string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(map[string]string{"error": code})
+}
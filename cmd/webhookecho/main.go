@@ -0,0 +1,57 @@
+// Command webhookecho is a local stand-in for an outgoing webhook receiver.
+// It verifies the signature of every delivery, prints the payload and can be
+// told to fail a share of requests to exercise retries.
+//
+//	webhookecho -addr :9090 -secret <secret from the create response> -fail 0.5
+//
+// Register http://localhost:9090/ as the webhook url.
+package main
+
+import (
+	"flag"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"project/webhooks"
+	"time"
+)
+
+func main() {
+	addr := flag.String("addr", ":9090", "listen address")
+	secret := flag.String("secret", "", "signing secret of the webhook, signatures are not checked when empty")
+	failRate := flag.Float64("fail", 0, "share of requests answered with 500, between 0 and 1")
+	tolerance := flag.Duration("tolerance", 5*time.Minute, "accepted clock skew of the signature timestamp")
+	flag.Parse()
+
+	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		delivery := r.Header.Get(webhooks.HeaderDelivery)
+		if *secret != "" {
+			signature := r.Header.Get(webhooks.HeaderSignature)
+			timestamp := r.Header.Get(webhooks.HeaderTimestamp)
+			if !webhooks.Verify(*secret, signature, timestamp, body, *tolerance) {
+				log.Printf("delivery %s: invalid signature", delivery)
+				http.Error(w, "invalid signature", http.StatusUnauthorized)
+				return
+			}
+		}
+
+		if rand.Float64() < *failRate {
+			log.Printf("delivery %s: failing on purpose", delivery)
+			http.Error(w, "failing on purpose", http.StatusInternalServerError)
+			return
+		}
+
+		log.Printf("delivery %s: %s %s", delivery, r.Header.Get(webhooks.HeaderEvent), body)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	log.Println("listening on", *addr)
+	log.Fatalln(http.ListenAndServe(*addr, nil))
+}
@@ -3,22 +3,43 @@ package config
 import (
 	"log"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
 )
 
 type StartupConfig struct {
-	Server    ServerConfig    `mapstructure:"server"`
-	Database  DBConfig        `mapstructure:"database"`
-	Migration MigrationConfig `mapstructure:"migration"`
-	Token     TokenConfig     `mapstructure:"token"`
-	Redis     RedisConfig     `mapstructure:"redis"`
+	Server     ServerConfig     `mapstructure:"server"`
+	Database   DBConfig         `mapstructure:"database"`
+	Migration  MigrationConfig  `mapstructure:"migration"`
+	Token      TokenConfig      `mapstructure:"token"`
+	Redis      RedisConfig      `mapstructure:"redis"`
+	Chat       ChatConfig       `mapstructure:"chat"`
+	Jobs       JobsConfig       `mapstructure:"jobs"`
+	Retention  RetentionConfig  `mapstructure:"retention"`
+	Webhooks   WebhooksConfig   `mapstructure:"webhooks"`
+	Commands   CommandsConfig   `mapstructure:"commands"`
+	Unfurl     UnfurlConfig     `mapstructure:"unfurl"`
+	Moderation ModerationConfig `mapstructure:"moderation"`
+	RateLimit  RateLimitConfig  `mapstructure:"rateLimit"`
+	Login      LoginConfig      `mapstructure:"login"`
+	Denylist   DenylistConfig   `mapstructure:"denylist"`
+	Mail       MailConfig       `mapstructure:"mail"`
+	Account    AccountConfig    `mapstructure:"account"`
+	TwoFactor  TwoFactorConfig  `mapstructure:"twoFactor"`
+	OIDC       OIDCConfig       `mapstructure:"oidc"`
+	Password   PasswordConfig   `mapstructure:"password"`
+	Profile    ProfileConfig    `mapstructure:"profile"`
 }
 
+// ServerConfig names the node and where it listens. TrustedProxies are the
+// addresses or CIDRs whose X-Forwarded-For header is believed when working
+// out the client address; with none the peer address is used.
 type ServerConfig struct {
-	Name    string `mapstructure:"name"`
-	Address string `mapstructure:"address"`
+	Name           string   `mapstructure:"name"`
+	Address        string   `mapstructure:"address"`
+	TrustedProxies []string `mapstructure:"trustedProxies"`
 }
 
 type DBConfig struct {
@@ -35,9 +56,28 @@ type MigrationConfig struct {
 }
 
 type TokenConfig struct {
-	JWTSecret            string        `mapstructure:"jwtSecret"`
-	AccessTokenDuration  time.Duration `mapstructure:"accessTokenDuration"`
-	RefreshTokenDuration time.Duration `mapstructure:"refreshTokenDuration"`
+	// JWTSecret signs HS256 tokens when no signing keys are configured, and
+	// only verifies them when there are.
+	JWTSecret            string             `mapstructure:"jwtSecret"`
+	Issuer               string             `mapstructure:"issuer"`
+	Audience             string             `mapstructure:"audience"`
+	AccessTokenDuration  time.Duration      `mapstructure:"accessTokenDuration"`
+	RefreshTokenDuration time.Duration      `mapstructure:"refreshTokenDuration"`
+	SigningKeys          []SigningKeyConfig `mapstructure:"signingKeys"`
+}
+
+// SigningKeyConfig schedules a key: it signs tokens from SignFrom until a
+// newer key takes over and verifies them until VerifyUntil. Both are RFC 3339
+// times, empty means no bound.
+type SigningKeyConfig struct {
+	Kid            string `mapstructure:"kid"`
+	Algorithm      string `mapstructure:"algorithm"` // RS256 or EdDSA
+	PrivateKeyFile string `mapstructure:"privateKeyFile"`
+	// PublicKeyFile is used for keys that only verify, when the private key
+	// is gone.
+	PublicKeyFile string `mapstructure:"publicKeyFile"`
+	SignFrom      string `mapstructure:"signFrom"`
+	VerifyUntil   string `mapstructure:"verifyUntil"`
 }
 
 type RedisConfig struct {
@@ -46,6 +86,226 @@ type RedisConfig struct {
 	Password string `mapstructure:"password"`
 }
 
+type ChatConfig struct {
+	MaxPinsPerChannel int64 `mapstructure:"maxPinsPerChannel"`
+	MaxMessageLength  int   `mapstructure:"maxMessageLength"`
+}
+
+type JobsConfig struct {
+	SchedulerInterval time.Duration `mapstructure:"schedulerInterval"`
+	SweeperInterval   time.Duration `mapstructure:"sweeperInterval"`
+	PollInterval      time.Duration `mapstructure:"pollInterval"`
+	BatchSize         int32         `mapstructure:"batchSize"`
+}
+
+// RetentionConfig is the deployment wide policy applied to channels that
+// inherit it.
+type RetentionConfig struct {
+	Policy        string        `mapstructure:"policy"`
+	Value         int32         `mapstructure:"value"`
+	PurgeInterval time.Duration `mapstructure:"purgeInterval"`
+	BatchSize     int32         `mapstructure:"batchSize"`
+	BatchPause    time.Duration `mapstructure:"batchPause"`
+}
+
+// WebhooksConfig limits incoming webhooks and drives outgoing deliveries.
+// Each incoming webhook may post RateLimit messages per RateWindow. Failed
+// outgoing deliveries are retried after BackoffBase, doubling up to
+// BackoffMax, until MaxAttempts is reached.
+type WebhooksConfig struct {
+	BaseURL          string        `mapstructure:"baseURL"`
+	RateLimit        int64         `mapstructure:"rateLimit"`
+	RateWindow       time.Duration `mapstructure:"rateWindow"`
+	DispatchInterval time.Duration `mapstructure:"dispatchInterval"`
+	Timeout          time.Duration `mapstructure:"timeout"`
+	MaxAttempts      int32         `mapstructure:"maxAttempts"`
+	BackoffBase      time.Duration `mapstructure:"backoffBase"`
+	BackoffMax       time.Duration `mapstructure:"backoffMax"`
+}
+
+// CommandsConfig lists the slash commands served by external HTTP endpoints.
+// Requests are signed with the command secret the same way outgoing webhooks
+// are, and must be answered within Timeout.
+type CommandsConfig struct {
+	Timeout  time.Duration           `mapstructure:"timeout"`
+	External []ExternalCommandConfig `mapstructure:"external"`
+}
+
+type ExternalCommandConfig struct {
+	Name   string `mapstructure:"name"`
+	Usage  string `mapstructure:"usage"`
+	URL    string `mapstructure:"url"`
+	Secret string `mapstructure:"secret"`
+}
+
+// UnfurlConfig drives link previews. Up to MaxLinks links per message are
+// queued, at most QueueSize at a time, and fetched by Workers goroutines.
+// Each page must be served within Timeout; only its first MaxBodyBytes are
+// read. Previews are cached for CacheTTL and failures for FailureTTL.
+type UnfurlConfig struct {
+	Enabled      bool          `mapstructure:"enabled"`
+	Workers      int           `mapstructure:"workers"`
+	QueueSize    int           `mapstructure:"queueSize"`
+	MaxLinks     int           `mapstructure:"maxLinks"`
+	Timeout      time.Duration `mapstructure:"timeout"`
+	MaxBodyBytes int64         `mapstructure:"maxBodyBytes"`
+	MaxRedirects int           `mapstructure:"maxRedirects"`
+	CacheTTL     time.Duration `mapstructure:"cacheTTL"`
+	FailureTTL   time.Duration `mapstructure:"failureTTL"`
+	UserAgent    string        `mapstructure:"userAgent"`
+}
+
+// ModerationConfig tunes the built-in moderation filters. Channel rules are
+// cached for RulesCacheTTL. A user posting the same message more than
+// SpamMaxRepeats times within SpamWindow gets SpamAction, hold or reject.
+type ModerationConfig struct {
+	RulesCacheTTL  time.Duration `mapstructure:"rulesCacheTTL"`
+	SpamWindow     time.Duration `mapstructure:"spamWindow"`
+	SpamMaxRepeats int64         `mapstructure:"spamMaxRepeats"`
+	SpamAction     string        `mapstructure:"spamAction"`
+}
+
+// RateLimitConfig holds the token bucket limits shared by every app node. API
+// and Auth apply per client address, Auth on top of API to the signup, login
+// and renew routes. UserMessages applies per user to websocket messages and
+// commands, ChannelMessages per channel to websocket messages.
+type RateLimitConfig struct {
+	API             RateConfig `mapstructure:"api"`
+	Auth            RateConfig `mapstructure:"auth"`
+	UserMessages    RateConfig `mapstructure:"userMessages"`
+	ChannelMessages RateConfig `mapstructure:"channelMessages"`
+}
+
+// RateConfig allows Requests per Per, all of them at once if need be. Zero
+// disables the limit.
+type RateConfig struct {
+	Requests int           `mapstructure:"requests"`
+	Per      time.Duration `mapstructure:"per"`
+}
+
+// LoginConfig slows down password guessing. Failed logins are counted per
+// account and per client address over FailureWindow. Past DelayAfter failures
+// each further attempt on the account must wait DelayBase, doubling up to
+// DelayMax. AccountMaxFailures locks the account and AddressMaxFailures locks
+// the address out, both for LockoutDuration.
+type LoginConfig struct {
+	FailureWindow      time.Duration `mapstructure:"failureWindow"`
+	DelayAfter         int64         `mapstructure:"delayAfter"`
+	DelayBase          time.Duration `mapstructure:"delayBase"`
+	DelayMax           time.Duration `mapstructure:"delayMax"`
+	AccountMaxFailures int64         `mapstructure:"accountMaxFailures"`
+	AddressMaxFailures int64         `mapstructure:"addressMaxFailures"`
+	LockoutDuration    time.Duration `mapstructure:"lockoutDuration"`
+}
+
+// DenylistConfig sizes the in-process cache of the token denylist. A token
+// revoked on another node may keep working for up to CacheTTL here.
+type DenylistConfig struct {
+	CacheTTL  time.Duration `mapstructure:"cacheTTL"`
+	CacheSize int           `mapstructure:"cacheSize"`
+}
+
+// MailConfig picks how mails are sent: smtp, file, which writes every mail
+// to Dir, or log. Links in mails point to BaseURL, the web client. Mails are
+// sent from an outbox, failed sends are retried with exponential backoff.
+type MailConfig struct {
+	Driver           string        `mapstructure:"driver"`
+	From             string        `mapstructure:"from"`
+	BaseURL          string        `mapstructure:"baseURL"`
+	Dir              string        `mapstructure:"dir"`
+	SMTP             SMTPConfig    `mapstructure:"smtp"`
+	DispatchInterval time.Duration `mapstructure:"dispatchInterval"`
+	Timeout          time.Duration `mapstructure:"timeout"`
+	MaxAttempts      int32         `mapstructure:"maxAttempts"`
+	BackoffBase      time.Duration `mapstructure:"backoffBase"`
+	BackoffMax       time.Duration `mapstructure:"backoffMax"`
+}
+
+type SMTPConfig struct {
+	Host     string `mapstructure:"host"`
+	Port     string `mapstructure:"port"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+}
+
+// AccountConfig sets the life of the tokens mailed to users. With
+// RequireVerifiedEmail, accounts cannot log in before verifying their email.
+type AccountConfig struct {
+	RequireVerifiedEmail bool          `mapstructure:"requireVerifiedEmail"`
+	EmailVerificationTTL time.Duration `mapstructure:"emailVerificationTTL"`
+	PasswordResetTTL     time.Duration `mapstructure:"passwordResetTTL"`
+	EmailChangeTTL       time.Duration `mapstructure:"emailChangeTTL"`
+}
+
+// TwoFactorConfig sets up TOTP. Secrets are sealed with EncryptionKey, 32
+// base64 encoded bytes; without it users cannot enroll. Enforce is none,
+// admins or all: enforced accounts must enroll at their next login.
+type TwoFactorConfig struct {
+	Issuer        string        `mapstructure:"issuer"`
+	EncryptionKey string        `mapstructure:"encryptionKey"`
+	ChallengeTTL  time.Duration `mapstructure:"challengeTTL"`
+	Enforce       string        `mapstructure:"enforce"`
+	RecoveryCodes int           `mapstructure:"recoveryCodes"`
+}
+
+// OIDCConfig lists the identity providers users can log in with. A login
+// has StateTTL to come back from the provider.
+type OIDCConfig struct {
+	StateTTL  time.Duration        `mapstructure:"stateTTL"`
+	Timeout   time.Duration        `mapstructure:"timeout"`
+	Providers []OIDCProviderConfig `mapstructure:"providers"`
+}
+
+// OIDCProviderConfig is one identity provider, Name appears in urls. The
+// client secret can also be set in OIDC_<NAME>_CLIENT_SECRET. Users get an
+// account on their first login with AutoProvision, and an existing account
+// with the same verified email is linked on first login with LinkByEmail,
+// which should only be set for providers trusted to own those addresses.
+type OIDCProviderConfig struct {
+	Name           string   `mapstructure:"name"`
+	DisplayName    string   `mapstructure:"displayName"`
+	Issuer         string   `mapstructure:"issuer"`
+	ClientID       string   `mapstructure:"clientID"`
+	ClientSecret   string   `mapstructure:"clientSecret"`
+	RedirectURL    string   `mapstructure:"redirectURL"`
+	Scopes         []string `mapstructure:"scopes"`
+	AllowedDomains []string `mapstructure:"allowedDomains"`
+	AutoProvision  bool     `mapstructure:"autoProvision"`
+	LinkByEmail    bool     `mapstructure:"linkByEmail"`
+}
+
+// PasswordConfig sets how passwords are hashed and which are accepted.
+// Hashes made with other Argon2 parameters, or with bcrypt, are replaced at
+// the next login. BreachedList is an optional file of passwords that are
+// refused, one per line in plain text or as SHA-1 digests like the Pwned
+// Passwords downloads; it is held in memory.
+type PasswordConfig struct {
+	Argon2       Argon2Config `mapstructure:"argon2"`
+	MinLength    int          `mapstructure:"minLength"`
+	MaxLength    int          `mapstructure:"maxLength"`
+	BreachedList string       `mapstructure:"breachedList"`
+}
+
+// Argon2Config holds the Argon2id parameters, Memory in KiB.
+type Argon2Config struct {
+	Memory      uint32 `mapstructure:"memory"`
+	Iterations  uint32 `mapstructure:"iterations"`
+	Parallelism uint8  `mapstructure:"parallelism"`
+	SaltLength  uint32 `mapstructure:"saltLength"`
+	KeyLength   uint32 `mapstructure:"keyLength"`
+}
+
+// ProfileConfig limits avatars. Uploads over AvatarMaxBytes or
+// AvatarMaxPixels are refused before being decoded; the rest are cropped to
+// a square, scaled down to AvatarSize pixels a side and stored as png in
+// AvatarDir, which every node must share.
+type ProfileConfig struct {
+	AvatarDir       string `mapstructure:"avatarDir"`
+	AvatarSize      int    `mapstructure:"avatarSize"`
+	AvatarMaxBytes  int64  `mapstructure:"avatarMaxBytes"`
+	AvatarMaxPixels int    `mapstructure:"avatarMaxPixels"`
+}
+
 func LoadConfig() (*StartupConfig, error) {
 	viper.AddConfigPath(".")
 	viper.SetConfigName("config")
@@ -75,5 +335,19 @@ func LoadConfig() (*StartupConfig, error) {
 	config.Redis.Port = os.Getenv("REDIS_PORT")
 	config.Redis.Password = os.Getenv("REDIS_PASSWORD")
 
+	// smtp credentials stay out of config.yml when set in the environment
+	if password, ok := os.LookupEnv("SMTP_PASSWORD"); ok {
+		config.Mail.SMTP.Password = password
+	}
+	if key, ok := os.LookupEnv("TOTP_ENCRYPTION_KEY"); ok {
+		config.TwoFactor.EncryptionKey = key
+	}
+	for i, provider := range config.OIDC.Providers {
+		name := strings.ToUpper(strings.ReplaceAll(provider.Name, "-", "_"))
+		if secret, ok := os.LookupEnv("OIDC_" + name + "_CLIENT_SECRET"); ok {
+			config.OIDC.Providers[i].ClientSecret = secret
+		}
+	}
+
 	return &config, nil
 }
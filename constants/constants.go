@@ -4,10 +4,94 @@ const (
 	// headers
 	HeaderAuthorization = "Authorization"
 	HeaderContentType   = "Content-Type"
+	HeaderCacheControl  = "Cache-Control"
 	RequestHeaders      = "RequestHeaders"
 	XRequestID          = "x-request-id"
 
+	HeaderRetryAfter         = "Retry-After"
+	HeaderRateLimitLimit     = "X-RateLimit-Limit"
+	HeaderRateLimitRemaining = "X-RateLimit-Remaining"
+	HeaderRateLimitReset     = "X-RateLimit-Reset" // seconds until the bucket is full again
+
 	BearerAuthorizationType = "bearer"
 
 	JWTClaims = "jwtClaims"
+
+	// jwt types
+	TokenTypeAccess  = "access"
+	TokenTypeRefresh = "refresh"
+	// issued by a login that still needs a second factor, exchanged once
+	// for access and refresh tokens
+	TokenTypeChallenge = "2fa_challenge"
+
+	// api keys start with a fixed prefix so they can be told apart from jwts
+	// and spotted by secret scanners
+	ApiKeyPrefix = "gck_"
+
+	// not a valid bcrypt hash, accounts holding it cannot log in with a password
+	UnusablePasswordHash = "!"
+)
+
+const (
+	// api key scopes
+	ScopeApi     = "api"      // call the REST api
+	ScopeWSRead  = "ws:read"  // connect to the websocket and receive messages
+	ScopeWSWrite = "ws:write" // send messages over the websocket
+)
+
+const (
+	// membership roles
+	MembershipRoleMember = "member"
+	MembershipRoleAdmin  = "admin"
+)
+
+const (
+	// audit log actions
+	AuditAccountLocked      = "account.locked"
+	AuditAddressLocked      = "address.locked"
+	AuditAccountUnlocked    = "account.unlocked"
+	AuditRefreshTokenReused = "session.refresh_token_reused"
+	AuditSessionsRevoked    = "sessions.revoked"
+	AuditPasswordReset      = "password.reset"
+	AuditPasswordChanged    = "password.changed"
+	AuditEmailVerified      = "email.verified"
+	AuditEmailChanged       = "email.changed"
+	AuditTwoFactorEnabled   = "2fa.enabled"
+	AuditTwoFactorDisabled  = "2fa.disabled"
+	AuditTwoFactorReset     = "2fa.reset"
+	AuditRecoveryCodeUsed   = "2fa.recovery_code_used"
+	AuditRecoveryCodesReset = "2fa.recovery_codes_regenerated"
+	AuditUserProvisioned    = "sso.user_provisioned"
+	AuditIdentityLinked     = "sso.identity_linked"
+	AuditIdentityUnlinked   = "sso.identity_unlinked"
+	AuditMemberRoleChanged  = "membership.role_changed"
+)
+
+const (
+	// who must use two-factor authentication
+	TwoFactorEnforceNone   = "none"
+	TwoFactorEnforceAdmins = "admins"
+	TwoFactorEnforceAll    = "all"
+)
+
+const (
+	// purposes of the single use tokens mailed to users
+	UserTokenPasswordReset     = "password_reset"
+	UserTokenEmailVerification = "email_verification"
+	UserTokenEmailChange       = "email_change"
+)
+
+const (
+	// held message statuses
+	HeldMessageStatusPending  = "pending"
+	HeldMessageStatusApproved = "approved"
+	HeldMessageStatusRejected = "rejected"
+)
+
+const (
+	// channel retention policies
+	RetentionPolicyInherit  = "inherit"
+	RetentionPolicyForever  = "forever"
+	RetentionPolicyDays     = "days"
+	RetentionPolicyMessages = "messages"
 )
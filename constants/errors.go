@@ -12,14 +12,90 @@ var ErrPasswordIncorrect = errors.New("password is incorrect")
 
 var ErrTokenExpired = errors.New("token is expired")
 var ErrTokenInvalid = errors.New("token is invalid")
+var ErrWrongTokenType = errors.New("token is of the wrong type")
+var ErrTokenRevoked = errors.New("token is revoked")
+var ErrNoSigningKey = errors.New("no key can sign tokens now")
 
 var ErrSessionBlocked = errors.New("session is blocked")
 var ErrSessionExpired = errors.New("session is expired")
 var ErrLoggedOutSession = errors.New("session is logged out")
 var ErrIncorrectSessionUser = errors.New("incorrect session user")
 var ErrIncorrectSessionToken = errors.New("incorrect session token")
+var ErrRefreshTokenReused = errors.New("refresh token was already used, the session is blocked")
 
 var ErrAccessDenied = errors.New("resource access denied")
+var ErrAdminRequired = errors.New("admin privileges required")
+
+var ErrNotChannelMember = errors.New("user is not a member of the channel")
+var ErrNotChannelAdmin = errors.New("user is not an admin of the channel")
+var ErrMessageNotInChannel = errors.New("message does not belong to the channel")
+var ErrPinLimitReached = errors.New("channel pin limit reached")
+var ErrAlreadyChannelMember = errors.New("user is already a member of the channel")
+var ErrChannelMuted = errors.New("user is muted in the channel")
+
+var ErrInvalidRetentionPolicy = errors.New("retention value is required for days and messages policies")
+
+var ErrScheduleInPast = errors.New("scheduled time must be in the future")
+
+var ErrMessageRejected = errors.New("message rejected")
+var ErrMessageHeld = errors.New("message held for review")
+var ErrInvalidModerationRule = errors.New("invalid moderation rule")
+var ErrHeldMessageReviewed = errors.New("held message was already reviewed")
+
+var ErrMessageTooLong = errors.New("message is too long")
+var ErrInvalidMessageEncoding = errors.New("message is not valid utf-8")
+
+var ErrPollClosed = errors.New("poll is closed")
+var ErrPollClosesInPast = errors.New("poll closing time must be in the future")
+var ErrInvalidPollOption = errors.New("option does not belong to the poll")
+var ErrSingleChoicePoll = errors.New("poll allows a single choice")
+
+var ErrRateLimited = errors.New("rate limit exceeded")
+var ErrSlowMode = errors.New("channel is in slow mode")
+
+var ErrInvalidArchive = errors.New("invalid archive")
+var ErrArchiveUserNotFound = errors.New("archive references a user that does not exist")
+
+var ErrNotBotOwner = errors.New("user does not own the bot")
+var ErrInsufficientScope = errors.New("api key does not grant the required scope")
+
+var ErrUnknownCommand = errors.New("unknown command")
+var ErrInvalidCommand = errors.New("invalid command arguments")
+var ErrCommandFailed = errors.New("command failed")
+
+var ErrNoLinkPreview = errors.New("link has no preview")
+var ErrBlockedAddress = errors.New("address is not publicly routable")
+var ErrInvalidWebhookURL = errors.New("webhook url must be an http or https url of a public host")
+
+var ErrLoginThrottled = errors.New("too many failed login attempts")
+var ErrAccountLocked = errors.New("account is temporarily locked after too many failed login attempts")
+
+var ErrInvalidUserToken = errors.New("link is invalid or expired")
+var ErrEmailNotVerified = errors.New("email address is not verified")
+var ErrWeakPassword = errors.New("password does not meet the password policy")
+
+var ErrUsernameTaken = errors.New("username is already taken")
+var ErrEmailTaken = errors.New("email address is already in use")
+var ErrInvalidTimeZone = errors.New("unknown time zone")
+var ErrInvalidImage = errors.New("image is not a valid png, jpeg or gif")
+var ErrImageTooLarge = errors.New("image is too large")
+
+var ErrTwoFactorUnavailable = errors.New("two-factor authentication is not configured")
+var ErrTwoFactorEnabled = errors.New("two-factor authentication is already enabled")
+var ErrTwoFactorNotEnabled = errors.New("two-factor authentication is not enabled")
+var ErrTwoFactorRequired = errors.New("two-factor authentication is required for this account")
+var ErrInvalidTwoFactorCode = errors.New("two-factor code is invalid")
+var ErrChallengeUsed = errors.New("login challenge was already used")
+
+var ErrUnknownProvider = errors.New("unknown identity provider")
+var ErrInvalidSSOState = errors.New("login state is invalid or expired")
+var ErrSSOFailed = errors.New("identity provider login failed")
+var ErrSSOEmailNotVerified = errors.New("identity provider did not verify the email address")
+var ErrSSODomainNotAllowed = errors.New("email domain is not allowed for this identity provider")
+var ErrSSOAccountNotFound = errors.New("no account is linked to this identity")
+var ErrSSOAccountExists = errors.New("an account already uses this email, log in and link the identity from it")
+var ErrIdentityLinked = errors.New("identity is already linked to an account")
+var ErrLastLoginMethod = errors.New("cannot unlink the only way to log in to the account")
 
 var ErrEmptyAuthHeader = errors.New("authorization header not provided")
 var ErrInvalidAuthHeader = errors.New("invalid authorization header format")
@@ -28,3 +104,12 @@ const (
 	ForeignKeyViolation = "23503"
 	UniqueViolation     = "23505"
 )
+
+// unique indexes of users
+const (
+	UsersUsernameKey = "users_username_key"
+	UsersEmailKey    = "users_email_key"
+)
+
+// unique index of memberships
+const MembershipsUserChannelKey = "memberships_user_id_channel_id_key"
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS "saved_items";
+DROP TABLE IF EXISTS "pins";
+ALTER TABLE "memberships" DROP COLUMN IF EXISTS "role";
+DROP TABLE IF EXISTS "messages";
@@ -0,0 +1,30 @@
+CREATE TABLE "messages" (
+    "id" bigserial PRIMARY KEY,
+    "channel_id" bigint NOT NULL REFERENCES channels(id) ON DELETE CASCADE,
+    "user_id" bigint NOT NULL REFERENCES users(id),
+    "content" varchar NOT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX "messages_channel_id_created_at_idx" ON "messages" ("channel_id", "created_at");
+
+ALTER TABLE "memberships" ADD COLUMN "role" varchar NOT NULL DEFAULT 'member';
+
+CREATE TABLE "pins" (
+    "id" bigserial PRIMARY KEY,
+    "channel_id" bigint NOT NULL REFERENCES channels(id) ON DELETE CASCADE,
+    "message_id" bigint NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+    "pinned_by" bigint NOT NULL REFERENCES users(id),
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "pins" ADD CONSTRAINT "pins_message_id_unique" UNIQUE ("message_id");
+
+CREATE TABLE "saved_items" (
+    "id" bigserial PRIMARY KEY,
+    "user_id" bigint NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    "message_id" bigint NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "saved_items" ADD CONSTRAINT "saved_items_user_id_message_id_unique" UNIQUE ("user_id", "message_id");
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS "reminders";
+DROP TABLE IF EXISTS "scheduled_messages";
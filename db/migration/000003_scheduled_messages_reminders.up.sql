@@ -0,0 +1,23 @@
+CREATE TABLE "scheduled_messages" (
+    "id" bigserial PRIMARY KEY,
+    "channel_id" bigint NOT NULL REFERENCES channels(id) ON DELETE CASCADE,
+    "user_id" bigint NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    "content" varchar NOT NULL,
+    "send_at" timestamptz NOT NULL,
+    "status" varchar NOT NULL DEFAULT 'pending',
+    "message_id" bigint DEFAULT NULL REFERENCES messages(id) ON DELETE SET NULL,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX "scheduled_messages_pending_send_at_idx" ON "scheduled_messages" ("send_at") WHERE "status" = 'pending';
+
+CREATE TABLE "reminders" (
+    "id" bigserial PRIMARY KEY,
+    "user_id" bigint NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    "message_id" bigint NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+    "remind_at" timestamptz NOT NULL,
+    "status" varchar NOT NULL DEFAULT 'pending',
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX "reminders_pending_remind_at_idx" ON "reminders" ("remind_at") WHERE "status" = 'pending';
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS "messages_expires_at_idx";
+ALTER TABLE "messages" DROP COLUMN IF EXISTS "expires_at";
+ALTER TABLE "channels" DROP COLUMN IF EXISTS "message_ttl_seconds";
@@ -0,0 +1,5 @@
+ALTER TABLE "channels" ADD COLUMN "message_ttl_seconds" integer DEFAULT NULL;
+
+ALTER TABLE "messages" ADD COLUMN "expires_at" timestamptz DEFAULT NULL;
+
+CREATE INDEX "messages_expires_at_idx" ON "messages" ("expires_at") WHERE "expires_at" IS NOT NULL;
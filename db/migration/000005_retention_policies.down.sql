@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS "messages_channel_id_id_idx";
+ALTER TABLE "channels" DROP COLUMN IF EXISTS "retention_value";
+ALTER TABLE "channels" DROP COLUMN IF EXISTS "retention_policy";
+ALTER TABLE "users" DROP COLUMN IF EXISTS "is_admin";
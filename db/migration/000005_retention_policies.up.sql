@@ -0,0 +1,6 @@
+ALTER TABLE "users" ADD COLUMN "is_admin" boolean NOT NULL DEFAULT false;
+
+ALTER TABLE "channels" ADD COLUMN "retention_policy" varchar NOT NULL DEFAULT 'inherit';
+ALTER TABLE "channels" ADD COLUMN "retention_value" integer DEFAULT NULL;
+
+CREATE INDEX "messages_channel_id_id_idx" ON "messages" ("channel_id", "id");
@@ -0,0 +1,5 @@
+ALTER TABLE "messages" DROP COLUMN IF EXISTS "webhook_id";
+ALTER TABLE "messages" DROP COLUMN IF EXISTS "attachments";
+ALTER TABLE "messages" DROP COLUMN IF EXISTS "username_override";
+ALTER TABLE "messages" DROP COLUMN IF EXISTS "is_bot";
+DROP TABLE IF EXISTS "incoming_webhooks";
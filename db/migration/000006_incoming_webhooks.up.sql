@@ -0,0 +1,17 @@
+CREATE TABLE "incoming_webhooks" (
+    "id" bigserial PRIMARY KEY,
+    "channel_id" bigint NOT NULL REFERENCES channels(id) ON DELETE CASCADE,
+    "name" varchar NOT NULL,
+    "token_hash" varchar NOT NULL,
+    "created_by" bigint NOT NULL REFERENCES users(id),
+    "revoked_at" timestamptz DEFAULT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "incoming_webhooks" ADD CONSTRAINT "incoming_webhooks_token_hash_unique" UNIQUE ("token_hash");
+
+-- bot messages keep user_id pointing at the admin who created the webhook
+ALTER TABLE "messages" ADD COLUMN "is_bot" boolean NOT NULL DEFAULT false;
+ALTER TABLE "messages" ADD COLUMN "username_override" varchar DEFAULT NULL;
+ALTER TABLE "messages" ADD COLUMN "attachments" jsonb DEFAULT NULL;
+ALTER TABLE "messages" ADD COLUMN "webhook_id" bigint DEFAULT NULL REFERENCES incoming_webhooks(id) ON DELETE SET NULL;
@@ -0,0 +1,41 @@
+CREATE TABLE "outgoing_webhooks" (
+    "id" bigserial PRIMARY KEY,
+    "channel_id" bigint NOT NULL REFERENCES channels(id) ON DELETE CASCADE,
+    "url" varchar NOT NULL,
+    "secret" varchar NOT NULL,
+    "trigger_words" text[] NOT NULL DEFAULT '{}',
+    "created_by" bigint NOT NULL REFERENCES users(id),
+    "revoked_at" timestamptz DEFAULT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX "outgoing_webhooks_channel_id_idx" ON "outgoing_webhooks" ("channel_id");
+
+-- transactional outbox, rows are written in the same transaction as the message
+CREATE TABLE "webhook_outbox" (
+    "id" bigserial PRIMARY KEY,
+    "webhook_id" bigint NOT NULL REFERENCES outgoing_webhooks(id) ON DELETE CASCADE,
+    "message_id" bigint NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+    "payload" jsonb NOT NULL,
+    "status" varchar NOT NULL DEFAULT 'pending',
+    "attempts" integer NOT NULL DEFAULT 0,
+    "next_attempt_at" timestamptz NOT NULL DEFAULT (now()),
+    "last_error" varchar DEFAULT NULL,
+    "delivered_at" timestamptz DEFAULT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX "webhook_outbox_pending_idx" ON "webhook_outbox" ("next_attempt_at") WHERE "status" = 'pending';
+
+CREATE TABLE "webhook_deliveries" (
+    "id" bigserial PRIMARY KEY,
+    "webhook_id" bigint NOT NULL REFERENCES outgoing_webhooks(id) ON DELETE CASCADE,
+    "outbox_id" bigint NOT NULL REFERENCES webhook_outbox(id) ON DELETE CASCADE,
+    "attempt" integer NOT NULL,
+    "status_code" integer DEFAULT NULL,
+    "error" varchar DEFAULT NULL,
+    "duration_ms" integer NOT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX "webhook_deliveries_webhook_id_id_idx" ON "webhook_deliveries" ("webhook_id", "id");
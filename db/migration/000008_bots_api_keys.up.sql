@@ -0,0 +1,18 @@
+ALTER TABLE "users" ADD COLUMN "is_bot" boolean NOT NULL DEFAULT false;
+ALTER TABLE "users" ADD COLUMN "owner_id" bigint DEFAULT NULL REFERENCES users(id);
+
+CREATE TABLE "api_keys" (
+    "id" bigserial PRIMARY KEY,
+    "user_id" bigint NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    "name" varchar NOT NULL,
+    "prefix" varchar NOT NULL,
+    "key_hash" varchar NOT NULL,
+    "scopes" text[] NOT NULL,
+    "expires_at" timestamptz DEFAULT NULL,
+    "last_used_at" timestamptz DEFAULT NULL,
+    "revoked_at" timestamptz DEFAULT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "api_keys" ADD CONSTRAINT "api_keys_key_hash_unique" UNIQUE ("key_hash");
+CREATE INDEX "api_keys_user_id_idx" ON "api_keys" ("user_id");
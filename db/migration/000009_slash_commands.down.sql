@@ -0,0 +1,2 @@
+ALTER TABLE "memberships" DROP COLUMN IF EXISTS "muted_until";
+ALTER TABLE "channels" DROP COLUMN IF EXISTS "topic";
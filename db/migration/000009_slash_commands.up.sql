@@ -0,0 +1,2 @@
+ALTER TABLE "channels" ADD COLUMN "topic" varchar DEFAULT NULL;
+ALTER TABLE "memberships" ADD COLUMN "muted_until" timestamptz DEFAULT NULL;
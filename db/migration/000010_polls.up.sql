@@ -0,0 +1,37 @@
+CREATE TABLE "polls" (
+    "id" bigserial PRIMARY KEY,
+    "message_id" bigint NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+    "channel_id" bigint NOT NULL REFERENCES channels(id) ON DELETE CASCADE,
+    "created_by" bigint NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    "question" varchar NOT NULL,
+    "multiple_choice" boolean NOT NULL DEFAULT false,
+    "anonymous" boolean NOT NULL DEFAULT false,
+    "closes_at" timestamptz DEFAULT NULL,
+    "closed_at" timestamptz DEFAULT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "polls" ADD CONSTRAINT "polls_message_id_unique" UNIQUE ("message_id");
+CREATE INDEX "polls_closes_at_idx" ON "polls" ("closes_at") WHERE "closed_at" IS NULL AND "closes_at" IS NOT NULL;
+
+CREATE TABLE "poll_options" (
+    "id" bigserial PRIMARY KEY,
+    "poll_id" bigint NOT NULL REFERENCES polls(id) ON DELETE CASCADE,
+    "position" integer NOT NULL,
+    "text" varchar NOT NULL
+);
+
+CREATE INDEX "poll_options_poll_id_idx" ON "poll_options" ("poll_id");
+
+-- voters of anonymous polls are stored too, they are only hidden from
+-- responses; a user votes for an option at most once
+CREATE TABLE "poll_votes" (
+    "id" bigserial PRIMARY KEY,
+    "poll_id" bigint NOT NULL REFERENCES polls(id) ON DELETE CASCADE,
+    "option_id" bigint NOT NULL REFERENCES poll_options(id) ON DELETE CASCADE,
+    "user_id" bigint NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "poll_votes" ADD CONSTRAINT "poll_votes_option_id_user_id_unique" UNIQUE ("option_id", "user_id");
+CREATE INDEX "poll_votes_poll_id_user_id_idx" ON "poll_votes" ("poll_id", "user_id");
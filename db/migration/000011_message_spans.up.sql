@@ -0,0 +1,2 @@
+-- sanitized markdown spans of the content, see package markdown
+ALTER TABLE "messages" ADD COLUMN "spans" jsonb DEFAULT NULL;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS "held_messages";
+DROP TABLE IF EXISTS "moderation_rules";
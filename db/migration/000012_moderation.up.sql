@@ -0,0 +1,35 @@
+-- kind is word, regex, link_allow or link_deny; action is what happens to a
+-- matching message, reject or hold
+CREATE TABLE "moderation_rules" (
+    "id" bigserial PRIMARY KEY,
+    "channel_id" bigint NOT NULL REFERENCES channels(id) ON DELETE CASCADE,
+    "kind" varchar NOT NULL,
+    "pattern" varchar NOT NULL,
+    "action" varchar NOT NULL DEFAULT 'reject',
+    "created_by" bigint NOT NULL REFERENCES users(id),
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "moderation_rules" ADD CONSTRAINT "moderation_rules_channel_id_kind_pattern_unique" UNIQUE ("channel_id", "kind", "pattern");
+
+-- messages held for review; they only become messages once approved
+CREATE TABLE "held_messages" (
+    "id" bigserial PRIMARY KEY,
+    "channel_id" bigint NOT NULL REFERENCES channels(id) ON DELETE CASCADE,
+    "user_id" bigint NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    "content" varchar NOT NULL,
+    "ttl_seconds" integer DEFAULT NULL,
+    "is_bot" boolean NOT NULL DEFAULT false,
+    "username_override" varchar DEFAULT NULL,
+    "attachments" jsonb DEFAULT NULL,
+    "webhook_id" bigint DEFAULT NULL REFERENCES incoming_webhooks(id) ON DELETE SET NULL,
+    "filter" varchar NOT NULL,
+    "reason" varchar NOT NULL,
+    "status" varchar NOT NULL DEFAULT 'pending',
+    "reviewed_by" bigint DEFAULT NULL REFERENCES users(id) ON DELETE SET NULL,
+    "reviewed_at" timestamptz DEFAULT NULL,
+    "message_id" bigint DEFAULT NULL REFERENCES messages(id) ON DELETE SET NULL,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX "held_messages_pending_idx" ON "held_messages" ("channel_id", "id") WHERE "status" = 'pending';
@@ -0,0 +1 @@
+ALTER TABLE "channels" DROP COLUMN IF EXISTS "slow_mode_seconds";
@@ -0,0 +1,2 @@
+-- minimum number of seconds between two messages of a member, 0 disables
+ALTER TABLE "channels" ADD COLUMN "slow_mode_seconds" integer NOT NULL DEFAULT 0;
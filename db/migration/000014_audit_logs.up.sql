@@ -0,0 +1,14 @@
+-- security relevant events; actor_id is NULL for events the server raised
+-- itself, such as a lockout
+CREATE TABLE "audit_logs" (
+    "id" bigserial PRIMARY KEY,
+    "actor_id" bigint DEFAULT NULL REFERENCES users(id) ON DELETE SET NULL,
+    "action" varchar NOT NULL,
+    "target_user_id" bigint DEFAULT NULL REFERENCES users(id) ON DELETE SET NULL,
+    "client_ip" varchar DEFAULT NULL,
+    "details" jsonb DEFAULT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX "audit_logs_target_user_id_idx" ON "audit_logs" ("target_user_id");
+CREATE INDEX "audit_logs_created_at_idx" ON "audit_logs" ("created_at");
@@ -0,0 +1,11 @@
+-- every refresh token issued for a session; a renewal uses the presented
+-- token and issues the next one, so the rows of a session form its family
+CREATE TABLE "refresh_tokens" (
+    "id" uuid PRIMARY KEY,
+    "session_id" uuid NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+    "expires_at" timestamptz NOT NULL,
+    "used_at" timestamptz DEFAULT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX "refresh_tokens_session_id_idx" ON "refresh_tokens" ("session_id");
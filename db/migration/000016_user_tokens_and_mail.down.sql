@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "mail_outbox";
+DROP TABLE IF EXISTS "user_tokens";
+ALTER TABLE "users" DROP COLUMN IF EXISTS "verified_at";
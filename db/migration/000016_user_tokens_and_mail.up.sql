@@ -0,0 +1,33 @@
+-- accounts that existed before verification are trusted as they are
+ALTER TABLE "users" ADD COLUMN "verified_at" timestamptz DEFAULT NULL;
+UPDATE "users" SET "verified_at" = "created_at";
+
+-- single use tokens mailed to users, only their sha256 is stored; email is
+-- the address the token was sent to
+CREATE TABLE "user_tokens" (
+    "id" bigserial PRIMARY KEY,
+    "user_id" bigint NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    "purpose" varchar NOT NULL,
+    "token_hash" varchar NOT NULL UNIQUE,
+    "email" varchar NOT NULL,
+    "expires_at" timestamptz NOT NULL,
+    "used_at" timestamptz DEFAULT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX "user_tokens_user_id_idx" ON "user_tokens" ("user_id", "purpose");
+
+CREATE TABLE "mail_outbox" (
+    "id" bigserial PRIMARY KEY,
+    "recipient" varchar NOT NULL,
+    "subject" varchar NOT NULL,
+    "body" text NOT NULL,
+    "status" varchar NOT NULL DEFAULT 'pending',
+    "attempts" integer NOT NULL DEFAULT 0,
+    "next_attempt_at" timestamptz NOT NULL DEFAULT (now()),
+    "last_error" varchar DEFAULT NULL,
+    "sent_at" timestamptz DEFAULT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX "mail_outbox_pending_idx" ON "mail_outbox" ("next_attempt_at") WHERE "status" = 'pending';
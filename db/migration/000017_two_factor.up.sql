@@ -0,0 +1,21 @@
+-- the totp secret of a user, sealed with the server key. It only counts once
+-- confirmed with a code; last_used_step keeps a code from being replayed.
+CREATE TABLE "user_totp" (
+    "user_id" bigint PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    "secret" bytea NOT NULL,
+    "confirmed_at" timestamptz DEFAULT NULL,
+    "last_used_step" bigint DEFAULT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+-- one-time codes for when the authenticator is lost, only their sha256 is
+-- stored
+CREATE TABLE "user_recovery_codes" (
+    "id" bigserial PRIMARY KEY,
+    "user_id" bigint NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    "code_hash" varchar NOT NULL,
+    "used_at" timestamptz DEFAULT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE UNIQUE INDEX "user_recovery_codes_user_id_code_hash_idx" ON "user_recovery_codes" ("user_id", "code_hash");
@@ -0,0 +1,14 @@
+-- accounts of identity providers linked to users; subject is the stable id
+-- the provider gives the account, email the address it last reported
+CREATE TABLE "user_identities" (
+    "id" bigserial PRIMARY KEY,
+    "user_id" bigint NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    "provider" varchar NOT NULL,
+    "subject" varchar NOT NULL,
+    "email" varchar NOT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT (now()),
+    "last_login_at" timestamptz DEFAULT NULL
+);
+
+ALTER TABLE "user_identities" ADD CONSTRAINT "user_identities_provider_subject_unique" UNIQUE ("provider", "subject");
+CREATE INDEX "user_identities_user_id_idx" ON "user_identities" ("user_id");
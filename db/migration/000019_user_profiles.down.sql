@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS "users_email_key";
+DROP INDEX IF EXISTS "users_username_key";
+ALTER TABLE "users" DROP COLUMN IF EXISTS "avatar_key";
+ALTER TABLE "users" DROP COLUMN IF EXISTS "time_zone";
+ALTER TABLE "users" DROP COLUMN IF EXISTS "bio";
+ALTER TABLE "users" DROP COLUMN IF EXISTS "display_name";
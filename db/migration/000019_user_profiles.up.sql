@@ -0,0 +1,25 @@
+ALTER TABLE "users" ADD COLUMN "display_name" varchar DEFAULT NULL;
+ALTER TABLE "users" ADD COLUMN "bio" varchar DEFAULT NULL;
+ALTER TABLE "users" ADD COLUMN "time_zone" varchar DEFAULT NULL;
+ALTER TABLE "users" ADD COLUMN "avatar_key" varchar DEFAULT NULL;
+
+-- usernames were never unique, the later accounts sharing one get their id
+-- appended
+UPDATE "users" AS u
+SET "username" = u."username" || '-' || u."id"
+WHERE EXISTS (
+    SELECT 1 FROM "users" AS o
+    WHERE lower(o."username") = lower(u."username") AND o."id" < u."id"
+);
+
+-- accounts sharing an email cannot be told apart at login, which one keeps
+-- the address is for an operator to decide
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM "users" GROUP BY lower("email") HAVING count(*) > 1) THEN
+        RAISE EXCEPTION 'some users share an email address, resolve the duplicates before migrating';
+    END IF;
+END $$;
+
+CREATE UNIQUE INDEX "users_username_key" ON "users" (lower("username"));
+CREATE UNIQUE INDEX "users_email_key" ON "users" (lower("email"));
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS "memberships_user_id_channel_id_key";
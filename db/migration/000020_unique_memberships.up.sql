@@ -0,0 +1,16 @@
+-- joining was not atomic, a user could end up with several memberships of a
+-- channel; the admin one, or else the oldest, is kept
+DELETE FROM "memberships"
+WHERE "id" IN (
+    SELECT "id"
+    FROM (
+        SELECT "id", row_number() OVER (
+            PARTITION BY "user_id", "channel_id"
+            ORDER BY "role" = 'admin' DESC, "id"
+        ) AS "rank"
+        FROM "memberships"
+    ) AS "ranked"
+    WHERE "rank" > 1
+);
+
+CREATE UNIQUE INDEX "memberships_user_id_channel_id_key" ON "memberships" ("user_id", "channel_id");
@@ -0,0 +1 @@
+-- promoted admins cannot be told apart from the others, they stay admins
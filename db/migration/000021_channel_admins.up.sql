@@ -0,0 +1,12 @@
+-- channels created before roles existed have no admin, their earliest member
+-- becomes one
+UPDATE "memberships"
+SET "role" = 'admin'
+WHERE "id" IN (
+    SELECT DISTINCT ON ("channel_id") "id"
+    FROM "memberships"
+    WHERE "channel_id" NOT IN (
+        SELECT "channel_id" FROM "memberships" WHERE "role" = 'admin'
+    )
+    ORDER BY "channel_id", "created_at", "id"
+);
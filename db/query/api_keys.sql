@@ -0,0 +1,29 @@
+-- name: CreateApiKey :one
+INSERT INTO api_keys (
+  user_id, name, prefix, key_hash, scopes, expires_at
+) VALUES (
+  sqlc.arg(user_id), sqlc.arg(name), sqlc.arg(prefix), sqlc.arg(key_hash), sqlc.arg(scopes), sqlc.narg(expires_at)
+)
+RETURNING *;
+
+-- name: GetApiKeyByHash :one
+SELECT *
+FROM api_keys
+where key_hash = sqlc.arg(key_hash) AND revoked_at IS NULL;
+
+-- name: GetApiKeysByUserId :many
+SELECT *
+FROM api_keys
+where user_id = sqlc.arg(user_id) AND revoked_at IS NULL
+ORDER BY id;
+
+-- name: RevokeApiKey :one
+UPDATE api_keys
+SET revoked_at = now()
+WHERE id = sqlc.arg(id) AND user_id = sqlc.arg(user_id) AND revoked_at IS NULL
+RETURNING *;
+
+-- name: TouchApiKey :exec
+UPDATE api_keys
+SET last_used_at = now()
+WHERE id = sqlc.arg(id);
@@ -0,0 +1,6 @@
+-- name: CreateAuditLog :exec
+INSERT INTO audit_logs (
+  actor_id, action, target_user_id, client_ip, details
+) VALUES (
+  sqlc.narg(actor_id), sqlc.arg(action), sqlc.narg(target_user_id), sqlc.narg(client_ip), sqlc.narg(details)
+);
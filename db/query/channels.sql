@@ -14,4 +14,49 @@ FROM channels;
 -- name: GetChannelById :one
 SELECT *
 FROM channels
-where id = sqlc.arg(id);
\ No newline at end of file
+where id = sqlc.arg(id);
+
+-- name: GetChannelByIdForUpdate :one
+SELECT *
+FROM channels
+where id = sqlc.arg(id)
+FOR UPDATE;
+
+-- name: UpdateChannelMessageTtl :one
+UPDATE channels
+SET message_ttl_seconds = sqlc.narg(message_ttl_seconds)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: UpdateChannelRetention :one
+UPDATE channels
+SET retention_policy = sqlc.arg(retention_policy), retention_value = sqlc.narg(retention_value)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: ImportChannel :one
+INSERT INTO channels (
+  name, created_at, message_ttl_seconds, retention_policy, retention_value
+) VALUES (
+  sqlc.arg(name), sqlc.arg(created_at), sqlc.narg(message_ttl_seconds), sqlc.arg(retention_policy), sqlc.narg(retention_value)
+)
+RETURNING *;
+
+-- name: GetChannelByName :one
+SELECT *
+FROM channels
+where name = sqlc.arg(name)
+ORDER BY id
+LIMIT 1;
+
+-- name: UpdateChannelTopic :one
+UPDATE channels
+SET topic = sqlc.narg(topic)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: UpdateChannelSlowMode :one
+UPDATE channels
+SET slow_mode_seconds = sqlc.arg(slow_mode_seconds)
+WHERE id = sqlc.arg(id)
+RETURNING *;
@@ -0,0 +1,24 @@
+-- name: CreateIncomingWebhook :one
+INSERT INTO incoming_webhooks (
+  channel_id, name, token_hash, created_by
+) VALUES (
+  sqlc.arg(channel_id), sqlc.arg(name), sqlc.arg(token_hash), sqlc.arg(created_by)
+)
+RETURNING *;
+
+-- name: GetIncomingWebhookByTokenHash :one
+SELECT *
+FROM incoming_webhooks
+where token_hash = sqlc.arg(token_hash) AND revoked_at IS NULL;
+
+-- name: GetIncomingWebhooksByChannelId :many
+SELECT *
+FROM incoming_webhooks
+where channel_id = sqlc.arg(channel_id) AND revoked_at IS NULL
+ORDER BY id;
+
+-- name: RevokeIncomingWebhook :one
+UPDATE incoming_webhooks
+SET revoked_at = now()
+WHERE id = sqlc.arg(id) AND channel_id = sqlc.arg(channel_id) AND revoked_at IS NULL
+RETURNING *;
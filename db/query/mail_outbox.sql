@@ -0,0 +1,36 @@
+-- name: CreateMailOutboxEntry :exec
+INSERT INTO mail_outbox (
+  recipient, subject, body
+) VALUES (
+  sqlc.arg(recipient), sqlc.arg(subject), sqlc.arg(body)
+);
+
+-- name: ClaimDueMailOutboxEntries :many
+-- claimed rows are leased by pushing next_attempt_at forward, so the mail is
+-- sent outside the transaction and a crashed node's claims are retried.
+UPDATE mail_outbox
+SET attempts = attempts + 1, next_attempt_at = now() + make_interval(secs => sqlc.arg(lease_seconds)::integer)
+WHERE id IN (
+  SELECT id
+  FROM mail_outbox
+  WHERE status = 'pending' AND next_attempt_at <= now()
+  ORDER BY next_attempt_at
+  LIMIT sqlc.arg(batch_size)
+  FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: MarkMailOutboxEntrySent :exec
+UPDATE mail_outbox
+SET status = 'sent', sent_at = now(), last_error = NULL
+WHERE id = sqlc.arg(id);
+
+-- name: RetryMailOutboxEntry :exec
+UPDATE mail_outbox
+SET next_attempt_at = sqlc.arg(next_attempt_at), last_error = sqlc.arg(last_error)
+WHERE id = sqlc.arg(id);
+
+-- name: FailMailOutboxEntry :exec
+UPDATE mail_outbox
+SET status = 'failed', last_error = sqlc.arg(last_error)
+WHERE id = sqlc.arg(id);
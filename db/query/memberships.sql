@@ -1,8 +1,8 @@
 -- name: CreateMembership :one
 INSERT INTO memberships (
-  user_id, channel_id
+  user_id, channel_id, role
 ) VALUES (
-  sqlc.arg(user_id), sqlc.arg(channel_id) 
+  sqlc.arg(user_id), sqlc.arg(channel_id), sqlc.arg(role)
 )
 RETURNING *;
 
@@ -19,4 +19,42 @@ where user_id = sqlc.arg(user_id);
 -- name: GetMembershipsByChannelId :many
 SELECT *
 FROM memberships
-where channel_id = sqlc.arg(channel_id);
\ No newline at end of file
+where channel_id = sqlc.arg(channel_id);
+
+-- name: GetMembership :one
+SELECT *
+FROM memberships
+where user_id = sqlc.arg(user_id) AND channel_id = sqlc.arg(channel_id)
+LIMIT 1;
+
+-- name: ImportMembership :one
+INSERT INTO memberships (
+  user_id, channel_id, role, created_at
+) VALUES (
+  sqlc.arg(user_id), sqlc.arg(channel_id), sqlc.arg(role), sqlc.arg(created_at)
+)
+RETURNING *;
+
+-- name: GetChannelMembersForExport :many
+SELECT memberships.role, memberships.created_at, users.email, users.username
+FROM memberships
+JOIN users ON users.id = memberships.user_id
+where memberships.channel_id = sqlc.arg(channel_id)
+ORDER BY memberships.id;
+
+-- name: DeleteMembership :one
+DELETE FROM memberships
+WHERE user_id = sqlc.arg(user_id) AND channel_id = sqlc.arg(channel_id)
+RETURNING *;
+
+-- name: UpdateMembershipMutedUntil :one
+UPDATE memberships
+SET muted_until = sqlc.narg(muted_until)
+WHERE user_id = sqlc.arg(user_id) AND channel_id = sqlc.arg(channel_id)
+RETURNING *;
+
+-- name: UpdateMembershipRole :one
+UPDATE memberships
+SET role = sqlc.arg(role)
+WHERE user_id = sqlc.arg(user_id) AND channel_id = sqlc.arg(channel_id)
+RETURNING *;
@@ -0,0 +1,88 @@
+-- name: CreateMessage :one
+-- expires_at comes from the message ttl, falling back to the channel ttl; NULL when neither is set.
+INSERT INTO messages (
+  channel_id, user_id, content, expires_at, is_bot, username_override, attachments, webhook_id, spans
+) VALUES (
+  sqlc.arg(channel_id), sqlc.arg(user_id), sqlc.arg(content),
+  now() + make_interval(secs => COALESCE(sqlc.narg(ttl_seconds)::integer, (SELECT message_ttl_seconds FROM channels WHERE id = sqlc.arg(channel_id)))),
+  sqlc.arg(is_bot), sqlc.narg(username_override), sqlc.narg(attachments), sqlc.narg(webhook_id), sqlc.narg(spans)
+)
+RETURNING *;
+
+-- name: GetMessageById :one
+SELECT *
+FROM messages
+where id = sqlc.arg(id);
+
+-- name: DeleteExpiredMessages :many
+DELETE FROM messages
+WHERE id IN (
+  SELECT id
+  FROM messages
+  WHERE expires_at <= now()
+  ORDER BY expires_at
+  LIMIT sqlc.arg(batch_size)
+  FOR UPDATE SKIP LOCKED
+)
+RETURNING id, channel_id;
+
+-- name: GetRetentionCutoffMessageId :one
+-- newest message id that falls outside the keep window of the channel.
+SELECT id
+FROM messages
+WHERE channel_id = sqlc.arg(channel_id)
+ORDER BY id DESC
+OFFSET sqlc.arg(keep)
+LIMIT 1;
+
+-- name: CountChannelMessagesBefore :one
+SELECT count(*)
+FROM messages
+WHERE channel_id = sqlc.arg(channel_id) AND created_at < sqlc.arg(before);
+
+-- name: CountChannelMessagesUpTo :one
+SELECT count(*)
+FROM messages
+WHERE channel_id = sqlc.arg(channel_id) AND id <= sqlc.arg(max_id);
+
+-- name: PurgeChannelMessagesBefore :many
+DELETE FROM messages
+WHERE id IN (
+  SELECT id
+  FROM messages
+  WHERE channel_id = sqlc.arg(channel_id) AND created_at < sqlc.arg(before)
+  ORDER BY id
+  LIMIT sqlc.arg(batch_size)
+  FOR UPDATE SKIP LOCKED
+)
+RETURNING id;
+
+-- name: PurgeChannelMessagesUpTo :many
+DELETE FROM messages
+WHERE id IN (
+  SELECT id
+  FROM messages
+  WHERE channel_id = sqlc.arg(channel_id) AND id <= sqlc.arg(max_id)
+  ORDER BY id
+  LIMIT sqlc.arg(batch_size)
+  FOR UPDATE SKIP LOCKED
+)
+RETURNING id;
+
+-- name: ImportMessage :one
+INSERT INTO messages (
+  channel_id, user_id, content, created_at, expires_at, is_bot, username_override, attachments, spans
+) VALUES (
+  sqlc.arg(channel_id), sqlc.arg(user_id), sqlc.arg(content), sqlc.arg(created_at), sqlc.narg(expires_at),
+  sqlc.arg(is_bot), sqlc.narg(username_override), sqlc.narg(attachments), sqlc.narg(spans)
+)
+RETURNING *;
+
+-- name: GetChannelMessagesForExport :many
+-- keyset pagination, pass the last id of the previous page as after_id.
+SELECT messages.id, messages.content, messages.created_at, messages.expires_at, messages.is_bot, messages.username_override, messages.attachments, users.email, users.username
+FROM messages
+JOIN users ON users.id = messages.user_id
+WHERE messages.channel_id = sqlc.arg(channel_id) AND messages.id > sqlc.arg(after_id)
+ORDER BY messages.id
+LIMIT sqlc.arg(page_size);
@@ -0,0 +1,51 @@
+-- name: CreateModerationRule :one
+INSERT INTO moderation_rules (
+  channel_id, kind, pattern, action, created_by
+) VALUES (
+  sqlc.arg(channel_id), sqlc.arg(kind), sqlc.arg(pattern), sqlc.arg(action), sqlc.arg(created_by)
+)
+RETURNING *;
+
+-- name: GetModerationRulesByChannelId :many
+SELECT *
+FROM moderation_rules
+where channel_id = sqlc.arg(channel_id)
+ORDER BY id;
+
+-- name: DeleteModerationRule :one
+DELETE FROM moderation_rules
+WHERE id = sqlc.arg(id) AND channel_id = sqlc.arg(channel_id)
+RETURNING *;
+
+-- name: CreateHeldMessage :one
+INSERT INTO held_messages (
+  channel_id, user_id, content, ttl_seconds, is_bot, username_override, attachments, webhook_id, filter, reason
+) VALUES (
+  sqlc.arg(channel_id), sqlc.arg(user_id), sqlc.arg(content), sqlc.narg(ttl_seconds), sqlc.arg(is_bot), sqlc.narg(username_override), sqlc.narg(attachments), sqlc.narg(webhook_id), sqlc.arg(filter), sqlc.arg(reason)
+)
+RETURNING *;
+
+-- name: GetHeldMessageById :one
+SELECT *
+FROM held_messages
+where id = sqlc.arg(id);
+
+-- name: GetPendingHeldMessagesByChannelId :many
+SELECT held_messages.id, held_messages.channel_id, held_messages.user_id, held_messages.content, held_messages.ttl_seconds, held_messages.is_bot, held_messages.username_override, held_messages.attachments, held_messages.webhook_id, held_messages.filter, held_messages.reason, held_messages.status, held_messages.reviewed_by, held_messages.reviewed_at, held_messages.message_id, held_messages.created_at, users.username
+FROM held_messages
+JOIN users ON users.id = held_messages.user_id
+where held_messages.channel_id = sqlc.arg(channel_id) AND held_messages.status = 'pending'
+ORDER BY held_messages.id;
+
+-- name: ApproveHeldMessage :one
+-- only pending messages can be reviewed, no rows means someone else got there first.
+UPDATE held_messages
+SET status = 'approved', reviewed_by = sqlc.narg(reviewed_by), reviewed_at = now(), message_id = sqlc.narg(message_id)
+WHERE id = sqlc.arg(id) AND status = 'pending'
+RETURNING *;
+
+-- name: RejectHeldMessage :one
+UPDATE held_messages
+SET status = 'rejected', reviewed_by = sqlc.narg(reviewed_by), reviewed_at = now()
+WHERE id = sqlc.arg(id) AND status = 'pending'
+RETURNING *;
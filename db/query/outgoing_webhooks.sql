@@ -0,0 +1,76 @@
+-- name: CreateOutgoingWebhook :one
+INSERT INTO outgoing_webhooks (
+  channel_id, url, secret, trigger_words, created_by
+) VALUES (
+  sqlc.arg(channel_id), sqlc.arg(url), sqlc.arg(secret), sqlc.arg(trigger_words), sqlc.arg(created_by)
+)
+RETURNING *;
+
+-- name: GetOutgoingWebhookById :one
+SELECT *
+FROM outgoing_webhooks
+where id = sqlc.arg(id);
+
+-- name: GetOutgoingWebhooksByChannelId :many
+SELECT *
+FROM outgoing_webhooks
+where channel_id = sqlc.arg(channel_id) AND revoked_at IS NULL
+ORDER BY id;
+
+-- name: RevokeOutgoingWebhook :one
+UPDATE outgoing_webhooks
+SET revoked_at = now()
+WHERE id = sqlc.arg(id) AND channel_id = sqlc.arg(channel_id) AND revoked_at IS NULL
+RETURNING *;
+
+-- name: CreateWebhookOutboxEntry :exec
+INSERT INTO webhook_outbox (
+  webhook_id, message_id, payload
+) VALUES (
+  sqlc.arg(webhook_id), sqlc.arg(message_id), sqlc.arg(payload)
+);
+
+-- name: ClaimDueWebhookOutboxEntries :many
+-- claimed rows are leased by pushing next_attempt_at forward, so the request
+-- runs outside the transaction and a crashed node's claims are retried.
+UPDATE webhook_outbox
+SET attempts = attempts + 1, next_attempt_at = now() + make_interval(secs => sqlc.arg(lease_seconds)::integer)
+WHERE id IN (
+  SELECT id
+  FROM webhook_outbox
+  WHERE status = 'pending' AND next_attempt_at <= now()
+  ORDER BY next_attempt_at
+  LIMIT sqlc.arg(batch_size)
+  FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: MarkWebhookOutboxEntryDelivered :exec
+UPDATE webhook_outbox
+SET status = 'delivered', delivered_at = now(), last_error = NULL
+WHERE id = sqlc.arg(id);
+
+-- name: RetryWebhookOutboxEntry :exec
+UPDATE webhook_outbox
+SET next_attempt_at = sqlc.arg(next_attempt_at), last_error = sqlc.arg(last_error)
+WHERE id = sqlc.arg(id);
+
+-- name: FailWebhookOutboxEntry :exec
+UPDATE webhook_outbox
+SET status = 'failed', last_error = sqlc.arg(last_error)
+WHERE id = sqlc.arg(id);
+
+-- name: CreateWebhookDelivery :exec
+INSERT INTO webhook_deliveries (
+  webhook_id, outbox_id, attempt, status_code, error, duration_ms
+) VALUES (
+  sqlc.arg(webhook_id), sqlc.arg(outbox_id), sqlc.arg(attempt), sqlc.narg(status_code), sqlc.narg(error), sqlc.arg(duration_ms)
+);
+
+-- name: GetWebhookDeliveries :many
+SELECT webhook_deliveries.id, webhook_deliveries.outbox_id, webhook_deliveries.attempt, webhook_deliveries.status_code, webhook_deliveries.error, webhook_deliveries.duration_ms, webhook_deliveries.created_at, webhook_outbox.message_id, webhook_outbox.status
+FROM webhook_deliveries
+JOIN webhook_outbox ON webhook_outbox.id = webhook_deliveries.outbox_id
+where webhook_deliveries.webhook_id = sqlc.arg(webhook_id)
+ORDER BY webhook_deliveries.id DESC
+LIMIT sqlc.arg(page_size);
@@ -0,0 +1,40 @@
+-- name: CreatePin :one
+INSERT INTO pins (
+  channel_id, message_id, pinned_by
+) VALUES (
+  sqlc.arg(channel_id), sqlc.arg(message_id), sqlc.arg(pinned_by)
+)
+RETURNING *;
+
+-- name: DeletePin :one
+DELETE FROM pins
+WHERE channel_id = sqlc.arg(channel_id) AND message_id = sqlc.arg(message_id)
+RETURNING *;
+
+-- name: CountPinsByChannelId :one
+SELECT count(*)
+FROM pins
+where channel_id = sqlc.arg(channel_id);
+
+-- name: GetPinsByChannelId :many
+SELECT pins.id, pins.channel_id, pins.message_id, pins.pinned_by, pins.created_at, messages.content, messages.user_id, COALESCE(messages.username_override, users.username)::varchar AS username
+FROM pins
+JOIN messages ON messages.id = pins.message_id
+JOIN users ON users.id = messages.user_id
+where pins.channel_id = sqlc.arg(channel_id)
+ORDER BY pins.created_at DESC;
+
+-- name: ImportPin :one
+INSERT INTO pins (
+  channel_id, message_id, pinned_by, created_at
+) VALUES (
+  sqlc.arg(channel_id), sqlc.arg(message_id), sqlc.arg(pinned_by), sqlc.arg(created_at)
+)
+RETURNING *;
+
+-- name: GetChannelPinsForExport :many
+SELECT pins.message_id, pins.created_at, users.email
+FROM pins
+JOIN users ON users.id = pins.pinned_by
+where pins.channel_id = sqlc.arg(channel_id)
+ORDER BY pins.id;
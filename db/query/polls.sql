@@ -0,0 +1,86 @@
+-- name: CreatePoll :one
+INSERT INTO polls (
+  message_id, channel_id, created_by, question, multiple_choice, anonymous, closes_at
+) VALUES (
+  sqlc.arg(message_id), sqlc.arg(channel_id), sqlc.arg(created_by), sqlc.arg(question), sqlc.arg(multiple_choice), sqlc.arg(anonymous), sqlc.narg(closes_at)
+)
+RETURNING *;
+
+-- name: CreatePollOption :one
+INSERT INTO poll_options (
+  poll_id, position, text
+) VALUES (
+  sqlc.arg(poll_id), sqlc.arg(position), sqlc.arg(text)
+)
+RETURNING *;
+
+-- name: GetPollById :one
+SELECT *
+FROM polls
+where id = sqlc.arg(id);
+
+-- name: GetPollByIdForUpdate :one
+SELECT *
+FROM polls
+where id = sqlc.arg(id)
+FOR UPDATE;
+
+-- name: GetPollOptions :many
+SELECT *
+FROM poll_options
+where poll_id = sqlc.arg(poll_id)
+ORDER BY position;
+
+-- name: CountPollVotes :many
+SELECT option_id, count(*) AS votes
+FROM poll_votes
+where poll_id = sqlc.arg(poll_id)
+GROUP BY option_id;
+
+-- name: CountPollVoters :one
+SELECT count(DISTINCT user_id)
+FROM poll_votes
+where poll_id = sqlc.arg(poll_id);
+
+-- name: GetPollVoters :many
+SELECT poll_votes.option_id, users.username
+FROM poll_votes
+JOIN users ON users.id = poll_votes.user_id
+where poll_votes.poll_id = sqlc.arg(poll_id)
+ORDER BY poll_votes.id;
+
+-- name: GetUserPollVotes :many
+SELECT option_id
+FROM poll_votes
+where poll_id = sqlc.arg(poll_id) AND user_id = sqlc.arg(user_id)
+ORDER BY option_id;
+
+-- name: CreatePollVote :exec
+INSERT INTO poll_votes (
+  poll_id, option_id, user_id
+) VALUES (
+  sqlc.arg(poll_id), sqlc.arg(option_id), sqlc.arg(user_id)
+);
+
+-- name: DeleteUserPollVotes :exec
+DELETE FROM poll_votes
+WHERE poll_id = sqlc.arg(poll_id) AND user_id = sqlc.arg(user_id);
+
+-- name: ClosePoll :one
+UPDATE polls
+SET closed_at = now()
+WHERE id = sqlc.arg(id) AND closed_at IS NULL
+RETURNING *;
+
+-- name: CloseDuePolls :many
+UPDATE polls
+SET closed_at = now()
+WHERE id IN (
+  SELECT id
+  FROM polls
+  WHERE closed_at IS NULL AND closes_at <= now()
+  ORDER BY closes_at
+  LIMIT sqlc.arg(batch_size)
+  FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
@@ -0,0 +1,17 @@
+-- name: CreateRefreshToken :exec
+INSERT INTO refresh_tokens (
+  id, session_id, expires_at
+) VALUES (
+  sqlc.arg(id), sqlc.arg(session_id), sqlc.arg(expires_at)
+);
+
+-- name: GetRefreshToken :one
+SELECT * FROM refresh_tokens
+WHERE id = sqlc.arg(id);
+
+-- name: UseRefreshToken :one
+-- marks the token used; no row when it is unknown or was used before.
+UPDATE refresh_tokens
+SET used_at = now()
+WHERE id = sqlc.arg(id) AND used_at IS NULL
+RETURNING *;
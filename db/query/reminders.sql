@@ -0,0 +1,32 @@
+-- name: CreateReminder :one
+INSERT INTO reminders (
+  user_id, message_id, remind_at
+) VALUES (
+  sqlc.arg(user_id), sqlc.arg(message_id), sqlc.arg(remind_at)
+)
+RETURNING *;
+
+-- name: GetPendingRemindersByUserId :many
+SELECT *
+FROM reminders
+where user_id = sqlc.arg(user_id) AND status = 'pending'
+ORDER BY remind_at;
+
+-- name: CancelReminder :one
+UPDATE reminders
+SET status = 'cancelled'
+WHERE id = sqlc.arg(id) AND user_id = sqlc.arg(user_id) AND status = 'pending'
+RETURNING *;
+
+-- name: ClaimDueReminders :many
+UPDATE reminders
+SET status = 'sent'
+WHERE id IN (
+  SELECT id
+  FROM reminders
+  WHERE status = 'pending' AND remind_at <= now()
+  ORDER BY remind_at
+  LIMIT sqlc.arg(batch_size)
+  FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
@@ -0,0 +1,20 @@
+-- name: CreateSavedItem :one
+INSERT INTO saved_items (
+  user_id, message_id
+) VALUES (
+  sqlc.arg(user_id), sqlc.arg(message_id)
+)
+RETURNING *;
+
+-- name: DeleteSavedItem :one
+DELETE FROM saved_items
+WHERE user_id = sqlc.arg(user_id) AND message_id = sqlc.arg(message_id)
+RETURNING *;
+
+-- name: GetSavedItemsByUserId :many
+SELECT saved_items.id, saved_items.user_id, saved_items.message_id, saved_items.created_at, messages.channel_id, messages.content, COALESCE(messages.username_override, users.username)::varchar AS username
+FROM saved_items
+JOIN messages ON messages.id = saved_items.message_id
+JOIN users ON users.id = messages.user_id
+where saved_items.user_id = sqlc.arg(user_id)
+ORDER BY saved_items.created_at DESC;
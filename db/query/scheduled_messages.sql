@@ -0,0 +1,43 @@
+-- name: CreateScheduledMessage :one
+INSERT INTO scheduled_messages (
+  channel_id, user_id, content, send_at
+) VALUES (
+  sqlc.arg(channel_id), sqlc.arg(user_id), sqlc.arg(content), sqlc.arg(send_at)
+)
+RETURNING *;
+
+-- name: GetPendingScheduledMessagesByUserId :many
+SELECT *
+FROM scheduled_messages
+where user_id = sqlc.arg(user_id) AND status = 'pending'
+ORDER BY send_at;
+
+-- name: CancelScheduledMessage :one
+UPDATE scheduled_messages
+SET status = 'cancelled'
+WHERE id = sqlc.arg(id) AND user_id = sqlc.arg(user_id) AND status = 'pending'
+RETURNING *;
+
+-- name: ClaimDueScheduledMessages :many
+UPDATE scheduled_messages
+SET status = 'sent'
+WHERE id IN (
+  SELECT id
+  FROM scheduled_messages
+  WHERE status = 'pending' AND send_at <= now()
+  ORDER BY send_at
+  LIMIT sqlc.arg(batch_size)
+  FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: SetScheduledMessageMessageId :exec
+UPDATE scheduled_messages
+SET message_id = sqlc.arg(message_id)
+WHERE id = sqlc.arg(id);
+
+-- name: FailScheduledMessage :exec
+-- also marks claimed messages that were refused, never one that went out.
+UPDATE scheduled_messages
+SET status = 'failed'
+WHERE id = sqlc.arg(id) AND message_id IS NULL;
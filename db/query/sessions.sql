@@ -14,11 +14,35 @@ INSERT INTO sessions (
 UPDATE sessions
 SET
     is_blocked = COALESCE(sqlc.narg(is_blocked), is_blocked),
-    is_logged_out = COALESCE(sqlc.narg(is_logged_out),is_logged_out)
+    is_logged_out = COALESCE(sqlc.narg(is_logged_out),is_logged_out),
+    refresh_token = COALESCE(sqlc.narg(refresh_token), refresh_token)
 WHERE
     id = sqlc.arg(id)
 RETURNING *;
 
 -- name: GetSession :one
 SELECT * FROM sessions 
-WHERE id = sqlc.arg(id);
\ No newline at end of file
+WHERE id = sqlc.arg(id);
+
+-- name: GetActiveSessionsByEmail :many
+SELECT * FROM sessions
+WHERE email = sqlc.arg(email) AND expires_at > now() AND NOT is_blocked AND NOT is_logged_out
+ORDER BY created_at DESC;
+
+-- name: LogoutOtherSessions :many
+UPDATE sessions
+SET is_logged_out = true
+WHERE email = sqlc.arg(email) AND id <> sqlc.arg(id) AND expires_at > now() AND NOT is_blocked AND NOT is_logged_out
+RETURNING id;
+
+-- name: BlockSessionsByEmail :many
+UPDATE sessions
+SET is_blocked = true
+WHERE email = sqlc.arg(email) AND expires_at > now() AND NOT is_blocked AND NOT is_logged_out
+RETURNING id;
+
+-- name: LogoutSessionsByEmail :many
+UPDATE sessions
+SET is_logged_out = true
+WHERE email = sqlc.arg(email) AND expires_at > now() AND NOT is_blocked AND NOT is_logged_out
+RETURNING id;
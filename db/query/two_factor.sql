@@ -0,0 +1,53 @@
+-- name: UpsertPendingTOTP :one
+-- replaces an unconfirmed secret, no row when the user has totp enabled already.
+INSERT INTO user_totp (
+  user_id, secret
+) VALUES (
+  sqlc.arg(user_id), sqlc.arg(secret)
+)
+ON CONFLICT (user_id) DO UPDATE
+SET secret = EXCLUDED.secret, last_used_step = NULL, created_at = now()
+WHERE user_totp.confirmed_at IS NULL
+RETURNING *;
+
+-- name: GetTOTPByUserId :one
+SELECT * FROM user_totp
+WHERE user_id = sqlc.arg(user_id);
+
+-- name: ConfirmTOTP :exec
+UPDATE user_totp
+SET confirmed_at = now()
+WHERE user_id = sqlc.arg(user_id) AND confirmed_at IS NULL;
+
+-- name: UseTOTPStep :one
+-- records the time step of an accepted code, no row when it or a later one was used already.
+UPDATE user_totp
+SET last_used_step = sqlc.arg(step)
+WHERE user_id = sqlc.arg(user_id) AND (last_used_step IS NULL OR last_used_step < sqlc.arg(step))
+RETURNING user_id;
+
+-- name: DeleteTOTP :execrows
+DELETE FROM user_totp
+WHERE user_id = sqlc.arg(user_id);
+
+-- name: CreateRecoveryCode :exec
+INSERT INTO user_recovery_codes (
+  user_id, code_hash
+) VALUES (
+  sqlc.arg(user_id), sqlc.arg(code_hash)
+);
+
+-- name: DeleteRecoveryCodes :exec
+DELETE FROM user_recovery_codes
+WHERE user_id = sqlc.arg(user_id);
+
+-- name: UseRecoveryCode :one
+-- no row when the code is unknown or used.
+UPDATE user_recovery_codes
+SET used_at = now()
+WHERE user_id = sqlc.arg(user_id) AND code_hash = sqlc.arg(code_hash) AND used_at IS NULL
+RETURNING id;
+
+-- name: CountUnusedRecoveryCodes :one
+SELECT count(*) FROM user_recovery_codes
+WHERE user_id = sqlc.arg(user_id) AND used_at IS NULL;
@@ -0,0 +1,29 @@
+-- name: CreateUserIdentity :one
+INSERT INTO user_identities (
+  user_id, provider, subject, email
+) VALUES (
+  sqlc.arg(user_id), sqlc.arg(provider), sqlc.arg(subject), sqlc.arg(email)
+)
+RETURNING *;
+
+-- name: GetUserIdentity :one
+SELECT * FROM user_identities
+WHERE provider = sqlc.arg(provider) AND subject = sqlc.arg(subject);
+
+-- name: GetUserIdentitiesByUserId :many
+SELECT * FROM user_identities
+WHERE user_id = sqlc.arg(user_id)
+ORDER BY created_at;
+
+-- name: TouchUserIdentity :exec
+UPDATE user_identities
+SET email = sqlc.arg(email), last_login_at = now()
+WHERE id = sqlc.arg(id);
+
+-- name: DeleteUserIdentity :execrows
+DELETE FROM user_identities
+WHERE id = sqlc.arg(id) AND user_id = sqlc.arg(user_id);
+
+-- name: CountUserIdentities :one
+SELECT count(*) FROM user_identities
+WHERE user_id = sqlc.arg(user_id);
@@ -0,0 +1,20 @@
+-- name: CreateUserToken :one
+INSERT INTO user_tokens (
+  user_id, purpose, token_hash, email, expires_at
+) VALUES (
+  sqlc.arg(user_id), sqlc.arg(purpose), sqlc.arg(token_hash), sqlc.arg(email), sqlc.arg(expires_at)
+)
+RETURNING *;
+
+-- name: UseUserToken :one
+-- no row when the token is unknown, used or expired.
+UPDATE user_tokens
+SET used_at = now()
+WHERE token_hash = sqlc.arg(token_hash) AND purpose = sqlc.arg(purpose) AND used_at IS NULL AND expires_at > now()
+RETURNING *;
+
+-- name: ExpireUserTokens :exec
+-- voids the unused tokens of the user for purpose, once a newer one is sent or one is used.
+UPDATE user_tokens
+SET expires_at = now()
+WHERE user_id = sqlc.arg(user_id) AND purpose = sqlc.arg(purpose) AND used_at IS NULL AND expires_at > now();
@@ -19,4 +19,60 @@ where id = sqlc.arg(id);
 
 -- name: GetUsers :many
 SELECT *
-FROM users;
\ No newline at end of file
+FROM users;
+
+-- name: CreateBotUser :one
+INSERT INTO users (
+  username, email, hashed_password, is_bot, owner_id
+) VALUES (
+  sqlc.arg(username), sqlc.arg(email), sqlc.arg(hashed_password), true, sqlc.arg(owner_id)
+)
+RETURNING *;
+
+-- name: GetBotsByOwnerId :many
+SELECT *
+FROM users
+where owner_id = sqlc.arg(owner_id) AND is_bot
+ORDER BY id;
+
+-- name: GetUserByUsername :one
+SELECT *
+FROM users
+where lower(username) = lower(sqlc.arg(username));
+
+-- name: UpdateUserPassword :exec
+UPDATE users
+SET hashed_password = sqlc.arg(hashed_password)
+WHERE id = sqlc.arg(id);
+
+-- name: RehashUserPassword :exec
+-- leaves the password alone if it was changed since it was verified.
+UPDATE users
+SET hashed_password = sqlc.arg(hashed_password)
+WHERE id = sqlc.arg(id) AND hashed_password = sqlc.arg(old_hashed_password);
+
+-- name: VerifyUserEmail :one
+-- only verifies the address the token was sent to, in case it changed since.
+UPDATE users
+SET verified_at = COALESCE(verified_at, now())
+WHERE id = sqlc.arg(id) AND email = sqlc.arg(email)
+RETURNING *;
+
+-- name: UpdateUserProfile :one
+UPDATE users
+SET display_name = sqlc.narg(display_name), bio = sqlc.narg(bio), time_zone = sqlc.narg(time_zone), phone = sqlc.narg(phone)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: UpdateUserAvatar :one
+UPDATE users
+SET avatar_key = sqlc.narg(avatar_key)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: UpdateUserEmail :one
+-- the new address is verified by the link that confirmed the change.
+UPDATE users
+SET email = sqlc.arg(email), verified_at = now()
+WHERE id = sqlc.arg(id) AND email = sqlc.arg(old_email)
+RETURNING *;
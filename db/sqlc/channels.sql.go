@@ -7,6 +7,7 @@ package db
 
 import (
 	"context"
+	"time"
 )
 
 const createChannel = `-- name: CreateChannel :one
@@ -15,18 +16,27 @@ INSERT INTO channels (
 ) VALUES (
   $1
 )
-RETURNING id, name, created_at
+RETURNING id, name, created_at, message_ttl_seconds, retention_policy, retention_value, topic, slow_mode_seconds
 `
 
 func (q *Queries) CreateChannel(ctx context.Context, name string) (*Channel, error) {
 	row := q.db.QueryRow(ctx, createChannel, name)
 	var i Channel
-	err := row.Scan(&i.ID, &i.Name, &i.CreatedAt)
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.CreatedAt,
+		&i.MessageTtlSeconds,
+		&i.RetentionPolicy,
+		&i.RetentionValue,
+		&i.Topic,
+		&i.SlowModeSeconds,
+	)
 	return &i, err
 }
 
 const getChannelById = `-- name: GetChannelById :one
-SELECT id, name, created_at
+SELECT id, name, created_at, message_ttl_seconds, retention_policy, retention_value, topic, slow_mode_seconds
 FROM channels
 where id = $1
 `
@@ -34,12 +44,68 @@ where id = $1
 func (q *Queries) GetChannelById(ctx context.Context, id int64) (*Channel, error) {
 	row := q.db.QueryRow(ctx, getChannelById, id)
 	var i Channel
-	err := row.Scan(&i.ID, &i.Name, &i.CreatedAt)
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.CreatedAt,
+		&i.MessageTtlSeconds,
+		&i.RetentionPolicy,
+		&i.RetentionValue,
+		&i.Topic,
+		&i.SlowModeSeconds,
+	)
+	return &i, err
+}
+
+const getChannelByIdForUpdate = `-- name: GetChannelByIdForUpdate :one
+SELECT id, name, created_at, message_ttl_seconds, retention_policy, retention_value, topic, slow_mode_seconds
+FROM channels
+where id = $1
+FOR UPDATE
+`
+
+func (q *Queries) GetChannelByIdForUpdate(ctx context.Context, id int64) (*Channel, error) {
+	row := q.db.QueryRow(ctx, getChannelByIdForUpdate, id)
+	var i Channel
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.CreatedAt,
+		&i.MessageTtlSeconds,
+		&i.RetentionPolicy,
+		&i.RetentionValue,
+		&i.Topic,
+		&i.SlowModeSeconds,
+	)
+	return &i, err
+}
+
+const getChannelByName = `-- name: GetChannelByName :one
+SELECT id, name, created_at, message_ttl_seconds, retention_policy, retention_value, topic, slow_mode_seconds
+FROM channels
+where name = $1
+ORDER BY id
+LIMIT 1
+`
+
+func (q *Queries) GetChannelByName(ctx context.Context, name string) (*Channel, error) {
+	row := q.db.QueryRow(ctx, getChannelByName, name)
+	var i Channel
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.CreatedAt,
+		&i.MessageTtlSeconds,
+		&i.RetentionPolicy,
+		&i.RetentionValue,
+		&i.Topic,
+		&i.SlowModeSeconds,
+	)
 	return &i, err
 }
 
 const getChannels = `-- name: GetChannels :many
-SELECT id, name, created_at
+SELECT id, name, created_at, message_ttl_seconds, retention_policy, retention_value, topic, slow_mode_seconds
 FROM channels
 `
 
@@ -52,7 +118,16 @@ func (q *Queries) GetChannels(ctx context.Context) ([]*Channel, error) {
 	items := []*Channel{}
 	for rows.Next() {
 		var i Channel
-		if err := rows.Scan(&i.ID, &i.Name, &i.CreatedAt); err != nil {
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.CreatedAt,
+			&i.MessageTtlSeconds,
+			&i.RetentionPolicy,
+			&i.RetentionValue,
+			&i.Topic,
+			&i.SlowModeSeconds,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, &i)
@@ -62,3 +137,155 @@ func (q *Queries) GetChannels(ctx context.Context) ([]*Channel, error) {
 	}
 	return items, nil
 }
+
+const importChannel = `-- name: ImportChannel :one
+INSERT INTO channels (
+  name, created_at, message_ttl_seconds, retention_policy, retention_value
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+RETURNING id, name, created_at, message_ttl_seconds, retention_policy, retention_value, topic, slow_mode_seconds
+`
+
+type ImportChannelParams struct {
+	Name              string
+	CreatedAt         time.Time
+	MessageTtlSeconds *int32
+	RetentionPolicy   string
+	RetentionValue    *int32
+}
+
+func (q *Queries) ImportChannel(ctx context.Context, arg *ImportChannelParams) (*Channel, error) {
+	row := q.db.QueryRow(ctx, importChannel,
+		arg.Name,
+		arg.CreatedAt,
+		arg.MessageTtlSeconds,
+		arg.RetentionPolicy,
+		arg.RetentionValue,
+	)
+	var i Channel
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.CreatedAt,
+		&i.MessageTtlSeconds,
+		&i.RetentionPolicy,
+		&i.RetentionValue,
+		&i.Topic,
+		&i.SlowModeSeconds,
+	)
+	return &i, err
+}
+
+const updateChannelMessageTtl = `-- name: UpdateChannelMessageTtl :one
+UPDATE channels
+SET message_ttl_seconds = $1
+WHERE id = $2
+RETURNING id, name, created_at, message_ttl_seconds, retention_policy, retention_value, topic, slow_mode_seconds
+`
+
+type UpdateChannelMessageTtlParams struct {
+	MessageTtlSeconds *int32
+	ID                int64
+}
+
+func (q *Queries) UpdateChannelMessageTtl(ctx context.Context, arg *UpdateChannelMessageTtlParams) (*Channel, error) {
+	row := q.db.QueryRow(ctx, updateChannelMessageTtl, arg.MessageTtlSeconds, arg.ID)
+	var i Channel
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.CreatedAt,
+		&i.MessageTtlSeconds,
+		&i.RetentionPolicy,
+		&i.RetentionValue,
+		&i.Topic,
+		&i.SlowModeSeconds,
+	)
+	return &i, err
+}
+
+const updateChannelRetention = `-- name: UpdateChannelRetention :one
+UPDATE channels
+SET retention_policy = $1, retention_value = $2
+WHERE id = $3
+RETURNING id, name, created_at, message_ttl_seconds, retention_policy, retention_value, topic, slow_mode_seconds
+`
+
+type UpdateChannelRetentionParams struct {
+	RetentionPolicy string
+	RetentionValue  *int32
+	ID              int64
+}
+
+func (q *Queries) UpdateChannelRetention(ctx context.Context, arg *UpdateChannelRetentionParams) (*Channel, error) {
+	row := q.db.QueryRow(ctx, updateChannelRetention, arg.RetentionPolicy, arg.RetentionValue, arg.ID)
+	var i Channel
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.CreatedAt,
+		&i.MessageTtlSeconds,
+		&i.RetentionPolicy,
+		&i.RetentionValue,
+		&i.Topic,
+		&i.SlowModeSeconds,
+	)
+	return &i, err
+}
+
+const updateChannelSlowMode = `-- name: UpdateChannelSlowMode :one
+UPDATE channels
+SET slow_mode_seconds = $1
+WHERE id = $2
+RETURNING id, name, created_at, message_ttl_seconds, retention_policy, retention_value, topic, slow_mode_seconds
+`
+
+type UpdateChannelSlowModeParams struct {
+	SlowModeSeconds int32
+	ID              int64
+}
+
+func (q *Queries) UpdateChannelSlowMode(ctx context.Context, arg *UpdateChannelSlowModeParams) (*Channel, error) {
+	row := q.db.QueryRow(ctx, updateChannelSlowMode, arg.SlowModeSeconds, arg.ID)
+	var i Channel
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.CreatedAt,
+		&i.MessageTtlSeconds,
+		&i.RetentionPolicy,
+		&i.RetentionValue,
+		&i.Topic,
+		&i.SlowModeSeconds,
+	)
+	return &i, err
+}
+
+const updateChannelTopic = `-- name: UpdateChannelTopic :one
+UPDATE channels
+SET topic = $1
+WHERE id = $2
+RETURNING id, name, created_at, message_ttl_seconds, retention_policy, retention_value, topic, slow_mode_seconds
+`
+
+type UpdateChannelTopicParams struct {
+	Topic *string
+	ID    int64
+}
+
+func (q *Queries) UpdateChannelTopic(ctx context.Context, arg *UpdateChannelTopicParams) (*Channel, error) {
+	row := q.db.QueryRow(ctx, updateChannelTopic, arg.Topic, arg.ID)
+	var i Channel
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.CreatedAt,
+		&i.MessageTtlSeconds,
+		&i.RetentionPolicy,
+		&i.RetentionValue,
+		&i.Topic,
+		&i.SlowModeSeconds,
+	)
+	return &i, err
+}
@@ -7,36 +7,131 @@ package db
 
 import (
 	"context"
+	"time"
 )
 
 const createMembership = `-- name: CreateMembership :one
 INSERT INTO memberships (
-  user_id, channel_id
+  user_id, channel_id, role
 ) VALUES (
-  $1, $2 
+  $1, $2, $3
 )
-RETURNING id, user_id, channel_id, created_at
+RETURNING id, user_id, channel_id, created_at, role, muted_until
 `
 
 type CreateMembershipParams struct {
 	UserID    int64
 	ChannelID int64
+	Role      string
 }
 
 func (q *Queries) CreateMembership(ctx context.Context, arg *CreateMembershipParams) (*Membership, error) {
-	row := q.db.QueryRow(ctx, createMembership, arg.UserID, arg.ChannelID)
+	row := q.db.QueryRow(ctx, createMembership, arg.UserID, arg.ChannelID, arg.Role)
 	var i Membership
 	err := row.Scan(
 		&i.ID,
 		&i.UserID,
 		&i.ChannelID,
 		&i.CreatedAt,
+		&i.Role,
+		&i.MutedUntil,
+	)
+	return &i, err
+}
+
+const deleteMembership = `-- name: DeleteMembership :one
+DELETE FROM memberships
+WHERE user_id = $1 AND channel_id = $2
+RETURNING id, user_id, channel_id, created_at, role, muted_until
+`
+
+type DeleteMembershipParams struct {
+	UserID    int64
+	ChannelID int64
+}
+
+func (q *Queries) DeleteMembership(ctx context.Context, arg *DeleteMembershipParams) (*Membership, error) {
+	row := q.db.QueryRow(ctx, deleteMembership, arg.UserID, arg.ChannelID)
+	var i Membership
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.ChannelID,
+		&i.CreatedAt,
+		&i.Role,
+		&i.MutedUntil,
+	)
+	return &i, err
+}
+
+const getChannelMembersForExport = `-- name: GetChannelMembersForExport :many
+SELECT memberships.role, memberships.created_at, users.email, users.username
+FROM memberships
+JOIN users ON users.id = memberships.user_id
+where memberships.channel_id = $1
+ORDER BY memberships.id
+`
+
+type GetChannelMembersForExportRow struct {
+	Role      string
+	CreatedAt time.Time
+	Email     string
+	Username  string
+}
+
+func (q *Queries) GetChannelMembersForExport(ctx context.Context, channelID int64) ([]*GetChannelMembersForExportRow, error) {
+	rows, err := q.db.Query(ctx, getChannelMembersForExport, channelID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*GetChannelMembersForExportRow{}
+	for rows.Next() {
+		var i GetChannelMembersForExportRow
+		if err := rows.Scan(
+			&i.Role,
+			&i.CreatedAt,
+			&i.Email,
+			&i.Username,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getMembership = `-- name: GetMembership :one
+SELECT id, user_id, channel_id, created_at, role, muted_until
+FROM memberships
+where user_id = $1 AND channel_id = $2
+LIMIT 1
+`
+
+type GetMembershipParams struct {
+	UserID    int64
+	ChannelID int64
+}
+
+func (q *Queries) GetMembership(ctx context.Context, arg *GetMembershipParams) (*Membership, error) {
+	row := q.db.QueryRow(ctx, getMembership, arg.UserID, arg.ChannelID)
+	var i Membership
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.ChannelID,
+		&i.CreatedAt,
+		&i.Role,
+		&i.MutedUntil,
 	)
 	return &i, err
 }
 
 const getMemberships = `-- name: GetMemberships :many
-SELECT id, user_id, channel_id, created_at
+SELECT id, user_id, channel_id, created_at, role, muted_until
 FROM memberships
 `
 
@@ -54,6 +149,8 @@ func (q *Queries) GetMemberships(ctx context.Context) ([]*Membership, error) {
 			&i.UserID,
 			&i.ChannelID,
 			&i.CreatedAt,
+			&i.Role,
+			&i.MutedUntil,
 		); err != nil {
 			return nil, err
 		}
@@ -66,7 +163,7 @@ func (q *Queries) GetMemberships(ctx context.Context) ([]*Membership, error) {
 }
 
 const getMembershipsByChannelId = `-- name: GetMembershipsByChannelId :many
-SELECT id, user_id, channel_id, created_at
+SELECT id, user_id, channel_id, created_at, role, muted_until
 FROM memberships
 where channel_id = $1
 `
@@ -85,6 +182,8 @@ func (q *Queries) GetMembershipsByChannelId(ctx context.Context, channelID int64
 			&i.UserID,
 			&i.ChannelID,
 			&i.CreatedAt,
+			&i.Role,
+			&i.MutedUntil,
 		); err != nil {
 			return nil, err
 		}
@@ -97,7 +196,7 @@ func (q *Queries) GetMembershipsByChannelId(ctx context.Context, channelID int64
 }
 
 const getMembershipsByUserId = `-- name: GetMembershipsByUserId :many
-SELECT id, user_id, channel_id, created_at
+SELECT id, user_id, channel_id, created_at, role, muted_until
 FROM memberships
 where user_id = $1
 `
@@ -116,6 +215,8 @@ func (q *Queries) GetMembershipsByUserId(ctx context.Context, userID int64) ([]*
 			&i.UserID,
 			&i.ChannelID,
 			&i.CreatedAt,
+			&i.Role,
+			&i.MutedUntil,
 		); err != nil {
 			return nil, err
 		}
@@ -126,3 +227,92 @@ func (q *Queries) GetMembershipsByUserId(ctx context.Context, userID int64) ([]*
 	}
 	return items, nil
 }
+
+const importMembership = `-- name: ImportMembership :one
+INSERT INTO memberships (
+  user_id, channel_id, role, created_at
+) VALUES (
+  $1, $2, $3, $4
+)
+RETURNING id, user_id, channel_id, created_at, role, muted_until
+`
+
+type ImportMembershipParams struct {
+	UserID    int64
+	ChannelID int64
+	Role      string
+	CreatedAt time.Time
+}
+
+func (q *Queries) ImportMembership(ctx context.Context, arg *ImportMembershipParams) (*Membership, error) {
+	row := q.db.QueryRow(ctx, importMembership,
+		arg.UserID,
+		arg.ChannelID,
+		arg.Role,
+		arg.CreatedAt,
+	)
+	var i Membership
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.ChannelID,
+		&i.CreatedAt,
+		&i.Role,
+		&i.MutedUntil,
+	)
+	return &i, err
+}
+
+const updateMembershipMutedUntil = `-- name: UpdateMembershipMutedUntil :one
+UPDATE memberships
+SET muted_until = $1
+WHERE user_id = $2 AND channel_id = $3
+RETURNING id, user_id, channel_id, created_at, role, muted_until
+`
+
+type UpdateMembershipMutedUntilParams struct {
+	MutedUntil *time.Time
+	UserID     int64
+	ChannelID  int64
+}
+
+func (q *Queries) UpdateMembershipMutedUntil(ctx context.Context, arg *UpdateMembershipMutedUntilParams) (*Membership, error) {
+	row := q.db.QueryRow(ctx, updateMembershipMutedUntil, arg.MutedUntil, arg.UserID, arg.ChannelID)
+	var i Membership
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.ChannelID,
+		&i.CreatedAt,
+		&i.Role,
+		&i.MutedUntil,
+	)
+	return &i, err
+}
+
+const updateMembershipRole = `-- name: UpdateMembershipRole :one
+UPDATE memberships
+SET role = $1
+WHERE user_id = $2 AND channel_id = $3
+RETURNING id, user_id, channel_id, created_at, role, muted_until
+`
+
+type UpdateMembershipRoleParams struct {
+	Role      string
+	UserID    int64
+	ChannelID int64
+}
+
+func (q *Queries) UpdateMembershipRole(ctx context.Context, arg *UpdateMembershipRoleParams) (*Membership, error) {
+	row := q.db.QueryRow(ctx, updateMembershipRole, arg.Role, arg.UserID, arg.ChannelID)
+	var i Membership
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.ChannelID,
+		&i.CreatedAt,
+		&i.Role,
+		&i.MutedUntil,
+	)
+	return &i, err
+}
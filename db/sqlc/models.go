@@ -10,16 +10,194 @@ import (
 	"github.com/google/uuid"
 )
 
+type ApiKey struct {
+	ID         int64
+	UserID     int64
+	Name       string
+	Prefix     string
+	KeyHash    string
+	Scopes     []string
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+	CreatedAt  time.Time
+}
+
+type AuditLog struct {
+	ID           int64
+	ActorID      *int64
+	Action       string
+	TargetUserID *int64
+	ClientIp     *string
+	Details      []byte
+	CreatedAt    time.Time
+}
+
 type Channel struct {
+	ID                int64
+	Name              string
+	CreatedAt         time.Time
+	MessageTtlSeconds *int32
+	RetentionPolicy   string
+	RetentionValue    *int32
+	Topic             *string
+	SlowModeSeconds   int32
+}
+
+type HeldMessage struct {
+	ID               int64
+	ChannelID        int64
+	UserID           int64
+	Content          string
+	TtlSeconds       *int32
+	IsBot            bool
+	UsernameOverride *string
+	Attachments      []byte
+	WebhookID        *int64
+	Filter           string
+	Reason           string
+	Status           string
+	ReviewedBy       *int64
+	ReviewedAt       *time.Time
+	MessageID        *int64
+	CreatedAt        time.Time
+}
+
+type IncomingWebhook struct {
 	ID        int64
+	ChannelID int64
 	Name      string
+	TokenHash string
+	CreatedBy int64
+	RevokedAt *time.Time
 	CreatedAt time.Time
 }
 
+type MailOutbox struct {
+	ID            int64
+	Recipient     string
+	Subject       string
+	Body          string
+	Status        string
+	Attempts      int32
+	NextAttemptAt time.Time
+	LastError     *string
+	SentAt        *time.Time
+	CreatedAt     time.Time
+}
+
 type Membership struct {
+	ID         int64
+	UserID     int64
+	ChannelID  int64
+	CreatedAt  time.Time
+	Role       string
+	MutedUntil *time.Time
+}
+
+type Message struct {
+	ID               int64
+	ChannelID        int64
+	UserID           int64
+	Content          string
+	CreatedAt        time.Time
+	ExpiresAt        *time.Time
+	IsBot            bool
+	UsernameOverride *string
+	Attachments      []byte
+	WebhookID        *int64
+	Spans            []byte
+}
+
+type ModerationRule struct {
+	ID        int64
+	ChannelID int64
+	Kind      string
+	Pattern   string
+	Action    string
+	CreatedBy int64
+	CreatedAt time.Time
+}
+
+type OutgoingWebhook struct {
+	ID           int64
+	ChannelID    int64
+	Url          string
+	Secret       string
+	TriggerWords []string
+	CreatedBy    int64
+	RevokedAt    *time.Time
+	CreatedAt    time.Time
+}
+
+type Pin struct {
+	ID        int64
+	ChannelID int64
+	MessageID int64
+	PinnedBy  int64
+	CreatedAt time.Time
+}
+
+type Poll struct {
+	ID             int64
+	MessageID      int64
+	ChannelID      int64
+	CreatedBy      int64
+	Question       string
+	MultipleChoice bool
+	Anonymous      bool
+	ClosesAt       *time.Time
+	ClosedAt       *time.Time
+	CreatedAt      time.Time
+}
+
+type PollOption struct {
+	ID       int64
+	PollID   int64
+	Position int32
+	Text     string
+}
+
+type PollVote struct {
+	ID        int64
+	PollID    int64
+	OptionID  int64
+	UserID    int64
+	CreatedAt time.Time
+}
+
+type RefreshToken struct {
+	ID        uuid.UUID
+	SessionID uuid.UUID
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+type Reminder struct {
+	ID        int64
+	UserID    int64
+	MessageID int64
+	RemindAt  time.Time
+	Status    string
+	CreatedAt time.Time
+}
+
+type SavedItem struct {
 	ID        int64
 	UserID    int64
+	MessageID int64
+	CreatedAt time.Time
+}
+
+type ScheduledMessage struct {
+	ID        int64
 	ChannelID int64
+	UserID    int64
+	Content   string
+	SendAt    time.Time
+	Status    string
+	MessageID *int64
 	CreatedAt time.Time
 }
 
@@ -42,4 +220,73 @@ type User struct {
 	HashedPassword string
 	Phone          *string
 	CreatedAt      time.Time
+	IsAdmin        bool
+	IsBot          bool
+	OwnerID        *int64
+	VerifiedAt     *time.Time
+	DisplayName    *string
+	Bio            *string
+	TimeZone       *string
+	AvatarKey      *string
+}
+
+type UserIdentity struct {
+	ID          int64
+	UserID      int64
+	Provider    string
+	Subject     string
+	Email       string
+	CreatedAt   time.Time
+	LastLoginAt *time.Time
+}
+
+type UserRecoveryCode struct {
+	ID        int64
+	UserID    int64
+	CodeHash  string
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+type UserToken struct {
+	ID        int64
+	UserID    int64
+	Purpose   string
+	TokenHash string
+	Email     string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+type UserTotp struct {
+	UserID       int64
+	Secret       []byte
+	ConfirmedAt  *time.Time
+	LastUsedStep *int64
+	CreatedAt    time.Time
+}
+
+type WebhookDelivery struct {
+	ID         int64
+	WebhookID  int64
+	OutboxID   int64
+	Attempt    int32
+	StatusCode *int32
+	Error      *string
+	DurationMs int32
+	CreatedAt  time.Time
+}
+
+type WebhookOutbox struct {
+	ID            int64
+	WebhookID     int64
+	MessageID     int64
+	Payload       []byte
+	Status        string
+	Attempts      int32
+	NextAttemptAt time.Time
+	LastError     *string
+	DeliveredAt   *time.Time
+	CreatedAt     time.Time
 }
@@ -11,20 +11,143 @@ import (
 )
 
 type Querier interface {
+	ApproveHeldMessage(ctx context.Context, arg *ApproveHeldMessageParams) (*HeldMessage, error)
+	BlockSessionsByEmail(ctx context.Context, email string) ([]uuid.UUID, error)
+	CancelReminder(ctx context.Context, arg *CancelReminderParams) (*Reminder, error)
+	CancelScheduledMessage(ctx context.Context, arg *CancelScheduledMessageParams) (*ScheduledMessage, error)
+	ClaimDueMailOutboxEntries(ctx context.Context, arg *ClaimDueMailOutboxEntriesParams) ([]*MailOutbox, error)
+	ClaimDueReminders(ctx context.Context, batchSize int32) ([]*Reminder, error)
+	ClaimDueScheduledMessages(ctx context.Context, batchSize int32) ([]*ScheduledMessage, error)
+	ClaimDueWebhookOutboxEntries(ctx context.Context, arg *ClaimDueWebhookOutboxEntriesParams) ([]*WebhookOutbox, error)
+	CloseDuePolls(ctx context.Context, batchSize int32) ([]*Poll, error)
+	ClosePoll(ctx context.Context, id int64) (*Poll, error)
+	ConfirmTOTP(ctx context.Context, userID int64) error
+	CountChannelMessagesBefore(ctx context.Context, arg *CountChannelMessagesBeforeParams) (int64, error)
+	CountChannelMessagesUpTo(ctx context.Context, arg *CountChannelMessagesUpToParams) (int64, error)
+	CountPinsByChannelId(ctx context.Context, channelID int64) (int64, error)
+	CountPollVoters(ctx context.Context, pollID int64) (int64, error)
+	CountPollVotes(ctx context.Context, pollID int64) ([]*CountPollVotesRow, error)
+	CountUnusedRecoveryCodes(ctx context.Context, userID int64) (int64, error)
+	CountUserIdentities(ctx context.Context, userID int64) (int64, error)
+	CreateApiKey(ctx context.Context, arg *CreateApiKeyParams) (*ApiKey, error)
+	CreateAuditLog(ctx context.Context, arg *CreateAuditLogParams) error
+	CreateBotUser(ctx context.Context, arg *CreateBotUserParams) (*User, error)
 	CreateChannel(ctx context.Context, name string) (*Channel, error)
+	CreateHeldMessage(ctx context.Context, arg *CreateHeldMessageParams) (*HeldMessage, error)
+	CreateIncomingWebhook(ctx context.Context, arg *CreateIncomingWebhookParams) (*IncomingWebhook, error)
+	CreateMailOutboxEntry(ctx context.Context, arg *CreateMailOutboxEntryParams) error
 	CreateMembership(ctx context.Context, arg *CreateMembershipParams) (*Membership, error)
+	CreateMessage(ctx context.Context, arg *CreateMessageParams) (*Message, error)
+	CreateModerationRule(ctx context.Context, arg *CreateModerationRuleParams) (*ModerationRule, error)
+	CreateOutgoingWebhook(ctx context.Context, arg *CreateOutgoingWebhookParams) (*OutgoingWebhook, error)
+	CreatePin(ctx context.Context, arg *CreatePinParams) (*Pin, error)
+	CreatePoll(ctx context.Context, arg *CreatePollParams) (*Poll, error)
+	CreatePollOption(ctx context.Context, arg *CreatePollOptionParams) (*PollOption, error)
+	CreatePollVote(ctx context.Context, arg *CreatePollVoteParams) error
+	CreateRecoveryCode(ctx context.Context, arg *CreateRecoveryCodeParams) error
+	CreateRefreshToken(ctx context.Context, arg *CreateRefreshTokenParams) error
+	CreateReminder(ctx context.Context, arg *CreateReminderParams) (*Reminder, error)
+	CreateSavedItem(ctx context.Context, arg *CreateSavedItemParams) (*SavedItem, error)
+	CreateScheduledMessage(ctx context.Context, arg *CreateScheduledMessageParams) (*ScheduledMessage, error)
 	CreateSession(ctx context.Context, arg *CreateSessionParams) (*Session, error)
 	CreateUser(ctx context.Context, arg *CreateUserParams) (*User, error)
+	CreateUserIdentity(ctx context.Context, arg *CreateUserIdentityParams) (*UserIdentity, error)
+	CreateUserToken(ctx context.Context, arg *CreateUserTokenParams) (*UserToken, error)
+	CreateWebhookDelivery(ctx context.Context, arg *CreateWebhookDeliveryParams) error
+	CreateWebhookOutboxEntry(ctx context.Context, arg *CreateWebhookOutboxEntryParams) error
+	DeleteExpiredMessages(ctx context.Context, batchSize int32) ([]*DeleteExpiredMessagesRow, error)
+	DeleteMembership(ctx context.Context, arg *DeleteMembershipParams) (*Membership, error)
+	DeleteModerationRule(ctx context.Context, arg *DeleteModerationRuleParams) (*ModerationRule, error)
+	DeletePin(ctx context.Context, arg *DeletePinParams) (*Pin, error)
+	DeleteRecoveryCodes(ctx context.Context, userID int64) error
+	DeleteSavedItem(ctx context.Context, arg *DeleteSavedItemParams) (*SavedItem, error)
+	DeleteTOTP(ctx context.Context, userID int64) (int64, error)
+	DeleteUserIdentity(ctx context.Context, arg *DeleteUserIdentityParams) (int64, error)
+	DeleteUserPollVotes(ctx context.Context, arg *DeleteUserPollVotesParams) error
+	ExpireUserTokens(ctx context.Context, arg *ExpireUserTokensParams) error
+	FailMailOutboxEntry(ctx context.Context, arg *FailMailOutboxEntryParams) error
+	FailScheduledMessage(ctx context.Context, id int64) error
+	FailWebhookOutboxEntry(ctx context.Context, arg *FailWebhookOutboxEntryParams) error
+	GetActiveSessionsByEmail(ctx context.Context, email string) ([]*Session, error)
+	GetApiKeyByHash(ctx context.Context, keyHash string) (*ApiKey, error)
+	GetApiKeysByUserId(ctx context.Context, userID int64) ([]*ApiKey, error)
+	GetBotsByOwnerId(ctx context.Context, ownerID *int64) ([]*User, error)
 	GetChannelById(ctx context.Context, id int64) (*Channel, error)
+	GetChannelByIdForUpdate(ctx context.Context, id int64) (*Channel, error)
+	GetChannelByName(ctx context.Context, name string) (*Channel, error)
+	GetChannelMembersForExport(ctx context.Context, channelID int64) ([]*GetChannelMembersForExportRow, error)
+	GetChannelMessagesForExport(ctx context.Context, arg *GetChannelMessagesForExportParams) ([]*GetChannelMessagesForExportRow, error)
+	GetChannelPinsForExport(ctx context.Context, channelID int64) ([]*GetChannelPinsForExportRow, error)
 	GetChannels(ctx context.Context) ([]*Channel, error)
+	GetHeldMessageById(ctx context.Context, id int64) (*HeldMessage, error)
+	GetIncomingWebhookByTokenHash(ctx context.Context, tokenHash string) (*IncomingWebhook, error)
+	GetIncomingWebhooksByChannelId(ctx context.Context, channelID int64) ([]*IncomingWebhook, error)
+	GetMembership(ctx context.Context, arg *GetMembershipParams) (*Membership, error)
 	GetMemberships(ctx context.Context) ([]*Membership, error)
 	GetMembershipsByChannelId(ctx context.Context, channelID int64) ([]*Membership, error)
 	GetMembershipsByUserId(ctx context.Context, userID int64) ([]*Membership, error)
+	GetMessageById(ctx context.Context, id int64) (*Message, error)
+	GetModerationRulesByChannelId(ctx context.Context, channelID int64) ([]*ModerationRule, error)
+	GetOutgoingWebhookById(ctx context.Context, id int64) (*OutgoingWebhook, error)
+	GetOutgoingWebhooksByChannelId(ctx context.Context, channelID int64) ([]*OutgoingWebhook, error)
+	GetPendingHeldMessagesByChannelId(ctx context.Context, channelID int64) ([]*GetPendingHeldMessagesByChannelIdRow, error)
+	GetPendingRemindersByUserId(ctx context.Context, userID int64) ([]*Reminder, error)
+	GetPendingScheduledMessagesByUserId(ctx context.Context, userID int64) ([]*ScheduledMessage, error)
+	GetPinsByChannelId(ctx context.Context, channelID int64) ([]*GetPinsByChannelIdRow, error)
+	GetPollById(ctx context.Context, id int64) (*Poll, error)
+	GetPollByIdForUpdate(ctx context.Context, id int64) (*Poll, error)
+	GetPollOptions(ctx context.Context, pollID int64) ([]*PollOption, error)
+	GetPollVoters(ctx context.Context, pollID int64) ([]*GetPollVotersRow, error)
+	GetRefreshToken(ctx context.Context, id uuid.UUID) (*RefreshToken, error)
+	GetRetentionCutoffMessageId(ctx context.Context, arg *GetRetentionCutoffMessageIdParams) (int64, error)
+	GetSavedItemsByUserId(ctx context.Context, userID int64) ([]*GetSavedItemsByUserIdRow, error)
 	GetSession(ctx context.Context, id uuid.UUID) (*Session, error)
+	GetTOTPByUserId(ctx context.Context, userID int64) (*UserTotp, error)
 	GetUserByEmail(ctx context.Context, email string) (*User, error)
 	GetUserById(ctx context.Context, id int64) (*User, error)
+	GetUserByUsername(ctx context.Context, username string) (*User, error)
+	GetUserIdentitiesByUserId(ctx context.Context, userID int64) ([]*UserIdentity, error)
+	GetUserIdentity(ctx context.Context, arg *GetUserIdentityParams) (*UserIdentity, error)
+	GetUserPollVotes(ctx context.Context, arg *GetUserPollVotesParams) ([]int64, error)
 	GetUsers(ctx context.Context) ([]*User, error)
+	GetWebhookDeliveries(ctx context.Context, arg *GetWebhookDeliveriesParams) ([]*GetWebhookDeliveriesRow, error)
+	ImportChannel(ctx context.Context, arg *ImportChannelParams) (*Channel, error)
+	ImportMembership(ctx context.Context, arg *ImportMembershipParams) (*Membership, error)
+	ImportMessage(ctx context.Context, arg *ImportMessageParams) (*Message, error)
+	ImportPin(ctx context.Context, arg *ImportPinParams) (*Pin, error)
+	LogoutOtherSessions(ctx context.Context, arg *LogoutOtherSessionsParams) ([]uuid.UUID, error)
+	LogoutSessionsByEmail(ctx context.Context, email string) ([]uuid.UUID, error)
+	MarkMailOutboxEntrySent(ctx context.Context, id int64) error
+	MarkWebhookOutboxEntryDelivered(ctx context.Context, id int64) error
+	PurgeChannelMessagesBefore(ctx context.Context, arg *PurgeChannelMessagesBeforeParams) ([]int64, error)
+	PurgeChannelMessagesUpTo(ctx context.Context, arg *PurgeChannelMessagesUpToParams) ([]int64, error)
+	RehashUserPassword(ctx context.Context, arg *RehashUserPasswordParams) error
+	RejectHeldMessage(ctx context.Context, arg *RejectHeldMessageParams) (*HeldMessage, error)
+	RetryMailOutboxEntry(ctx context.Context, arg *RetryMailOutboxEntryParams) error
+	RetryWebhookOutboxEntry(ctx context.Context, arg *RetryWebhookOutboxEntryParams) error
+	RevokeApiKey(ctx context.Context, arg *RevokeApiKeyParams) (*ApiKey, error)
+	RevokeIncomingWebhook(ctx context.Context, arg *RevokeIncomingWebhookParams) (*IncomingWebhook, error)
+	RevokeOutgoingWebhook(ctx context.Context, arg *RevokeOutgoingWebhookParams) (*OutgoingWebhook, error)
+	SetScheduledMessageMessageId(ctx context.Context, arg *SetScheduledMessageMessageIdParams) error
+	TouchApiKey(ctx context.Context, id int64) error
+	TouchUserIdentity(ctx context.Context, arg *TouchUserIdentityParams) error
+	UpdateChannelMessageTtl(ctx context.Context, arg *UpdateChannelMessageTtlParams) (*Channel, error)
+	UpdateChannelRetention(ctx context.Context, arg *UpdateChannelRetentionParams) (*Channel, error)
+	UpdateChannelSlowMode(ctx context.Context, arg *UpdateChannelSlowModeParams) (*Channel, error)
+	UpdateChannelTopic(ctx context.Context, arg *UpdateChannelTopicParams) (*Channel, error)
+	UpdateMembershipMutedUntil(ctx context.Context, arg *UpdateMembershipMutedUntilParams) (*Membership, error)
+	UpdateMembershipRole(ctx context.Context, arg *UpdateMembershipRoleParams) (*Membership, error)
 	UpdateSession(ctx context.Context, arg *UpdateSessionParams) (*Session, error)
+	UpdateUserAvatar(ctx context.Context, arg *UpdateUserAvatarParams) (*User, error)
+	UpdateUserEmail(ctx context.Context, arg *UpdateUserEmailParams) (*User, error)
+	UpdateUserPassword(ctx context.Context, arg *UpdateUserPasswordParams) error
+	UpdateUserProfile(ctx context.Context, arg *UpdateUserProfileParams) (*User, error)
+	UpsertPendingTOTP(ctx context.Context, arg *UpsertPendingTOTPParams) (*UserTotp, error)
+	UseRecoveryCode(ctx context.Context, arg *UseRecoveryCodeParams) (int64, error)
+	UseRefreshToken(ctx context.Context, id uuid.UUID) (*RefreshToken, error)
+	UseTOTPStep(ctx context.Context, arg *UseTOTPStepParams) (int64, error)
+	UseUserToken(ctx context.Context, arg *UseUserTokenParams) (*UserToken, error)
+	VerifyUserEmail(ctx context.Context, arg *VerifyUserEmailParams) (*User, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -12,6 +12,33 @@ import (
 	"github.com/google/uuid"
 )
 
+const blockSessionsByEmail = `-- name: BlockSessionsByEmail :many
+UPDATE sessions
+SET is_blocked = true
+WHERE email = $1 AND expires_at > now() AND NOT is_blocked AND NOT is_logged_out
+RETURNING id
+`
+
+func (q *Queries) BlockSessionsByEmail(ctx context.Context, email string) ([]uuid.UUID, error) {
+	rows, err := q.db.Query(ctx, blockSessionsByEmail, email)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []uuid.UUID{}
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const createSession = `-- name: CreateSession :one
 INSERT INTO sessions (
     id,
@@ -58,6 +85,42 @@ func (q *Queries) CreateSession(ctx context.Context, arg *CreateSessionParams) (
 	return &i, err
 }
 
+const getActiveSessionsByEmail = `-- name: GetActiveSessionsByEmail :many
+SELECT id, email, user_agent, client_ip, refresh_token, expires_at, is_blocked, is_logged_out, created_at FROM sessions
+WHERE email = $1 AND expires_at > now() AND NOT is_blocked AND NOT is_logged_out
+ORDER BY created_at DESC
+`
+
+func (q *Queries) GetActiveSessionsByEmail(ctx context.Context, email string) ([]*Session, error) {
+	rows, err := q.db.Query(ctx, getActiveSessionsByEmail, email)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*Session{}
+	for rows.Next() {
+		var i Session
+		if err := rows.Scan(
+			&i.ID,
+			&i.Email,
+			&i.UserAgent,
+			&i.ClientIp,
+			&i.RefreshToken,
+			&i.ExpiresAt,
+			&i.IsBlocked,
+			&i.IsLoggedOut,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getSession = `-- name: GetSession :one
 SELECT id, email, user_agent, client_ip, refresh_token, expires_at, is_blocked, is_logged_out, created_at FROM sessions 
 WHERE id = $1
@@ -80,24 +143,90 @@ func (q *Queries) GetSession(ctx context.Context, id uuid.UUID) (*Session, error
 	return &i, err
 }
 
+const logoutOtherSessions = `-- name: LogoutOtherSessions :many
+UPDATE sessions
+SET is_logged_out = true
+WHERE email = $1 AND id <> $2 AND expires_at > now() AND NOT is_blocked AND NOT is_logged_out
+RETURNING id
+`
+
+type LogoutOtherSessionsParams struct {
+	Email string
+	ID    uuid.UUID
+}
+
+func (q *Queries) LogoutOtherSessions(ctx context.Context, arg *LogoutOtherSessionsParams) ([]uuid.UUID, error) {
+	rows, err := q.db.Query(ctx, logoutOtherSessions, arg.Email, arg.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []uuid.UUID{}
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const logoutSessionsByEmail = `-- name: LogoutSessionsByEmail :many
+UPDATE sessions
+SET is_logged_out = true
+WHERE email = $1 AND expires_at > now() AND NOT is_blocked AND NOT is_logged_out
+RETURNING id
+`
+
+func (q *Queries) LogoutSessionsByEmail(ctx context.Context, email string) ([]uuid.UUID, error) {
+	rows, err := q.db.Query(ctx, logoutSessionsByEmail, email)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []uuid.UUID{}
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const updateSession = `-- name: UpdateSession :one
 UPDATE sessions
 SET
     is_blocked = COALESCE($1, is_blocked),
-    is_logged_out = COALESCE($2,is_logged_out)
+    is_logged_out = COALESCE($2,is_logged_out),
+    refresh_token = COALESCE($3, refresh_token)
 WHERE
-    id = $3
+    id = $4
 RETURNING id, email, user_agent, client_ip, refresh_token, expires_at, is_blocked, is_logged_out, created_at
 `
 
 type UpdateSessionParams struct {
-	IsBlocked   *bool
-	IsLoggedOut *bool
-	ID          uuid.UUID
+	IsBlocked    *bool
+	IsLoggedOut  *bool
+	RefreshToken *string
+	ID           uuid.UUID
 }
 
 func (q *Queries) UpdateSession(ctx context.Context, arg *UpdateSessionParams) (*Session, error) {
-	row := q.db.QueryRow(ctx, updateSession, arg.IsBlocked, arg.IsLoggedOut, arg.ID)
+	row := q.db.QueryRow(ctx, updateSession,
+		arg.IsBlocked,
+		arg.IsLoggedOut,
+		arg.RefreshToken,
+		arg.ID,
+	)
 	var i Session
 	err := row.Scan(
 		&i.ID,
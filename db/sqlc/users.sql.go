@@ -9,13 +9,56 @@ import (
 	"context"
 )
 
+const createBotUser = `-- name: CreateBotUser :one
+INSERT INTO users (
+  username, email, hashed_password, is_bot, owner_id
+) VALUES (
+  $1, $2, $3, true, $4
+)
+RETURNING id, username, email, hashed_password, phone, created_at, is_admin, is_bot, owner_id, verified_at, display_name, bio, time_zone, avatar_key
+`
+
+type CreateBotUserParams struct {
+	Username       string
+	Email          string
+	HashedPassword string
+	OwnerID        *int64
+}
+
+func (q *Queries) CreateBotUser(ctx context.Context, arg *CreateBotUserParams) (*User, error) {
+	row := q.db.QueryRow(ctx, createBotUser,
+		arg.Username,
+		arg.Email,
+		arg.HashedPassword,
+		arg.OwnerID,
+	)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Email,
+		&i.HashedPassword,
+		&i.Phone,
+		&i.CreatedAt,
+		&i.IsAdmin,
+		&i.IsBot,
+		&i.OwnerID,
+		&i.VerifiedAt,
+		&i.DisplayName,
+		&i.Bio,
+		&i.TimeZone,
+		&i.AvatarKey,
+	)
+	return &i, err
+}
+
 const createUser = `-- name: CreateUser :one
 INSERT INTO users (
   username, email, hashed_password, phone
 ) VALUES (
   $1, $2, $3, $4
 )
-RETURNING id, username, email, hashed_password, phone, created_at
+RETURNING id, username, email, hashed_password, phone, created_at, is_admin, is_bot, owner_id, verified_at, display_name, bio, time_zone, avatar_key
 `
 
 type CreateUserParams struct {
@@ -40,12 +83,62 @@ func (q *Queries) CreateUser(ctx context.Context, arg *CreateUserParams) (*User,
 		&i.HashedPassword,
 		&i.Phone,
 		&i.CreatedAt,
+		&i.IsAdmin,
+		&i.IsBot,
+		&i.OwnerID,
+		&i.VerifiedAt,
+		&i.DisplayName,
+		&i.Bio,
+		&i.TimeZone,
+		&i.AvatarKey,
 	)
 	return &i, err
 }
 
+const getBotsByOwnerId = `-- name: GetBotsByOwnerId :many
+SELECT id, username, email, hashed_password, phone, created_at, is_admin, is_bot, owner_id, verified_at, display_name, bio, time_zone, avatar_key
+FROM users
+where owner_id = $1 AND is_bot
+ORDER BY id
+`
+
+func (q *Queries) GetBotsByOwnerId(ctx context.Context, ownerID *int64) ([]*User, error) {
+	rows, err := q.db.Query(ctx, getBotsByOwnerId, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*User{}
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.Email,
+			&i.HashedPassword,
+			&i.Phone,
+			&i.CreatedAt,
+			&i.IsAdmin,
+			&i.IsBot,
+			&i.OwnerID,
+			&i.VerifiedAt,
+			&i.DisplayName,
+			&i.Bio,
+			&i.TimeZone,
+			&i.AvatarKey,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, username, email, hashed_password, phone, created_at
+SELECT id, username, email, hashed_password, phone, created_at, is_admin, is_bot, owner_id, verified_at, display_name, bio, time_zone, avatar_key
 FROM users
 where email = $1
 `
@@ -60,12 +153,20 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (*User, erro
 		&i.HashedPassword,
 		&i.Phone,
 		&i.CreatedAt,
+		&i.IsAdmin,
+		&i.IsBot,
+		&i.OwnerID,
+		&i.VerifiedAt,
+		&i.DisplayName,
+		&i.Bio,
+		&i.TimeZone,
+		&i.AvatarKey,
 	)
 	return &i, err
 }
 
 const getUserById = `-- name: GetUserById :one
-SELECT id, username, email, hashed_password, phone, created_at
+SELECT id, username, email, hashed_password, phone, created_at, is_admin, is_bot, owner_id, verified_at, display_name, bio, time_zone, avatar_key
 FROM users
 where id = $1
 `
@@ -80,12 +181,48 @@ func (q *Queries) GetUserById(ctx context.Context, id int64) (*User, error) {
 		&i.HashedPassword,
 		&i.Phone,
 		&i.CreatedAt,
+		&i.IsAdmin,
+		&i.IsBot,
+		&i.OwnerID,
+		&i.VerifiedAt,
+		&i.DisplayName,
+		&i.Bio,
+		&i.TimeZone,
+		&i.AvatarKey,
+	)
+	return &i, err
+}
+
+const getUserByUsername = `-- name: GetUserByUsername :one
+SELECT id, username, email, hashed_password, phone, created_at, is_admin, is_bot, owner_id, verified_at, display_name, bio, time_zone, avatar_key
+FROM users
+where lower(username) = lower($1)
+`
+
+func (q *Queries) GetUserByUsername(ctx context.Context, username string) (*User, error) {
+	row := q.db.QueryRow(ctx, getUserByUsername, username)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Email,
+		&i.HashedPassword,
+		&i.Phone,
+		&i.CreatedAt,
+		&i.IsAdmin,
+		&i.IsBot,
+		&i.OwnerID,
+		&i.VerifiedAt,
+		&i.DisplayName,
+		&i.Bio,
+		&i.TimeZone,
+		&i.AvatarKey,
 	)
 	return &i, err
 }
 
 const getUsers = `-- name: GetUsers :many
-SELECT id, username, email, hashed_password, phone, created_at
+SELECT id, username, email, hashed_password, phone, created_at, is_admin, is_bot, owner_id, verified_at, display_name, bio, time_zone, avatar_key
 FROM users
 `
 
@@ -105,6 +242,14 @@ func (q *Queries) GetUsers(ctx context.Context) ([]*User, error) {
 			&i.HashedPassword,
 			&i.Phone,
 			&i.CreatedAt,
+			&i.IsAdmin,
+			&i.IsBot,
+			&i.OwnerID,
+			&i.VerifiedAt,
+			&i.DisplayName,
+			&i.Bio,
+			&i.TimeZone,
+			&i.AvatarKey,
 		); err != nil {
 			return nil, err
 		}
@@ -115,3 +260,185 @@ func (q *Queries) GetUsers(ctx context.Context) ([]*User, error) {
 	}
 	return items, nil
 }
+
+const rehashUserPassword = `-- name: RehashUserPassword :exec
+UPDATE users
+SET hashed_password = $1
+WHERE id = $2 AND hashed_password = $3
+`
+
+type RehashUserPasswordParams struct {
+	HashedPassword    string
+	ID                int64
+	OldHashedPassword string
+}
+
+// leaves the password alone if it was changed since it was verified.
+func (q *Queries) RehashUserPassword(ctx context.Context, arg *RehashUserPasswordParams) error {
+	_, err := q.db.Exec(ctx, rehashUserPassword, arg.HashedPassword, arg.ID, arg.OldHashedPassword)
+	return err
+}
+
+const updateUserAvatar = `-- name: UpdateUserAvatar :one
+UPDATE users
+SET avatar_key = $1
+WHERE id = $2
+RETURNING id, username, email, hashed_password, phone, created_at, is_admin, is_bot, owner_id, verified_at, display_name, bio, time_zone, avatar_key
+`
+
+type UpdateUserAvatarParams struct {
+	AvatarKey *string
+	ID        int64
+}
+
+func (q *Queries) UpdateUserAvatar(ctx context.Context, arg *UpdateUserAvatarParams) (*User, error) {
+	row := q.db.QueryRow(ctx, updateUserAvatar, arg.AvatarKey, arg.ID)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Email,
+		&i.HashedPassword,
+		&i.Phone,
+		&i.CreatedAt,
+		&i.IsAdmin,
+		&i.IsBot,
+		&i.OwnerID,
+		&i.VerifiedAt,
+		&i.DisplayName,
+		&i.Bio,
+		&i.TimeZone,
+		&i.AvatarKey,
+	)
+	return &i, err
+}
+
+const updateUserEmail = `-- name: UpdateUserEmail :one
+UPDATE users
+SET email = $1, verified_at = now()
+WHERE id = $2 AND email = $3
+RETURNING id, username, email, hashed_password, phone, created_at, is_admin, is_bot, owner_id, verified_at, display_name, bio, time_zone, avatar_key
+`
+
+type UpdateUserEmailParams struct {
+	Email    string
+	ID       int64
+	OldEmail string
+}
+
+// the new address is verified by the link that confirmed the change.
+func (q *Queries) UpdateUserEmail(ctx context.Context, arg *UpdateUserEmailParams) (*User, error) {
+	row := q.db.QueryRow(ctx, updateUserEmail, arg.Email, arg.ID, arg.OldEmail)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Email,
+		&i.HashedPassword,
+		&i.Phone,
+		&i.CreatedAt,
+		&i.IsAdmin,
+		&i.IsBot,
+		&i.OwnerID,
+		&i.VerifiedAt,
+		&i.DisplayName,
+		&i.Bio,
+		&i.TimeZone,
+		&i.AvatarKey,
+	)
+	return &i, err
+}
+
+const updateUserPassword = `-- name: UpdateUserPassword :exec
+UPDATE users
+SET hashed_password = $1
+WHERE id = $2
+`
+
+type UpdateUserPasswordParams struct {
+	HashedPassword string
+	ID             int64
+}
+
+func (q *Queries) UpdateUserPassword(ctx context.Context, arg *UpdateUserPasswordParams) error {
+	_, err := q.db.Exec(ctx, updateUserPassword, arg.HashedPassword, arg.ID)
+	return err
+}
+
+const updateUserProfile = `-- name: UpdateUserProfile :one
+UPDATE users
+SET display_name = $1, bio = $2, time_zone = $3, phone = $4
+WHERE id = $5
+RETURNING id, username, email, hashed_password, phone, created_at, is_admin, is_bot, owner_id, verified_at, display_name, bio, time_zone, avatar_key
+`
+
+type UpdateUserProfileParams struct {
+	DisplayName *string
+	Bio         *string
+	TimeZone    *string
+	Phone       *string
+	ID          int64
+}
+
+func (q *Queries) UpdateUserProfile(ctx context.Context, arg *UpdateUserProfileParams) (*User, error) {
+	row := q.db.QueryRow(ctx, updateUserProfile,
+		arg.DisplayName,
+		arg.Bio,
+		arg.TimeZone,
+		arg.Phone,
+		arg.ID,
+	)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Email,
+		&i.HashedPassword,
+		&i.Phone,
+		&i.CreatedAt,
+		&i.IsAdmin,
+		&i.IsBot,
+		&i.OwnerID,
+		&i.VerifiedAt,
+		&i.DisplayName,
+		&i.Bio,
+		&i.TimeZone,
+		&i.AvatarKey,
+	)
+	return &i, err
+}
+
+const verifyUserEmail = `-- name: VerifyUserEmail :one
+UPDATE users
+SET verified_at = COALESCE(verified_at, now())
+WHERE id = $1 AND email = $2
+RETURNING id, username, email, hashed_password, phone, created_at, is_admin, is_bot, owner_id, verified_at, display_name, bio, time_zone, avatar_key
+`
+
+type VerifyUserEmailParams struct {
+	ID    int64
+	Email string
+}
+
+// only verifies the address the token was sent to, in case it changed since.
+func (q *Queries) VerifyUserEmail(ctx context.Context, arg *VerifyUserEmailParams) (*User, error) {
+	row := q.db.QueryRow(ctx, verifyUserEmail, arg.ID, arg.Email)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Email,
+		&i.HashedPassword,
+		&i.Phone,
+		&i.CreatedAt,
+		&i.IsAdmin,
+		&i.IsBot,
+		&i.OwnerID,
+		&i.VerifiedAt,
+		&i.DisplayName,
+		&i.Bio,
+		&i.TimeZone,
+		&i.AvatarKey,
+	)
+	return &i, err
+}
@@ -0,0 +1,176 @@
+package delivery
+
+import (
+	"net/http"
+	"project/constants"
+	"project/models"
+	"project/models/request"
+	"project/service"
+	"project/utils"
+	"project/validator"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AccountHandler struct {
+	accountSvc service.AccountService
+}
+
+func NewAccountHandler(accountSvc service.AccountService) *AccountHandler {
+	return &AccountHandler{accountSvc}
+}
+
+func ConfigureAccountHandler(router *gin.RouterGroup, authMiddleware gin.HandlerFunc, authRateLimit gin.HandlerFunc, accountSvc service.AccountService) {
+	accountHandler := NewAccountHandler(accountSvc)
+	addAccountHandlerRoutes(router, authMiddleware, authRateLimit, accountHandler)
+}
+
+func addAccountHandlerRoutes(router *gin.RouterGroup, authMiddleware gin.HandlerFunc, authRateLimit gin.HandlerFunc, accountHandler *AccountHandler) {
+	router.POST("/password/forgot", authRateLimit, accountHandler.ForgotPassword)
+	router.POST("/password/reset", authRateLimit, accountHandler.ResetPassword)
+	router.POST("/email/verify", authRateLimit, accountHandler.VerifyEmail)
+	router.POST("/email/verify/resend", authRateLimit, accountHandler.ResendVerificationEmail)
+	router.PUT("/me/password", authMiddleware, authRateLimit, accountHandler.ChangePassword)
+	router.POST("/me/email", authMiddleware, authRateLimit, accountHandler.ChangeEmail)
+	router.POST("/email/change/confirm", authRateLimit, accountHandler.ConfirmEmailChange)
+}
+
+func (h *AccountHandler) ForgotPassword(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req request.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationErrs := validator.GetValidationError(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": validationErrs})
+		return
+	}
+
+	err := h.accountSvc.ForgotPassword(ctx, &req)
+	if err != nil {
+		statusCode := utils.GetHTTPStatusCode(err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, "if the email has an account, a reset link is on its way")
+}
+
+func (h *AccountHandler) ResetPassword(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req request.ResetPasswordRequest
+	req.ClientIp = c.ClientIP()
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationErrs := validator.GetValidationError(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": validationErrs})
+		return
+	}
+
+	err := h.accountSvc.ResetPassword(ctx, &req)
+	if err != nil {
+		statusCode := utils.GetHTTPStatusCode(err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, "password reset successfully")
+}
+
+func (h *AccountHandler) ChangePassword(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req request.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationErrs := validator.GetValidationError(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": validationErrs})
+		return
+	}
+	claims := c.MustGet(constants.JWTClaims).(*models.JWTClaims)
+	req.Email = claims.Email
+	req.CurrentSessionId = claims.SessionId
+	req.ClientIp = c.ClientIP()
+
+	revoked, err := h.accountSvc.ChangePassword(ctx, &req)
+	if err != nil {
+		statusCode := utils.GetHTTPStatusCode(err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, revoked)
+}
+
+func (h *AccountHandler) ChangeEmail(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req request.ChangeEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationErrs := validator.GetValidationError(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": validationErrs})
+		return
+	}
+	req.Email = c.MustGet(constants.JWTClaims).(*models.JWTClaims).Email
+
+	err := h.accountSvc.ChangeEmail(ctx, &req)
+	if err != nil {
+		statusCode := utils.GetHTTPStatusCode(err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, "confirmation link sent to the new email address")
+}
+
+func (h *AccountHandler) ConfirmEmailChange(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req request.ConfirmEmailChangeRequest
+	req.ClientIp = c.ClientIP()
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationErrs := validator.GetValidationError(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": validationErrs})
+		return
+	}
+
+	user, err := h.accountSvc.ConfirmEmailChange(ctx, &req)
+	if err != nil {
+		statusCode := utils.GetHTTPStatusCode(err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+func (h *AccountHandler) VerifyEmail(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req request.VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationErrs := validator.GetValidationError(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": validationErrs})
+		return
+	}
+
+	user, err := h.accountSvc.VerifyEmail(ctx, &req)
+	if err != nil {
+		statusCode := utils.GetHTTPStatusCode(err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+func (h *AccountHandler) ResendVerificationEmail(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req request.ResendVerificationEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationErrs := validator.GetValidationError(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": validationErrs})
+		return
+	}
+
+	err := h.accountSvc.ResendVerificationEmail(ctx, &req)
+	if err != nil {
+		statusCode := utils.GetHTTPStatusCode(err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, "if the email has an unverified account, a verification link is on its way")
+}
@@ -0,0 +1,83 @@
+package delivery
+
+import (
+	"fmt"
+	"net/http"
+	"project/archive"
+	"project/models/request"
+	"project/service"
+	"project/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ArchiveHandler struct {
+	archiveSvc service.ArchiveService
+}
+
+func NewArchiveHandler(archiveSvc service.ArchiveService) *ArchiveHandler {
+	return &ArchiveHandler{archiveSvc}
+}
+
+func ConfigureArchiveHandler(router *gin.RouterGroup, authMiddleware gin.HandlerFunc, adminMiddleware gin.HandlerFunc, archiveSvc service.ArchiveService) {
+	archiveHandler := NewArchiveHandler(archiveSvc)
+	addArchiveHandlerRoutes(router, authMiddleware, adminMiddleware, archiveHandler)
+}
+
+func addArchiveHandlerRoutes(router *gin.RouterGroup, authMiddleware gin.HandlerFunc, adminMiddleware gin.HandlerFunc, archiveHandler *ArchiveHandler) {
+	router.GET("/admin/channels/:channelId/export", authMiddleware, adminMiddleware, archiveHandler.ExportChannel)
+	router.POST("/admin/channels/import", authMiddleware, adminMiddleware, archiveHandler.ImportChannel)
+}
+
+func (h *ArchiveHandler) ExportChannel(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req request.ExportChannelRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Format == "" {
+		req.Format = archive.FormatJSON
+	}
+
+	contentType := "application/json"
+	if req.Format == archive.FormatJSONLines {
+		contentType = "application/x-ndjson"
+	}
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=channel-%d.%s", req.ChannelId, req.Format))
+
+	err := h.archiveSvc.ExportChannel(ctx, &req, c.Writer)
+	if err != nil {
+		// once streaming has started the status is already sent, the client
+		// sees a truncated archive instead
+		if !c.Writer.Written() {
+			c.Writer.Header().Del("Content-Disposition")
+			statusCode := utils.GetHTTPStatusCode(err)
+			c.JSON(statusCode, gin.H{"error": err.Error()})
+		}
+		return
+	}
+}
+
+func (h *ArchiveHandler) ImportChannel(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req request.ImportChannelRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.archiveSvc.ImportChannel(ctx, &req, c.Request.Body)
+	if err != nil {
+		statusCode := utils.GetHTTPStatusCode(err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
@@ -0,0 +1,128 @@
+package delivery
+
+import (
+	"net/http"
+	"project/constants"
+	"project/models"
+	"project/models/request"
+	"project/service"
+	"project/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type BotHandler struct {
+	botSvc service.BotService
+}
+
+func NewBotHandler(botSvc service.BotService) *BotHandler {
+	return &BotHandler{botSvc}
+}
+
+func ConfigureBotHandler(router *gin.RouterGroup, authMiddleware gin.HandlerFunc, botSvc service.BotService) {
+	botHandler := NewBotHandler(botSvc)
+	addBotHandlerRoutes(router, authMiddleware, botHandler)
+}
+
+func addBotHandlerRoutes(router *gin.RouterGroup, authMiddleware gin.HandlerFunc, botHandler *BotHandler) {
+	router.GET("/bots", authMiddleware, botHandler.GetBots)
+	router.POST("/bots", authMiddleware, botHandler.CreateBot)
+	router.GET("/bots/:botId/keys", authMiddleware, botHandler.GetApiKeys)
+	router.POST("/bots/:botId/keys", authMiddleware, botHandler.CreateApiKey)
+	router.DELETE("/bots/:botId/keys/:keyId", authMiddleware, botHandler.RevokeApiKey)
+}
+
+func (h *BotHandler) CreateBot(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req request.CreateBotRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Email = c.MustGet(constants.JWTClaims).(*models.JWTClaims).Email
+
+	bot, err := h.botSvc.CreateBot(ctx, &req)
+	if err != nil {
+		statusCode := utils.GetHTTPStatusCode(err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, bot)
+}
+
+func (h *BotHandler) GetBots(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req request.GetBotsRequest
+	req.Email = c.MustGet(constants.JWTClaims).(*models.JWTClaims).Email
+
+	bots, err := h.botSvc.GetBots(ctx, &req)
+	if err != nil {
+		statusCode := utils.GetHTTPStatusCode(err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, bots)
+}
+
+func (h *BotHandler) CreateApiKey(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req request.CreateApiKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Email = c.MustGet(constants.JWTClaims).(*models.JWTClaims).Email
+
+	apiKey, err := h.botSvc.CreateApiKey(ctx, &req)
+	if err != nil {
+		statusCode := utils.GetHTTPStatusCode(err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, apiKey)
+}
+
+func (h *BotHandler) GetApiKeys(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req request.GetApiKeysRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Email = c.MustGet(constants.JWTClaims).(*models.JWTClaims).Email
+
+	apiKeys, err := h.botSvc.GetApiKeys(ctx, &req)
+	if err != nil {
+		statusCode := utils.GetHTTPStatusCode(err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, apiKeys)
+}
+
+func (h *BotHandler) RevokeApiKey(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req request.RevokeApiKeyRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Email = c.MustGet(constants.JWTClaims).(*models.JWTClaims).Email
+
+	err := h.botSvc.RevokeApiKey(ctx, &req)
+	if err != nil {
+		statusCode := utils.GetHTTPStatusCode(err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, "api key revoked successfully")
+}
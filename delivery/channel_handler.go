@@ -0,0 +1,124 @@
+package delivery
+
+import (
+	"net/http"
+	"project/constants"
+	"project/models"
+	"project/models/request"
+	"project/service"
+	"project/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ChannelHandler struct {
+	channelSvc service.ChannelService
+}
+
+func NewChannelHandler(channelSvc service.ChannelService) *ChannelHandler {
+	return &ChannelHandler{channelSvc}
+}
+
+func ConfigureChannelHandler(router *gin.RouterGroup, authMiddleware gin.HandlerFunc, adminMiddleware gin.HandlerFunc, channelSvc service.ChannelService) {
+	channelHandler := NewChannelHandler(channelSvc)
+	addChannelHandlerRoutes(router, authMiddleware, adminMiddleware, channelHandler)
+}
+
+func addChannelHandlerRoutes(router *gin.RouterGroup, authMiddleware gin.HandlerFunc, adminMiddleware gin.HandlerFunc, channelHandler *ChannelHandler) {
+	router.PUT("/channels/:channelId/ttl", authMiddleware, channelHandler.UpdateMessageTtl)
+	router.PUT("/channels/:channelId/retention", authMiddleware, channelHandler.UpdateChannelRetention)
+	router.PUT("/channels/:channelId/slow-mode", authMiddleware, channelHandler.UpdateSlowMode)
+	router.PUT("/admin/channels/:channelId/members/:userId/role", authMiddleware, adminMiddleware, channelHandler.UpdateMemberRole)
+}
+
+func (h *ChannelHandler) UpdateMessageTtl(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req request.UpdateMessageTtlRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Email = c.MustGet(constants.JWTClaims).(*models.JWTClaims).Email
+
+	channel, err := h.channelSvc.UpdateMessageTtl(ctx, &req)
+	if err != nil {
+		statusCode := utils.GetHTTPStatusCode(err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, channel)
+}
+
+func (h *ChannelHandler) UpdateChannelRetention(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req request.UpdateChannelRetentionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Email = c.MustGet(constants.JWTClaims).(*models.JWTClaims).Email
+
+	channel, err := h.channelSvc.UpdateChannelRetention(ctx, &req)
+	if err != nil {
+		statusCode := utils.GetHTTPStatusCode(err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, channel)
+}
+
+func (h *ChannelHandler) UpdateSlowMode(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req request.UpdateSlowModeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Email = c.MustGet(constants.JWTClaims).(*models.JWTClaims).Email
+
+	channel, err := h.channelSvc.UpdateSlowMode(ctx, &req)
+	if err != nil {
+		statusCode := utils.GetHTTPStatusCode(err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, channel)
+}
+
+func (h *ChannelHandler) UpdateMemberRole(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req request.UpdateMemberRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Email = c.MustGet(constants.JWTClaims).(*models.JWTClaims).Email
+
+	membership, err := h.channelSvc.UpdateMemberRole(ctx, &req)
+	if err != nil {
+		statusCode := utils.GetHTTPStatusCode(err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, membership)
+}
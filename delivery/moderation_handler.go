@@ -0,0 +1,160 @@
+package delivery
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"project/constants"
+	"project/models"
+	"project/models/request"
+	"project/service"
+	"project/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ModerationHandler struct {
+	moderationSvc service.ModerationService
+}
+
+func NewModerationHandler(moderationSvc service.ModerationService) *ModerationHandler {
+	return &ModerationHandler{moderationSvc}
+}
+
+func ConfigureModerationHandler(router *gin.RouterGroup, authMiddleware gin.HandlerFunc, moderationSvc service.ModerationService) {
+	moderationHandler := NewModerationHandler(moderationSvc)
+	addModerationHandlerRoutes(router, authMiddleware, moderationHandler)
+}
+
+func addModerationHandlerRoutes(router *gin.RouterGroup, authMiddleware gin.HandlerFunc, moderationHandler *ModerationHandler) {
+	router.GET("/channels/:channelId/moderation/rules", authMiddleware, moderationHandler.GetModerationRules)
+	router.POST("/channels/:channelId/moderation/rules", authMiddleware, moderationHandler.CreateModerationRule)
+	router.DELETE("/channels/:channelId/moderation/rules/:ruleId", authMiddleware, moderationHandler.DeleteModerationRule)
+
+	router.GET("/channels/:channelId/moderation/queue", authMiddleware, moderationHandler.GetHeldMessages)
+	router.POST("/channels/:channelId/moderation/queue/:heldMessageId/approve", authMiddleware, moderationHandler.ApproveHeldMessage)
+	router.POST("/channels/:channelId/moderation/queue/:heldMessageId/reject", authMiddleware, moderationHandler.RejectHeldMessage)
+}
+
+func (h *ModerationHandler) CreateModerationRule(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req request.CreateModerationRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Email = c.MustGet(constants.JWTClaims).(*models.JWTClaims).Email
+
+	rule, err := h.moderationSvc.CreateModerationRule(ctx, &req)
+	if err != nil {
+		statusCode := utils.GetHTTPStatusCode(err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, rule)
+}
+
+func (h *ModerationHandler) GetModerationRules(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req request.GetModerationRulesRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Email = c.MustGet(constants.JWTClaims).(*models.JWTClaims).Email
+
+	rules, err := h.moderationSvc.GetModerationRules(ctx, &req)
+	if err != nil {
+		statusCode := utils.GetHTTPStatusCode(err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, rules)
+}
+
+func (h *ModerationHandler) DeleteModerationRule(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req request.DeleteModerationRuleRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Email = c.MustGet(constants.JWTClaims).(*models.JWTClaims).Email
+
+	err := h.moderationSvc.DeleteModerationRule(ctx, &req)
+	if err != nil {
+		statusCode := utils.GetHTTPStatusCode(err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, "moderation rule deleted successfully")
+}
+
+func (h *ModerationHandler) GetHeldMessages(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req request.GetHeldMessagesRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Email = c.MustGet(constants.JWTClaims).(*models.JWTClaims).Email
+
+	heldMessages, err := h.moderationSvc.GetHeldMessages(ctx, &req)
+	if err != nil {
+		statusCode := utils.GetHTTPStatusCode(err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, heldMessages)
+}
+
+func (h *ModerationHandler) ApproveHeldMessage(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req request.ApproveHeldMessageRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Email = c.MustGet(constants.JWTClaims).(*models.JWTClaims).Email
+
+	heldMessage, err := h.moderationSvc.ApproveHeldMessage(ctx, &req)
+	if err != nil {
+		statusCode := utils.GetHTTPStatusCode(err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, heldMessage)
+}
+
+func (h *ModerationHandler) RejectHeldMessage(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req request.RejectHeldMessageRequest
+	// the reason is optional, so is the body
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Email = c.MustGet(constants.JWTClaims).(*models.JWTClaims).Email
+
+	heldMessage, err := h.moderationSvc.RejectHeldMessage(ctx, &req)
+	if err != nil {
+		statusCode := utils.GetHTTPStatusCode(err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, heldMessage)
+}
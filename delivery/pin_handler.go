@@ -0,0 +1,149 @@
+package delivery
+
+import (
+	"net/http"
+	"project/constants"
+	"project/models"
+	"project/models/request"
+	"project/service"
+	"project/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PinHandler struct {
+	pinSvc service.PinService
+}
+
+func NewPinHandler(pinSvc service.PinService) *PinHandler {
+	return &PinHandler{pinSvc}
+}
+
+func ConfigurePinHandler(router *gin.RouterGroup, authMiddleware gin.HandlerFunc, pinSvc service.PinService) {
+	pinHandler := NewPinHandler(pinSvc)
+	addPinHandlerRoutes(router, authMiddleware, pinHandler)
+}
+
+func addPinHandlerRoutes(router *gin.RouterGroup, authMiddleware gin.HandlerFunc, pinHandler *PinHandler) {
+	router.GET("/channels/:channelId/pins", authMiddleware, pinHandler.GetPins)
+	router.POST("/channels/:channelId/pins", authMiddleware, pinHandler.PinMessage)
+	router.DELETE("/channels/:channelId/pins/:messageId", authMiddleware, pinHandler.UnpinMessage)
+	router.GET("/me/saved", authMiddleware, pinHandler.GetSavedItems)
+	router.POST("/me/saved", authMiddleware, pinHandler.SaveMessage)
+	router.DELETE("/me/saved/:messageId", authMiddleware, pinHandler.UnsaveMessage)
+}
+
+func (h *PinHandler) GetPins(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req request.GetPinsRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Email = c.MustGet(constants.JWTClaims).(*models.JWTClaims).Email
+
+	pins, err := h.pinSvc.GetPins(ctx, &req)
+	if err != nil {
+		statusCode := utils.GetHTTPStatusCode(err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, pins)
+}
+
+func (h *PinHandler) PinMessage(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req request.PinMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Email = c.MustGet(constants.JWTClaims).(*models.JWTClaims).Email
+
+	pin, err := h.pinSvc.PinMessage(ctx, &req)
+	if err != nil {
+		statusCode := utils.GetHTTPStatusCode(err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, pin)
+}
+
+func (h *PinHandler) UnpinMessage(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req request.UnpinMessageRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Email = c.MustGet(constants.JWTClaims).(*models.JWTClaims).Email
+
+	err := h.pinSvc.UnpinMessage(ctx, &req)
+	if err != nil {
+		statusCode := utils.GetHTTPStatusCode(err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, "message unpinned successfully")
+}
+
+func (h *PinHandler) GetSavedItems(c *gin.Context) {
+	ctx := c.Request.Context()
+	req := request.GetSavedItemsRequest{
+		Email: c.MustGet(constants.JWTClaims).(*models.JWTClaims).Email,
+	}
+
+	savedItems, err := h.pinSvc.GetSavedItems(ctx, &req)
+	if err != nil {
+		statusCode := utils.GetHTTPStatusCode(err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, savedItems)
+}
+
+func (h *PinHandler) SaveMessage(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req request.SaveMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Email = c.MustGet(constants.JWTClaims).(*models.JWTClaims).Email
+
+	savedItem, err := h.pinSvc.SaveMessage(ctx, &req)
+	if err != nil {
+		statusCode := utils.GetHTTPStatusCode(err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, savedItem)
+}
+
+func (h *PinHandler) UnsaveMessage(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req request.UnsaveMessageRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Email = c.MustGet(constants.JWTClaims).(*models.JWTClaims).Email
+
+	err := h.pinSvc.UnsaveMessage(ctx, &req)
+	if err != nil {
+		statusCode := utils.GetHTTPStatusCode(err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, "message removed from saved items")
+}
@@ -0,0 +1,136 @@
+package delivery
+
+import (
+	"net/http"
+	"project/constants"
+	"project/models"
+	"project/models/request"
+	"project/service"
+	"project/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PollHandler struct {
+	pollSvc service.PollService
+}
+
+func NewPollHandler(pollSvc service.PollService) *PollHandler {
+	return &PollHandler{pollSvc}
+}
+
+func ConfigurePollHandler(router *gin.RouterGroup, authMiddleware gin.HandlerFunc, pollSvc service.PollService) {
+	pollHandler := NewPollHandler(pollSvc)
+	addPollHandlerRoutes(router, authMiddleware, pollHandler)
+}
+
+func addPollHandlerRoutes(router *gin.RouterGroup, authMiddleware gin.HandlerFunc, pollHandler *PollHandler) {
+	router.POST("/channels/:channelId/polls", authMiddleware, pollHandler.CreatePoll)
+	router.GET("/polls/:pollId", authMiddleware, pollHandler.GetPoll)
+	router.PUT("/polls/:pollId/votes", authMiddleware, pollHandler.VotePoll)
+	router.DELETE("/polls/:pollId/votes", authMiddleware, pollHandler.RetractPollVote)
+	router.POST("/polls/:pollId/close", authMiddleware, pollHandler.ClosePoll)
+}
+
+func (h *PollHandler) CreatePoll(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req request.CreatePollRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Email = c.MustGet(constants.JWTClaims).(*models.JWTClaims).Email
+
+	poll, err := h.pollSvc.CreatePoll(ctx, &req)
+	if err != nil {
+		statusCode := utils.GetHTTPStatusCode(err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, poll)
+}
+
+func (h *PollHandler) GetPoll(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req request.GetPollRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Email = c.MustGet(constants.JWTClaims).(*models.JWTClaims).Email
+
+	poll, err := h.pollSvc.GetPoll(ctx, &req)
+	if err != nil {
+		statusCode := utils.GetHTTPStatusCode(err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, poll)
+}
+
+func (h *PollHandler) VotePoll(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req request.VotePollRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Email = c.MustGet(constants.JWTClaims).(*models.JWTClaims).Email
+
+	poll, err := h.pollSvc.VotePoll(ctx, &req)
+	if err != nil {
+		statusCode := utils.GetHTTPStatusCode(err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, poll)
+}
+
+func (h *PollHandler) RetractPollVote(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req request.RetractPollVoteRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Email = c.MustGet(constants.JWTClaims).(*models.JWTClaims).Email
+
+	poll, err := h.pollSvc.RetractPollVote(ctx, &req)
+	if err != nil {
+		statusCode := utils.GetHTTPStatusCode(err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, poll)
+}
+
+func (h *PollHandler) ClosePoll(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req request.ClosePollRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Email = c.MustGet(constants.JWTClaims).(*models.JWTClaims).Email
+
+	poll, err := h.pollSvc.ClosePoll(ctx, &req)
+	if err != nil {
+		statusCode := utils.GetHTTPStatusCode(err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, poll)
+}
@@ -0,0 +1,103 @@
+package delivery
+
+import (
+	"net/http"
+	"project/constants"
+	"project/models"
+	"project/models/request"
+	"project/service"
+	"project/utils"
+	"project/validator"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ProfileHandler struct {
+	profileSvc service.ProfileService
+}
+
+func NewProfileHandler(profileSvc service.ProfileService) *ProfileHandler {
+	return &ProfileHandler{profileSvc}
+}
+
+func ConfigureProfileHandler(router *gin.RouterGroup, authMiddleware gin.HandlerFunc, profileSvc service.ProfileService) {
+	profileHandler := NewProfileHandler(profileSvc)
+	addProfileHandlerRoutes(router, authMiddleware, profileHandler)
+}
+
+func addProfileHandlerRoutes(router *gin.RouterGroup, authMiddleware gin.HandlerFunc, profileHandler *ProfileHandler) {
+	router.PATCH("/me", authMiddleware, profileHandler.UpdateProfile)
+	router.PUT("/me/avatar", authMiddleware, profileHandler.UpdateAvatar)
+	router.DELETE("/me/avatar", authMiddleware, profileHandler.DeleteAvatar)
+	// avatar urls are unguessable, images can load them without a token
+	router.GET("/avatars/:file", profileHandler.GetAvatar)
+}
+
+func (h *ProfileHandler) UpdateProfile(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req request.UpdateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationErrs := validator.GetValidationError(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": validationErrs})
+		return
+	}
+	req.Email = c.MustGet(constants.JWTClaims).(*models.JWTClaims).Email
+
+	user, err := h.profileSvc.UpdateProfile(ctx, &req)
+	if err != nil {
+		statusCode := utils.GetHTTPStatusCode(err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// UpdateAvatar takes the image as the request body.
+func (h *ProfileHandler) UpdateAvatar(c *gin.Context) {
+	ctx := c.Request.Context()
+	req := request.UpdateAvatarRequest{Email: c.MustGet(constants.JWTClaims).(*models.JWTClaims).Email}
+
+	user, err := h.profileSvc.UpdateAvatar(ctx, &req, c.Request.Body)
+	if err != nil {
+		statusCode := utils.GetHTTPStatusCode(err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+func (h *ProfileHandler) DeleteAvatar(c *gin.Context) {
+	ctx := c.Request.Context()
+	req := request.DeleteAvatarRequest{Email: c.MustGet(constants.JWTClaims).(*models.JWTClaims).Email}
+
+	user, err := h.profileSvc.DeleteAvatar(ctx, &req)
+	if err != nil {
+		statusCode := utils.GetHTTPStatusCode(err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+func (h *ProfileHandler) GetAvatar(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req request.GetAvatarRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	path, err := h.profileSvc.GetAvatar(ctx, &req)
+	if err != nil {
+		statusCode := utils.GetHTTPStatusCode(err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	// a new upload gets a new url, this one never changes
+	c.Header("Cache-Control", "public, max-age=31536000, immutable")
+	c.File(path)
+}
@@ -0,0 +1,38 @@
+package delivery
+
+import (
+	"net/http"
+	"project/service"
+	"project/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RetentionHandler struct {
+	retentionSvc service.RetentionService
+}
+
+func NewRetentionHandler(retentionSvc service.RetentionService) *RetentionHandler {
+	return &RetentionHandler{retentionSvc}
+}
+
+func ConfigureRetentionHandler(router *gin.RouterGroup, authMiddleware gin.HandlerFunc, adminMiddleware gin.HandlerFunc, retentionSvc service.RetentionService) {
+	retentionHandler := NewRetentionHandler(retentionSvc)
+	addRetentionHandlerRoutes(router, authMiddleware, adminMiddleware, retentionHandler)
+}
+
+func addRetentionHandlerRoutes(router *gin.RouterGroup, authMiddleware gin.HandlerFunc, adminMiddleware gin.HandlerFunc, retentionHandler *RetentionHandler) {
+	router.GET("/admin/retention/report", authMiddleware, adminMiddleware, retentionHandler.GetRetentionReport)
+}
+
+func (h *RetentionHandler) GetRetentionReport(c *gin.Context) {
+	ctx := c.Request.Context()
+	report, err := h.retentionSvc.GetRetentionReport(ctx)
+	if err != nil {
+		statusCode := utils.GetHTTPStatusCode(err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
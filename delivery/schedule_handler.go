@@ -0,0 +1,146 @@
+package delivery
+
+import (
+	"net/http"
+	"project/constants"
+	"project/models"
+	"project/models/request"
+	"project/service"
+	"project/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ScheduleHandler struct {
+	scheduleSvc service.ScheduleService
+}
+
+func NewScheduleHandler(scheduleSvc service.ScheduleService) *ScheduleHandler {
+	return &ScheduleHandler{scheduleSvc}
+}
+
+func ConfigureScheduleHandler(router *gin.RouterGroup, authMiddleware gin.HandlerFunc, scheduleSvc service.ScheduleService) {
+	scheduleHandler := NewScheduleHandler(scheduleSvc)
+	addScheduleHandlerRoutes(router, authMiddleware, scheduleHandler)
+}
+
+func addScheduleHandlerRoutes(router *gin.RouterGroup, authMiddleware gin.HandlerFunc, scheduleHandler *ScheduleHandler) {
+	router.POST("/channels/:channelId/scheduled", authMiddleware, scheduleHandler.ScheduleMessage)
+	router.GET("/me/scheduled", authMiddleware, scheduleHandler.GetScheduledMessages)
+	router.DELETE("/me/scheduled/:scheduledMessageId", authMiddleware, scheduleHandler.CancelScheduledMessage)
+	router.POST("/me/reminders", authMiddleware, scheduleHandler.CreateReminder)
+	router.GET("/me/reminders", authMiddleware, scheduleHandler.GetReminders)
+	router.DELETE("/me/reminders/:reminderId", authMiddleware, scheduleHandler.CancelReminder)
+}
+
+func (h *ScheduleHandler) ScheduleMessage(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req request.ScheduleMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Email = c.MustGet(constants.JWTClaims).(*models.JWTClaims).Email
+
+	scheduledMessage, err := h.scheduleSvc.ScheduleMessage(ctx, &req)
+	if err != nil {
+		statusCode := utils.GetHTTPStatusCode(err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, scheduledMessage)
+}
+
+func (h *ScheduleHandler) GetScheduledMessages(c *gin.Context) {
+	ctx := c.Request.Context()
+	req := request.GetScheduledMessagesRequest{
+		Email: c.MustGet(constants.JWTClaims).(*models.JWTClaims).Email,
+	}
+
+	scheduledMessages, err := h.scheduleSvc.GetScheduledMessages(ctx, &req)
+	if err != nil {
+		statusCode := utils.GetHTTPStatusCode(err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, scheduledMessages)
+}
+
+func (h *ScheduleHandler) CancelScheduledMessage(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req request.CancelScheduledMessageRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Email = c.MustGet(constants.JWTClaims).(*models.JWTClaims).Email
+
+	err := h.scheduleSvc.CancelScheduledMessage(ctx, &req)
+	if err != nil {
+		statusCode := utils.GetHTTPStatusCode(err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, "scheduled message cancelled successfully")
+}
+
+func (h *ScheduleHandler) CreateReminder(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req request.CreateReminderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Email = c.MustGet(constants.JWTClaims).(*models.JWTClaims).Email
+
+	reminder, err := h.scheduleSvc.CreateReminder(ctx, &req)
+	if err != nil {
+		statusCode := utils.GetHTTPStatusCode(err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, reminder)
+}
+
+func (h *ScheduleHandler) GetReminders(c *gin.Context) {
+	ctx := c.Request.Context()
+	req := request.GetRemindersRequest{
+		Email: c.MustGet(constants.JWTClaims).(*models.JWTClaims).Email,
+	}
+
+	reminders, err := h.scheduleSvc.GetReminders(ctx, &req)
+	if err != nil {
+		statusCode := utils.GetHTTPStatusCode(err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, reminders)
+}
+
+func (h *ScheduleHandler) CancelReminder(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req request.CancelReminderRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Email = c.MustGet(constants.JWTClaims).(*models.JWTClaims).Email
+
+	err := h.scheduleSvc.CancelReminder(ctx, &req)
+	if err != nil {
+		statusCode := utils.GetHTTPStatusCode(err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, "reminder cancelled successfully")
+}
@@ -0,0 +1,124 @@
+package delivery
+
+import (
+	"net/http"
+	"project/constants"
+	"project/models"
+	"project/models/request"
+	"project/service"
+	"project/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SessionHandler struct {
+	sessionSvc service.SessionService
+}
+
+func NewSessionHandler(sessionSvc service.SessionService) *SessionHandler {
+	return &SessionHandler{sessionSvc}
+}
+
+func ConfigureSessionHandler(router *gin.RouterGroup, authMiddleware gin.HandlerFunc, adminMiddleware gin.HandlerFunc, sessionSvc service.SessionService) {
+	sessionHandler := NewSessionHandler(sessionSvc)
+	addSessionHandlerRoutes(router, authMiddleware, adminMiddleware, sessionHandler)
+}
+
+func addSessionHandlerRoutes(router *gin.RouterGroup, authMiddleware gin.HandlerFunc, adminMiddleware gin.HandlerFunc, sessionHandler *SessionHandler) {
+	router.GET("/me/sessions", authMiddleware, sessionHandler.GetSessions)
+	router.DELETE("/me/sessions/:sessionId", authMiddleware, sessionHandler.RevokeSession)
+	router.POST("/me/sessions/logout-others", authMiddleware, sessionHandler.RevokeOtherSessions)
+	router.DELETE("/admin/users/:userId/sessions", authMiddleware, adminMiddleware, sessionHandler.AdminRevokeSessions)
+	router.DELETE("/admin/users/:userId/sessions/:sessionId", authMiddleware, adminMiddleware, sessionHandler.AdminRevokeSession)
+}
+
+func (h *SessionHandler) GetSessions(c *gin.Context) {
+	ctx := c.Request.Context()
+	claims := c.MustGet(constants.JWTClaims).(*models.JWTClaims)
+	req := request.GetSessionsRequest{Email: claims.Email, CurrentSessionId: claims.SessionId}
+
+	sessions, err := h.sessionSvc.GetSessions(ctx, &req)
+	if err != nil {
+		statusCode := utils.GetHTTPStatusCode(err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, sessions)
+}
+
+func (h *SessionHandler) RevokeSession(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req request.RevokeSessionRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	claims := c.MustGet(constants.JWTClaims).(*models.JWTClaims)
+	req.Email = claims.Email
+	req.CurrentSessionId = claims.SessionId
+
+	err := h.sessionSvc.RevokeSession(ctx, &req)
+	if err != nil {
+		statusCode := utils.GetHTTPStatusCode(err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, "session revoked successfully")
+}
+
+func (h *SessionHandler) RevokeOtherSessions(c *gin.Context) {
+	ctx := c.Request.Context()
+	claims := c.MustGet(constants.JWTClaims).(*models.JWTClaims)
+	req := request.RevokeOtherSessionsRequest{Email: claims.Email, CurrentSessionId: claims.SessionId}
+
+	revoked, err := h.sessionSvc.RevokeOtherSessions(ctx, &req)
+	if err != nil {
+		statusCode := utils.GetHTTPStatusCode(err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, revoked)
+}
+
+func (h *SessionHandler) AdminRevokeSession(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req request.AdminRevokeSessionRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Email = c.MustGet(constants.JWTClaims).(*models.JWTClaims).Email
+	req.ClientIp = c.ClientIP()
+
+	err := h.sessionSvc.AdminRevokeSession(ctx, &req)
+	if err != nil {
+		statusCode := utils.GetHTTPStatusCode(err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, "session revoked successfully")
+}
+
+func (h *SessionHandler) AdminRevokeSessions(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req request.AdminRevokeSessionsRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Email = c.MustGet(constants.JWTClaims).(*models.JWTClaims).Email
+	req.ClientIp = c.ClientIP()
+
+	revoked, err := h.sessionSvc.AdminRevokeSessions(ctx, &req)
+	if err != nil {
+		statusCode := utils.GetHTTPStatusCode(err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, revoked)
+}
@@ -0,0 +1,176 @@
+package delivery
+
+import (
+	"net/http"
+	"project/constants"
+	"project/models"
+	"project/models/request"
+	"project/service"
+	"project/utils"
+	"project/validator"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SSOHandler struct {
+	ssoSvc service.SSOService
+}
+
+func NewSSOHandler(ssoSvc service.SSOService) *SSOHandler {
+	return &SSOHandler{ssoSvc}
+}
+
+func ConfigureSSOHandler(router *gin.RouterGroup, authMiddleware gin.HandlerFunc, authRateLimit gin.HandlerFunc, ssoSvc service.SSOService) {
+	ssoHandler := NewSSOHandler(ssoSvc)
+	addSSOHandlerRoutes(router, authMiddleware, authRateLimit, ssoHandler)
+}
+
+func addSSOHandlerRoutes(router *gin.RouterGroup, authMiddleware gin.HandlerFunc, authRateLimit gin.HandlerFunc, ssoHandler *SSOHandler) {
+	router.GET("/auth/oidc/providers", ssoHandler.GetProviders)
+	router.GET("/auth/oidc/:provider/authorize", authRateLimit, ssoHandler.StartSSOLogin)
+	router.POST("/auth/oidc/:provider/callback", authRateLimit, ssoHandler.CompleteSSOLogin)
+	router.GET("/me/identities", authMiddleware, ssoHandler.GetIdentities)
+	router.POST("/me/identities/:provider/authorize", authMiddleware, ssoHandler.StartIdentityLink)
+	router.POST("/me/identities/:provider/callback", authMiddleware, authRateLimit, ssoHandler.CompleteIdentityLink)
+	router.DELETE("/me/identities/:identityId", authMiddleware, ssoHandler.UnlinkIdentity)
+}
+
+func (h *SSOHandler) GetProviders(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	providers, err := h.ssoSvc.GetProviders(ctx)
+	if err != nil {
+		statusCode := utils.GetHTTPStatusCode(err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, providers)
+}
+
+func (h *SSOHandler) StartSSOLogin(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req request.StartSSOLoginRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	authorization, err := h.ssoSvc.StartSSOLogin(ctx, &req)
+	if err != nil {
+		statusCode := utils.GetHTTPStatusCode(err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, authorization)
+}
+
+func (h *SSOHandler) CompleteSSOLogin(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req request.CompleteSSOLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationErrs := validator.GetValidationError(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": validationErrs})
+		return
+	}
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.UserAgent = c.Request.UserAgent()
+	req.ClientIp = c.ClientIP()
+
+	loginUserResponse, err := h.ssoSvc.CompleteSSOLogin(ctx, &req)
+	if err != nil {
+		statusCode := utils.GetHTTPStatusCode(err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	// no tokens yet, the challenge goes to POST /login/2fa
+	if loginUserResponse.Challenge != nil {
+		c.JSON(http.StatusOK, loginUserResponse.Challenge)
+		return
+	}
+
+	c.JSON(http.StatusOK, loginUserResponse)
+}
+
+func (h *SSOHandler) GetIdentities(c *gin.Context) {
+	ctx := c.Request.Context()
+	req := request.GetIdentitiesRequest{Email: c.MustGet(constants.JWTClaims).(*models.JWTClaims).Email}
+
+	identities, err := h.ssoSvc.GetIdentities(ctx, &req)
+	if err != nil {
+		statusCode := utils.GetHTTPStatusCode(err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, identities)
+}
+
+func (h *SSOHandler) StartIdentityLink(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req request.StartIdentityLinkRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Email = c.MustGet(constants.JWTClaims).(*models.JWTClaims).Email
+
+	authorization, err := h.ssoSvc.StartIdentityLink(ctx, &req)
+	if err != nil {
+		statusCode := utils.GetHTTPStatusCode(err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, authorization)
+}
+
+func (h *SSOHandler) CompleteIdentityLink(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req request.CompleteIdentityLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationErrs := validator.GetValidationError(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": validationErrs})
+		return
+	}
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Email = c.MustGet(constants.JWTClaims).(*models.JWTClaims).Email
+	req.ClientIp = c.ClientIP()
+
+	identity, err := h.ssoSvc.CompleteIdentityLink(ctx, &req)
+	if err != nil {
+		statusCode := utils.GetHTTPStatusCode(err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, identity)
+}
+
+func (h *SSOHandler) UnlinkIdentity(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req request.UnlinkIdentityRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Email = c.MustGet(constants.JWTClaims).(*models.JWTClaims).Email
+	req.ClientIp = c.ClientIP()
+
+	err := h.ssoSvc.UnlinkIdentity(ctx, &req)
+	if err != nil {
+		statusCode := utils.GetHTTPStatusCode(err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, "identity unlinked successfully")
+}
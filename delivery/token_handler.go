@@ -2,6 +2,7 @@ package delivery
 
 import (
 	"net/http"
+	"project/constants"
 	"project/models/request"
 	"project/service"
 	"project/utils"
@@ -18,18 +19,21 @@ func NewTokenHandler(tokenSvc service.TokenService) *TokenHandler {
 	return &TokenHandler{tokenSvc}
 }
 
-func ConfigureTokenHandler(router *gin.RouterGroup, tokenSvc service.TokenService) {
+func ConfigureTokenHandler(router *gin.RouterGroup, authRateLimit gin.HandlerFunc, tokenSvc service.TokenService) {
 	TokenHandler := NewTokenHandler(tokenSvc)
-	addTokenHandlerRoutes(router, TokenHandler)
+	addTokenHandlerRoutes(router, authRateLimit, TokenHandler)
 }
 
-func addTokenHandlerRoutes(router *gin.RouterGroup, TokenHandler *TokenHandler) {
-	router.POST("/renew", TokenHandler.RenewAccessToken)
+func addTokenHandlerRoutes(router *gin.RouterGroup, authRateLimit gin.HandlerFunc, TokenHandler *TokenHandler) {
+	router.POST("/renew", authRateLimit, TokenHandler.RenewAccessToken)
+	router.GET("/.well-known/jwks.json", TokenHandler.GetJWKS)
 }
 
 func (h *TokenHandler) RenewAccessToken(c *gin.Context) {
 	ctx := c.Request.Context()
 	var renewAccessTokenRequest request.RenewAccessTokenRequest
+	renewAccessTokenRequest.UserAgent = c.Request.UserAgent()
+	renewAccessTokenRequest.ClientIp = c.ClientIP()
 	if err := c.ShouldBindJSON(&renewAccessTokenRequest); err != nil {
 		validationErrs := validator.GetValidationError(err)
 		c.JSON(http.StatusBadRequest, gin.H{"error": validationErrs})
@@ -45,3 +49,18 @@ func (h *TokenHandler) RenewAccessToken(c *gin.Context) {
 
 	c.JSON(http.StatusOK, renewAccessTokenResponse)
 }
+
+// GetJWKS publishes the public keys tokens are verified with, so other
+// services can verify them without sharing a secret.
+func (h *TokenHandler) GetJWKS(c *gin.Context) {
+	ctx := c.Request.Context()
+	jwks, err := h.tokenSvc.GetJWKS(ctx)
+	if err != nil {
+		statusCode := utils.GetHTTPStatusCode(err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header(constants.HeaderCacheControl, "public, max-age=300")
+	c.JSON(http.StatusOK, jwks)
+}
@@ -0,0 +1,146 @@
+package delivery
+
+import (
+	"net/http"
+	"project/constants"
+	"project/models"
+	"project/models/request"
+	"project/service"
+	"project/utils"
+	"project/validator"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TwoFactorHandler struct {
+	twoFactorSvc service.TwoFactorService
+}
+
+func NewTwoFactorHandler(twoFactorSvc service.TwoFactorService) *TwoFactorHandler {
+	return &TwoFactorHandler{twoFactorSvc}
+}
+
+func ConfigureTwoFactorHandler(router *gin.RouterGroup, authMiddleware gin.HandlerFunc, adminMiddleware gin.HandlerFunc, authRateLimit gin.HandlerFunc, twoFactorSvc service.TwoFactorService) {
+	twoFactorHandler := NewTwoFactorHandler(twoFactorSvc)
+	addTwoFactorHandlerRoutes(router, authMiddleware, adminMiddleware, authRateLimit, twoFactorHandler)
+}
+
+func addTwoFactorHandlerRoutes(router *gin.RouterGroup, authMiddleware gin.HandlerFunc, adminMiddleware gin.HandlerFunc, authRateLimit gin.HandlerFunc, twoFactorHandler *TwoFactorHandler) {
+	router.GET("/me/2fa", authMiddleware, twoFactorHandler.GetTwoFactorStatus)
+	router.POST("/me/2fa/totp", authMiddleware, twoFactorHandler.EnrollTOTP)
+	router.POST("/me/2fa/totp/confirm", authMiddleware, authRateLimit, twoFactorHandler.ConfirmTOTP)
+	router.DELETE("/me/2fa/totp", authMiddleware, authRateLimit, twoFactorHandler.DisableTOTP)
+	router.POST("/me/2fa/recovery-codes", authMiddleware, authRateLimit, twoFactorHandler.RegenerateRecoveryCodes)
+	router.DELETE("/admin/users/:userId/2fa", authMiddleware, adminMiddleware, twoFactorHandler.AdminResetTwoFactor)
+}
+
+func (h *TwoFactorHandler) GetTwoFactorStatus(c *gin.Context) {
+	ctx := c.Request.Context()
+	req := request.GetTwoFactorStatusRequest{Email: c.MustGet(constants.JWTClaims).(*models.JWTClaims).Email}
+
+	status, err := h.twoFactorSvc.GetTwoFactorStatus(ctx, &req)
+	if err != nil {
+		statusCode := utils.GetHTTPStatusCode(err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+func (h *TwoFactorHandler) EnrollTOTP(c *gin.Context) {
+	ctx := c.Request.Context()
+	req := request.EnrollTOTPRequest{Email: c.MustGet(constants.JWTClaims).(*models.JWTClaims).Email}
+
+	enrollment, err := h.twoFactorSvc.EnrollTOTP(ctx, &req)
+	if err != nil {
+		statusCode := utils.GetHTTPStatusCode(err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, enrollment)
+}
+
+func (h *TwoFactorHandler) ConfirmTOTP(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req request.ConfirmTOTPRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationErrs := validator.GetValidationError(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": validationErrs})
+		return
+	}
+	req.Email = c.MustGet(constants.JWTClaims).(*models.JWTClaims).Email
+	req.ClientIp = c.ClientIP()
+
+	recoveryCodes, err := h.twoFactorSvc.ConfirmTOTP(ctx, &req)
+	if err != nil {
+		statusCode := utils.GetHTTPStatusCode(err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, recoveryCodes)
+}
+
+func (h *TwoFactorHandler) DisableTOTP(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req request.DisableTOTPRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationErrs := validator.GetValidationError(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": validationErrs})
+		return
+	}
+	req.Email = c.MustGet(constants.JWTClaims).(*models.JWTClaims).Email
+	req.ClientIp = c.ClientIP()
+
+	err := h.twoFactorSvc.DisableTOTP(ctx, &req)
+	if err != nil {
+		statusCode := utils.GetHTTPStatusCode(err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, "two-factor authentication disabled successfully")
+}
+
+func (h *TwoFactorHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req request.RegenerateRecoveryCodesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationErrs := validator.GetValidationError(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": validationErrs})
+		return
+	}
+	req.Email = c.MustGet(constants.JWTClaims).(*models.JWTClaims).Email
+	req.ClientIp = c.ClientIP()
+
+	recoveryCodes, err := h.twoFactorSvc.RegenerateRecoveryCodes(ctx, &req)
+	if err != nil {
+		statusCode := utils.GetHTTPStatusCode(err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, recoveryCodes)
+}
+
+func (h *TwoFactorHandler) AdminResetTwoFactor(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req request.AdminResetTwoFactorRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Email = c.MustGet(constants.JWTClaims).(*models.JWTClaims).Email
+	req.ClientIp = c.ClientIP()
+
+	err := h.twoFactorSvc.AdminResetTwoFactor(ctx, &req)
+	if err != nil {
+		statusCode := utils.GetHTTPStatusCode(err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, "two-factor authentication reset successfully")
+}
@@ -8,6 +8,7 @@ import (
 	"project/service"
 	"project/utils"
 	"project/validator"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
@@ -20,18 +21,21 @@ func NewUserHandler(userSvc service.UserService) *UserHandler {
 	return &UserHandler{userSvc}
 }
 
-func ConfigureUserHandler(router *gin.RouterGroup, authMiddleware gin.HandlerFunc, userSvc service.UserService) {
+func ConfigureUserHandler(router *gin.RouterGroup, authMiddleware gin.HandlerFunc, adminMiddleware gin.HandlerFunc, authRateLimit gin.HandlerFunc, userSvc service.UserService) {
 	userHandler := NewUserHandler(userSvc)
-	addUserHandlerRoutes(router, authMiddleware, userHandler)
+	addUserHandlerRoutes(router, authMiddleware, adminMiddleware, authRateLimit, userHandler)
 }
 
-func addUserHandlerRoutes(router *gin.RouterGroup, authMiddleware gin.HandlerFunc, userHandler *UserHandler) {
-	router.POST("/signup", userHandler.CreateUser)
-	router.POST("/login", userHandler.LoginUser)
+func addUserHandlerRoutes(router *gin.RouterGroup, authMiddleware gin.HandlerFunc, adminMiddleware gin.HandlerFunc, authRateLimit gin.HandlerFunc, userHandler *UserHandler) {
+	router.POST("/signup", authRateLimit, userHandler.CreateUser)
+	router.POST("/login", authRateLimit, userHandler.LoginUser)
+	router.POST("/login/2fa", authRateLimit, userHandler.LoginSecondFactor)
+	router.POST("/login/2fa/enroll", authRateLimit, userHandler.EnrollLoginSecondFactor)
 	router.POST("/logout", userHandler.LogoutUser)
 	router.GET("/users/:email", authMiddleware, userHandler.GetUserByEmail)
 	router.GET("/users", userHandler.GetUsers)
-	router.POST("/users", userHandler.CreateUser)
+	router.POST("/users", authRateLimit, userHandler.CreateUser)
+	router.POST("/admin/users/:userId/unlock", authMiddleware, adminMiddleware, userHandler.UnlockUser)
 }
 
 func (h *UserHandler) CreateUser(c *gin.Context) {
@@ -66,14 +70,66 @@ func (h *UserHandler) LoginUser(c *gin.Context) {
 
 	loginUserResponse, err := h.userSvc.LoginUser(ctx, &loginUserRequest)
 	if err != nil {
+		if wait, ok := utils.RetryAfter(err); ok {
+			c.Header(constants.HeaderRetryAfter, strconv.Itoa(utils.RetryAfterSeconds(wait)))
+		}
 		statusCode := utils.GetHTTPStatusCode(err)
 		c.JSON(statusCode, gin.H{"error": err.Error()})
 		return
 	}
 
+	// no tokens yet, the challenge goes to POST /login/2fa
+	if loginUserResponse.Challenge != nil {
+		c.JSON(http.StatusOK, loginUserResponse.Challenge)
+		return
+	}
+
 	c.JSON(http.StatusOK, loginUserResponse)
 }
 
+func (h *UserHandler) LoginSecondFactor(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req request.LoginSecondFactorRequest
+	req.UserAgent = c.Request.UserAgent()
+	req.ClientIp = c.ClientIP()
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationErrs := validator.GetValidationError(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": validationErrs})
+		return
+	}
+
+	loginUserResponse, err := h.userSvc.LoginSecondFactor(ctx, &req)
+	if err != nil {
+		if wait, ok := utils.RetryAfter(err); ok {
+			c.Header(constants.HeaderRetryAfter, strconv.Itoa(utils.RetryAfterSeconds(wait)))
+		}
+		statusCode := utils.GetHTTPStatusCode(err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, loginUserResponse)
+}
+
+func (h *UserHandler) EnrollLoginSecondFactor(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req request.EnrollLoginSecondFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationErrs := validator.GetValidationError(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": validationErrs})
+		return
+	}
+
+	enrollment, err := h.userSvc.EnrollLoginSecondFactor(ctx, &req)
+	if err != nil {
+		statusCode := utils.GetHTTPStatusCode(err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, enrollment)
+}
+
 func (h *UserHandler) LogoutUser(c *gin.Context) {
 	ctx := c.Request.Context()
 	var logoutUserRequest request.LogoutUserRequest
@@ -128,3 +184,22 @@ func (h *UserHandler) GetUsers(c *gin.Context) {
 
 	c.JSON(http.StatusOK, users)
 }
+
+func (h *UserHandler) UnlockUser(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req request.UnlockUserRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Email = c.MustGet(constants.JWTClaims).(*models.JWTClaims).Email
+
+	err := h.userSvc.UnlockUser(ctx, &req)
+	if err != nil {
+		statusCode := utils.GetHTTPStatusCode(err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, "user unlocked successfully")
+}
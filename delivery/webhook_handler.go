@@ -0,0 +1,206 @@
+package delivery
+
+import (
+	"net/http"
+	"project/constants"
+	"project/models"
+	"project/models/request"
+	"project/service"
+	"project/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WebhookHandler struct {
+	webhookSvc service.WebhookService
+}
+
+func NewWebhookHandler(webhookSvc service.WebhookService) *WebhookHandler {
+	return &WebhookHandler{webhookSvc}
+}
+
+func ConfigureWebhookHandler(router *gin.RouterGroup, authMiddleware gin.HandlerFunc, webhookSvc service.WebhookService) {
+	webhookHandler := NewWebhookHandler(webhookSvc)
+	addWebhookHandlerRoutes(router, authMiddleware, webhookHandler)
+}
+
+func addWebhookHandlerRoutes(router *gin.RouterGroup, authMiddleware gin.HandlerFunc, webhookHandler *WebhookHandler) {
+	router.GET("/channels/:channelId/webhooks", authMiddleware, webhookHandler.GetIncomingWebhooks)
+	router.POST("/channels/:channelId/webhooks", authMiddleware, webhookHandler.CreateIncomingWebhook)
+	router.DELETE("/channels/:channelId/webhooks/:webhookId", authMiddleware, webhookHandler.RevokeIncomingWebhook)
+
+	router.GET("/channels/:channelId/outgoing-webhooks", authMiddleware, webhookHandler.GetOutgoingWebhooks)
+	router.POST("/channels/:channelId/outgoing-webhooks", authMiddleware, webhookHandler.CreateOutgoingWebhook)
+	router.DELETE("/channels/:channelId/outgoing-webhooks/:webhookId", authMiddleware, webhookHandler.RevokeOutgoingWebhook)
+	router.GET("/channels/:channelId/outgoing-webhooks/:webhookId/deliveries", authMiddleware, webhookHandler.GetWebhookDeliveries)
+
+	// authenticated by the secret token in the url
+	router.POST("/hooks/:token", webhookHandler.PostIncomingWebhook)
+}
+
+func (h *WebhookHandler) CreateIncomingWebhook(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req request.CreateIncomingWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Email = c.MustGet(constants.JWTClaims).(*models.JWTClaims).Email
+
+	webhook, err := h.webhookSvc.CreateIncomingWebhook(ctx, &req)
+	if err != nil {
+		statusCode := utils.GetHTTPStatusCode(err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, webhook)
+}
+
+func (h *WebhookHandler) GetIncomingWebhooks(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req request.GetIncomingWebhooksRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Email = c.MustGet(constants.JWTClaims).(*models.JWTClaims).Email
+
+	webhooks, err := h.webhookSvc.GetIncomingWebhooks(ctx, &req)
+	if err != nil {
+		statusCode := utils.GetHTTPStatusCode(err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, webhooks)
+}
+
+func (h *WebhookHandler) RevokeIncomingWebhook(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req request.RevokeIncomingWebhookRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Email = c.MustGet(constants.JWTClaims).(*models.JWTClaims).Email
+
+	err := h.webhookSvc.RevokeIncomingWebhook(ctx, &req)
+	if err != nil {
+		statusCode := utils.GetHTTPStatusCode(err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, "webhook revoked successfully")
+}
+
+func (h *WebhookHandler) PostIncomingWebhook(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req request.PostIncomingWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	message, err := h.webhookSvc.PostIncomingWebhook(ctx, &req)
+	if err != nil {
+		statusCode := utils.GetHTTPStatusCode(err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, message)
+}
+
+func (h *WebhookHandler) CreateOutgoingWebhook(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req request.CreateOutgoingWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Email = c.MustGet(constants.JWTClaims).(*models.JWTClaims).Email
+
+	webhook, err := h.webhookSvc.CreateOutgoingWebhook(ctx, &req)
+	if err != nil {
+		statusCode := utils.GetHTTPStatusCode(err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, webhook)
+}
+
+func (h *WebhookHandler) GetOutgoingWebhooks(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req request.GetOutgoingWebhooksRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Email = c.MustGet(constants.JWTClaims).(*models.JWTClaims).Email
+
+	webhooks, err := h.webhookSvc.GetOutgoingWebhooks(ctx, &req)
+	if err != nil {
+		statusCode := utils.GetHTTPStatusCode(err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, webhooks)
+}
+
+func (h *WebhookHandler) RevokeOutgoingWebhook(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req request.RevokeOutgoingWebhookRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Email = c.MustGet(constants.JWTClaims).(*models.JWTClaims).Email
+
+	err := h.webhookSvc.RevokeOutgoingWebhook(ctx, &req)
+	if err != nil {
+		statusCode := utils.GetHTTPStatusCode(err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, "webhook revoked successfully")
+}
+
+func (h *WebhookHandler) GetWebhookDeliveries(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req request.GetWebhookDeliveriesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Email = c.MustGet(constants.JWTClaims).(*models.JWTClaims).Email
+
+	deliveries, err := h.webhookSvc.GetWebhookDeliveries(ctx, &req)
+	if err != nil {
+		statusCode := utils.GetHTTPStatusCode(err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, deliveries)
+}
@@ -3,11 +3,13 @@ package delivery
 import (
 	"net/http"
 	"project/chat"
+	"project/constants"
 	db "project/db/sqlc"
+	"project/models"
 	"project/models/request"
+	"project/models/response"
 	"project/service"
 	"project/utils"
-	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
@@ -37,17 +39,18 @@ func NewWSHandler(hub *chat.Hub, userSvc service.UserService, repo db.Repository
 	}
 }
 
-func ConfigureWSHandler(router *gin.RouterGroup, hub *chat.Hub, userSvc service.UserService, repo db.Repository) {
+func ConfigureWSHandler(router *gin.RouterGroup, authMiddleware gin.HandlerFunc, wsAuthMiddleware gin.HandlerFunc, hub *chat.Hub, userSvc service.UserService, repo db.Repository) {
 	wsHandler := NewWSHandler(hub, userSvc, repo)
-	addWSHandlerRoutes(router, wsHandler)
+	addWSHandlerRoutes(router, authMiddleware, wsAuthMiddleware, wsHandler)
 }
 
-func addWSHandlerRoutes(router *gin.RouterGroup, wsHandler *WSHandler) {
+func addWSHandlerRoutes(router *gin.RouterGroup, authMiddleware gin.HandlerFunc, wsAuthMiddleware gin.HandlerFunc, wsHandler *WSHandler) {
 	router.GET("/channels", wsHandler.GetChannels)
 	router.GET("/memberships", wsHandler.GetMemberships)
-	router.POST("/channels", wsHandler.CreateChannel)
-	router.GET("/ws/join", wsHandler.JoinChat)
-	router.GET("/channels/join/:channelId", wsHandler.JoinChannel)
+	router.GET("/channels/:channelId", wsHandler.GetChannel)
+	router.POST("/channels", authMiddleware, wsHandler.CreateChannel)
+	router.GET("/ws/join", wsAuthMiddleware, wsHandler.JoinChat)
+	router.GET("/channels/join/:channelId", authMiddleware, wsHandler.JoinChannel)
 }
 
 func (h *WSHandler) GetChannels(c *gin.Context) {
@@ -62,6 +65,31 @@ func (h *WSHandler) GetChannels(c *gin.Context) {
 	c.JSON(http.StatusOK, channels)
 }
 
+func (h *WSHandler) GetChannel(c *gin.Context) {
+	ctx := c.Request.Context()
+	var getChannelRequest request.GetChannelRequest
+	if err := c.ShouldBindUri(&getChannelRequest); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	channel, err := h.repo.GetChannelById(ctx, getChannelRequest.ChannelId)
+	if err != nil {
+		statusCode := utils.GetHTTPStatusCode(err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	pinCount, err := h.repo.CountPinsByChannelId(ctx, channel.ID)
+	if err != nil {
+		statusCode := utils.GetHTTPStatusCode(err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.BuildChannelResponse(channel, pinCount))
+}
+
 func (h *WSHandler) GetMemberships(c *gin.Context) {
 	ctx := c.Request.Context()
 	memberships, err := h.repo.GetMemberships(ctx)
@@ -82,34 +110,48 @@ func (h *WSHandler) CreateChannel(c *gin.Context) {
 		return
 	}
 
-	channel, err := h.repo.CreateChannel(ctx, createChannelRequest.Name)
+	claims := c.MustGet(constants.JWTClaims).(*models.JWTClaims)
+	user, err := h.userSvc.GetUserByEmail(ctx, &request.GetUserByEmailRequest{Email: claims.Email})
 	if err != nil {
 		statusCode := utils.GetHTTPStatusCode(err)
 		c.JSON(statusCode, gin.H{"error": err.Error()})
 		return
 	}
 
+	// the creator joins the channel as its admin
+	var channel *db.Channel
+	var membership *db.Membership
+	err = h.repo.ExecTx(ctx, func(q *db.Queries) error {
+		var err error
+		channel, err = q.CreateChannel(ctx, createChannelRequest.Name)
+		if err != nil {
+			return err
+		}
+
+		membership, err = q.CreateMembership(ctx, &db.CreateMembershipParams{
+			UserID:    user.Id,
+			ChannelID: channel.ID,
+			Role:      constants.MembershipRoleAdmin,
+		})
+		return err
+	})
+	if err != nil {
+		statusCode := utils.GetHTTPStatusCode(err)
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.hub.MembershipUpdates <- membership
 	c.JSON(http.StatusOK, channel)
 }
 
 func (h *WSHandler) JoinChat(c *gin.Context) {
 	ctx := c.Request.Context()
-	// var joinChatRequest request.JoinChatRequest
-	// if err := c.ShouldBindUri(&joinChatRequest); err != nil {
-	// 	c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-	// 	return
-	// }
-
-	userId, err := strconv.ParseInt(c.Query("userId"), 10, 64)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid userId query param"})
-		return
-	}
-
-	getUserByIdRequest := request.GetUserByIdRequest{
-		Id: userId,
+	claims := c.MustGet(constants.JWTClaims).(*models.JWTClaims)
+	getUserByEmailRequest := request.GetUserByEmailRequest{
+		Email: claims.Email,
 	}
-	user, err := h.userSvc.GetUserById(ctx, &getUserByIdRequest)
+	user, err := h.userSvc.GetUserByEmail(ctx, &getUserByEmailRequest)
 	if err != nil {
 		statusCode := utils.GetHTTPStatusCode(err)
 		c.JSON(statusCode, gin.H{"error": err.Error()})
@@ -133,10 +175,13 @@ func (h *WSHandler) JoinChat(c *gin.Context) {
 	username := user.Username
 	client := &chat.Client{
 		Id:          user.Id,
+		SessionId:   claims.SessionId,
 		Username:    username,
 		Conn:        conn,
 		MessageChan: make(chan *chat.Message, 10),
 		Memberships: memberships,
+		IsBot:       user.IsBot,
+		ReadOnly:    !claims.HasScope(constants.ScopeWSWrite),
 	}
 
 	// add client to server
@@ -154,15 +199,9 @@ func (h *WSHandler) JoinChannel(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	if err := c.ShouldBindQuery(&joinChannelRequest); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
 
-	getUserByIdRequest := request.GetUserByIdRequest{
-		Id: joinChannelRequest.UserId,
-	}
-	user, err := h.userSvc.GetUserById(ctx, &getUserByIdRequest)
+	claims := c.MustGet(constants.JWTClaims).(*models.JWTClaims)
+	user, err := h.userSvc.GetUserByEmail(ctx, &request.GetUserByEmailRequest{Email: claims.Email})
 	if err != nil {
 		statusCode := utils.GetHTTPStatusCode(err)
 		c.JSON(statusCode, gin.H{"error": err.Error()})
@@ -176,12 +215,7 @@ func (h *WSHandler) JoinChannel(c *gin.Context) {
 		return
 	}
 
-	createMembershipParams := &db.CreateMembershipParams{
-		UserID:    user.Id,
-		ChannelID: channel.ID,
-	}
-
-	membership, err := h.repo.CreateMembership(ctx, createMembershipParams)
+	membership, err := h.hub.AddMember(ctx, user.Id, channel.ID)
 	if err != nil {
 		statusCode := utils.GetHTTPStatusCode(err)
 		c.JSON(statusCode, gin.H{"error": err.Error()})
@@ -0,0 +1,179 @@
+// Package denylist revokes tokens before they expire. Access tokens are
+// checked by signature only, so logging out or revoking a session adds its
+// id, or the jti of a single token, to a denylist in redis, for as long as
+// the tokens concerned could still be valid.
+//
+// Every node keeps the answers it got from redis for a short while: denials
+// until they expire, since they never get lifted, and clean answers for the
+// cache ttl. Denials made on a node are cached there right away.
+package denylist
+
+import (
+	"context"
+	"project/models"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	keyPrefix        = "denylist:"
+	sessionKeyPrefix = keyPrefix + "session:"
+	tokenKeyPrefix   = keyPrefix + "token:"
+)
+
+type entry struct {
+	denied  bool
+	expires time.Time
+}
+
+type Denylist struct {
+	redisClient *redis.Client
+	sessionTTL  time.Duration // longest life of an access token
+	cacheTTL    time.Duration
+	cacheSize   int
+
+	mu    sync.Mutex
+	cache map[string]entry
+}
+
+// New returns a denylist for access tokens living at most sessionTTL.
+func New(redisClient *redis.Client, sessionTTL time.Duration, cacheTTL time.Duration, cacheSize int) *Denylist {
+	return &Denylist{
+		redisClient: redisClient,
+		sessionTTL:  sessionTTL,
+		cacheTTL:    cacheTTL,
+		cacheSize:   cacheSize,
+		cache:       make(map[string]entry),
+	}
+}
+
+// DenySessions denies every token of the sessions.
+func (d *Denylist) DenySessions(ctx context.Context, sessionIds ...uuid.UUID) error {
+	if len(sessionIds) == 0 {
+		return nil
+	}
+
+	pipe := d.redisClient.Pipeline()
+	for _, sessionId := range sessionIds {
+		pipe.Set(ctx, sessionKeyPrefix+sessionId.String(), 1, d.sessionTTL)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return err
+	}
+
+	for _, sessionId := range sessionIds {
+		d.remember(sessionKeyPrefix+sessionId.String(), true, d.sessionTTL)
+	}
+	return nil
+}
+
+// DenyToken denies the token with the jti until it expires.
+func (d *Denylist) DenyToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+
+	err := d.redisClient.Set(ctx, tokenKeyPrefix+jti, 1, ttl).Err()
+	if err != nil {
+		return err
+	}
+
+	d.remember(tokenKeyPrefix+jti, true, ttl)
+	return nil
+}
+
+// UseToken denies the token with the jti until it expires and reports
+// whether this call did, so a token meant for a single use is accepted once
+// across every node.
+func (d *Denylist) UseToken(ctx context.Context, jti string, expiresAt time.Time) (bool, error) {
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return false, nil
+	}
+
+	used, err := d.redisClient.SetNX(ctx, tokenKeyPrefix+jti, 1, ttl).Result()
+	if err != nil {
+		return false, err
+	}
+
+	d.remember(tokenKeyPrefix+jti, true, ttl)
+	return used, nil
+}
+
+// IsDenied reports whether the token or its session was denied.
+func (d *Denylist) IsDenied(ctx context.Context, claims *models.JWTClaims) (bool, error) {
+	keys := make([]string, 0, 2)
+	if len(claims.ID) > 0 {
+		keys = append(keys, tokenKeyPrefix+claims.ID)
+	}
+	if claims.SessionId != uuid.Nil {
+		keys = append(keys, sessionKeyPrefix+claims.SessionId.String())
+	}
+
+	missing := make([]string, 0, len(keys))
+	for _, key := range keys {
+		denied, ok := d.cached(key)
+		if !ok {
+			missing = append(missing, key)
+			continue
+		}
+		if denied {
+			return true, nil
+		}
+	}
+	if len(missing) == 0 {
+		return false, nil
+	}
+
+	pipe := d.redisClient.Pipeline()
+	ttls := make([]*redis.DurationCmd, len(missing))
+	for i, key := range missing {
+		ttls[i] = pipe.PTTL(ctx, key)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return false, err
+	}
+
+	denied := false
+	for i, key := range missing {
+		// PTTL is negative when the key does not exist
+		if ttl := ttls[i].Val(); ttl > 0 {
+			d.remember(key, true, ttl)
+			denied = true
+		} else {
+			d.remember(key, false, d.cacheTTL)
+		}
+	}
+	return denied, nil
+}
+
+func (d *Denylist) cached(key string) (denied bool, ok bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	cached, ok := d.cache[key]
+	if !ok || time.Now().After(cached.expires) {
+		return false, false
+	}
+	return cached.denied, true
+}
+
+func (d *Denylist) remember(key string, denied bool, ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	// clearing the whole cache when it is full keeps it bounded without
+	// tracking the age of entries; it refills from redis
+	if len(d.cache) >= d.cacheSize {
+		d.cache = make(map[string]entry)
+	}
+	d.cache[key] = entry{denied, time.Now().Add(ttl)}
+}
@@ -0,0 +1,204 @@
+package jobs
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"project/config"
+	db "project/db/sqlc"
+	"project/logger"
+	"project/webhooks"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// maxErrorLength bounds the error text kept on outbox entries and delivery
+// logs.
+const maxErrorLength = 512
+
+// Dispatcher delivers outgoing webhook requests from the outbox. Entries are
+// claimed with FOR UPDATE SKIP LOCKED and leased for a while, so every app
+// node can dispatch concurrently and the HTTP request runs outside the
+// transaction. Failed deliveries are retried with exponential backoff; every
+// attempt is kept in webhook_deliveries.
+type Dispatcher struct {
+	repo        db.Repository
+	wg          *sync.WaitGroup
+	client      *http.Client
+	interval    time.Duration
+	batchSize   int32
+	lease       time.Duration
+	maxAttempts int32
+	backoffBase time.Duration
+	backoffMax  time.Duration
+}
+
+func newDispatcher(wg *sync.WaitGroup, cfg *config.StartupConfig, repo db.Repository) *Dispatcher {
+	return &Dispatcher{
+		repo:        repo,
+		wg:          wg,
+		client:      webhooks.NewClient(cfg.Webhooks.Timeout),
+		interval:    cfg.Webhooks.DispatchInterval,
+		batchSize:   cfg.Jobs.BatchSize,
+		lease:       2*cfg.Webhooks.Timeout + time.Second,
+		maxAttempts: cfg.Webhooks.MaxAttempts,
+		backoffBase: cfg.Webhooks.BackoffBase,
+		backoffMax:  cfg.Webhooks.BackoffMax,
+	}
+}
+
+func StartDispatcher(wg *sync.WaitGroup, cfg *config.StartupConfig, repo db.Repository) *Dispatcher {
+	dispatcher := newDispatcher(wg, cfg, repo)
+	go dispatcher.run()
+	return dispatcher
+}
+
+func (d *Dispatcher) run() {
+	d.wg.Add(1)
+	defer d.wg.Done()
+
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		d.dispatch(context.Background())
+	}
+}
+
+func (d *Dispatcher) dispatch(ctx context.Context) {
+	entries, err := d.repo.ClaimDueWebhookOutboxEntries(ctx, &db.ClaimDueWebhookOutboxEntriesParams{
+		LeaseSeconds: int32(d.lease / time.Second),
+		BatchSize:    d.batchSize,
+	})
+	if err != nil {
+		logger.Error(ctx, "dispatch :: failed to claim outbox entries", logger.Field("error", err.Error()))
+		return
+	}
+
+	// one slow endpoint should not hold back the rest of the batch
+	var wg sync.WaitGroup
+	for _, entry := range entries {
+		wg.Add(1)
+		go func(entry *db.WebhookOutbox) {
+			defer wg.Done()
+			d.deliver(ctx, entry)
+		}(entry)
+	}
+	wg.Wait()
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, entry *db.WebhookOutbox) {
+	webhook, err := d.repo.GetOutgoingWebhookById(ctx, entry.WebhookID)
+	if err != nil {
+		logger.Error(ctx, "deliver :: failed to get webhook", logger.Field("outboxId", entry.ID), logger.Field("error", err.Error()))
+		return
+	}
+	if webhook.RevokedAt != nil {
+		d.fail(ctx, entry, "webhook revoked")
+		return
+	}
+
+	start := time.Now()
+	statusCode, err := d.send(ctx, webhook, entry)
+	duration := time.Since(start)
+
+	delivery := &db.CreateWebhookDeliveryParams{
+		WebhookID:  webhook.ID,
+		OutboxID:   entry.ID,
+		Attempt:    entry.Attempts,
+		DurationMs: int32(duration / time.Millisecond),
+	}
+	if statusCode != 0 {
+		code := int32(statusCode)
+		delivery.StatusCode = &code
+	}
+	if err == nil && (statusCode < 200 || statusCode > 299) {
+		err = fmt.Errorf("unexpected status %d", statusCode)
+	}
+	if err != nil {
+		errText := truncate(err.Error())
+		delivery.Error = &errText
+	}
+
+	if logErr := d.repo.CreateWebhookDelivery(ctx, delivery); logErr != nil {
+		logger.Error(ctx, "deliver :: failed to log delivery", logger.Field("outboxId", entry.ID), logger.Field("error", logErr.Error()))
+	}
+
+	if err == nil {
+		if err := d.repo.MarkWebhookOutboxEntryDelivered(ctx, entry.ID); err != nil {
+			logger.Error(ctx, "deliver :: failed to mark entry delivered", logger.Field("outboxId", entry.ID), logger.Field("error", err.Error()))
+		}
+		return
+	}
+
+	if entry.Attempts >= d.maxAttempts {
+		d.fail(ctx, entry, *delivery.Error)
+		return
+	}
+
+	err = d.repo.RetryWebhookOutboxEntry(ctx, &db.RetryWebhookOutboxEntryParams{
+		NextAttemptAt: time.Now().Add(d.backoff(entry.Attempts)),
+		LastError:     delivery.Error,
+		ID:            entry.ID,
+	})
+	if err != nil {
+		logger.Error(ctx, "deliver :: failed to schedule retry", logger.Field("outboxId", entry.ID), logger.Field("error", err.Error()))
+	}
+}
+
+// send posts the signed payload and returns the response status, or zero when
+// no response was received.
+func (d *Dispatcher) send(ctx context.Context, webhook *db.OutgoingWebhook, entry *db.WebhookOutbox) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.Url, bytes.NewReader(entry.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(webhooks.HeaderEvent, webhooks.EventMessageCreated)
+	req.Header.Set(webhooks.HeaderDelivery, strconv.FormatInt(entry.ID, 10))
+	req.Header.Set(webhooks.HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(webhooks.HeaderSignature, webhooks.Sign(webhook.Secret, timestamp, entry.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	return resp.StatusCode, nil
+}
+
+func (d *Dispatcher) fail(ctx context.Context, entry *db.WebhookOutbox, reason string) {
+	err := d.repo.FailWebhookOutboxEntry(ctx, &db.FailWebhookOutboxEntryParams{LastError: &reason, ID: entry.ID})
+	if err != nil {
+		logger.Error(ctx, "fail :: failed to mark entry failed", logger.Field("outboxId", entry.ID), logger.Field("error", err.Error()))
+	}
+}
+
+func (d *Dispatcher) backoff(attempts int32) time.Duration {
+	return backoff(d.backoffBase, d.backoffMax, attempts)
+}
+
+// backoff returns the delay before the next attempt: base doubled for every
+// failed attempt, capped at max.
+func backoff(base time.Duration, max time.Duration, attempts int32) time.Duration {
+	delay := base
+	for i := int32(1); i < attempts && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		delay = max
+	}
+	return delay
+}
+
+func truncate(s string) string {
+	if len(s) > maxErrorLength {
+		return s[:maxErrorLength]
+	}
+	return s
+}
@@ -0,0 +1,116 @@
+package jobs
+
+import (
+	"context"
+	"project/config"
+	db "project/db/sqlc"
+	"project/logger"
+	"project/mailer"
+	"sync"
+	"time"
+)
+
+// MailDispatcher sends the mails of the outbox, claiming and leasing entries
+// like the webhook Dispatcher so every app node can send concurrently.
+// Failed sends are retried with exponential backoff.
+type MailDispatcher struct {
+	repo        db.Repository
+	wg          *sync.WaitGroup
+	mailer      mailer.Mailer
+	interval    time.Duration
+	batchSize   int32
+	lease       time.Duration
+	timeout     time.Duration
+	maxAttempts int32
+	backoffBase time.Duration
+	backoffMax  time.Duration
+}
+
+func newMailDispatcher(wg *sync.WaitGroup, cfg *config.StartupConfig, repo db.Repository, m mailer.Mailer) *MailDispatcher {
+	return &MailDispatcher{
+		repo:        repo,
+		wg:          wg,
+		mailer:      m,
+		interval:    cfg.Mail.DispatchInterval,
+		batchSize:   cfg.Jobs.BatchSize,
+		lease:       2*cfg.Mail.Timeout + time.Second,
+		timeout:     cfg.Mail.Timeout,
+		maxAttempts: cfg.Mail.MaxAttempts,
+		backoffBase: cfg.Mail.BackoffBase,
+		backoffMax:  cfg.Mail.BackoffMax,
+	}
+}
+
+func StartMailDispatcher(wg *sync.WaitGroup, cfg *config.StartupConfig, repo db.Repository, m mailer.Mailer) *MailDispatcher {
+	dispatcher := newMailDispatcher(wg, cfg, repo, m)
+	go dispatcher.run()
+	return dispatcher
+}
+
+func (d *MailDispatcher) run() {
+	d.wg.Add(1)
+	defer d.wg.Done()
+
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		d.dispatch(context.Background())
+	}
+}
+
+func (d *MailDispatcher) dispatch(ctx context.Context) {
+	entries, err := d.repo.ClaimDueMailOutboxEntries(ctx, &db.ClaimDueMailOutboxEntriesParams{
+		LeaseSeconds: int32(d.lease / time.Second),
+		BatchSize:    d.batchSize,
+	})
+	if err != nil {
+		logger.Error(ctx, "dispatch :: failed to claim mail outbox entries", logger.Field("error", err.Error()))
+		return
+	}
+
+	var wg sync.WaitGroup
+	for _, entry := range entries {
+		wg.Add(1)
+		go func(entry *db.MailOutbox) {
+			defer wg.Done()
+			d.send(ctx, entry)
+		}(entry)
+	}
+	wg.Wait()
+}
+
+func (d *MailDispatcher) send(ctx context.Context, entry *db.MailOutbox) {
+	sendCtx, cancel := context.WithTimeout(ctx, d.timeout)
+	defer cancel()
+
+	err := d.mailer.Send(sendCtx, &mailer.Mail{
+		To:      entry.Recipient,
+		Subject: entry.Subject,
+		Body:    entry.Body,
+	})
+	if err == nil {
+		if err := d.repo.MarkMailOutboxEntrySent(ctx, entry.ID); err != nil {
+			logger.Error(ctx, "send :: failed to mark mail sent", logger.Field("outboxId", entry.ID), logger.Field("error", err.Error()))
+		}
+		return
+	}
+
+	errText := truncate(err.Error())
+	if entry.Attempts >= d.maxAttempts {
+		err = d.repo.FailMailOutboxEntry(ctx, &db.FailMailOutboxEntryParams{LastError: &errText, ID: entry.ID})
+		if err != nil {
+			logger.Error(ctx, "send :: failed to mark mail failed", logger.Field("outboxId", entry.ID), logger.Field("error", err.Error()))
+		}
+		return
+	}
+
+	err = d.repo.RetryMailOutboxEntry(ctx, &db.RetryMailOutboxEntryParams{
+		NextAttemptAt: time.Now().Add(backoff(d.backoffBase, d.backoffMax, entry.Attempts)),
+		LastError:     &errText,
+		ID:            entry.ID,
+	})
+	if err != nil {
+		logger.Error(ctx, "send :: failed to schedule retry", logger.Field("outboxId", entry.ID), logger.Field("error", err.Error()))
+	}
+}
@@ -0,0 +1,49 @@
+package jobs
+
+import (
+	"context"
+	"project/config"
+	"project/logger"
+	"project/service"
+	"sync"
+	"time"
+)
+
+// PollCloser closes polls once their closing time has passed and announces
+// the final tallies. Polls are claimed with FOR UPDATE SKIP LOCKED, so every
+// app node can run it.
+type PollCloser struct {
+	pollSvc  service.PollService
+	wg       *sync.WaitGroup
+	interval time.Duration
+}
+
+func newPollCloser(wg *sync.WaitGroup, cfg *config.StartupConfig, pollSvc service.PollService) *PollCloser {
+	return &PollCloser{
+		pollSvc:  pollSvc,
+		wg:       wg,
+		interval: cfg.Jobs.PollInterval,
+	}
+}
+
+func StartPollCloser(wg *sync.WaitGroup, cfg *config.StartupConfig, pollSvc service.PollService) *PollCloser {
+	closer := newPollCloser(wg, cfg, pollSvc)
+	go closer.run()
+	return closer
+}
+
+func (p *PollCloser) run() {
+	p.wg.Add(1)
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		ctx := context.Background()
+		err := p.pollSvc.CloseDuePolls(ctx)
+		if err != nil {
+			logger.Error(ctx, "PollCloser :: failed to close polls", logger.Field("error", err.Error()))
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package jobs
+
+import (
+	"context"
+	"project/config"
+	"project/logger"
+	"project/service"
+	"sync"
+	"time"
+)
+
+// Purger enforces channel retention policies on a fixed interval. Batches
+// are claimed with FOR UPDATE SKIP LOCKED, so nodes purging concurrently
+// split the work instead of blocking each other.
+type Purger struct {
+	retentionSvc service.RetentionService
+	wg           *sync.WaitGroup
+	interval     time.Duration
+}
+
+func newPurger(wg *sync.WaitGroup, cfg *config.StartupConfig, retentionSvc service.RetentionService) *Purger {
+	return &Purger{
+		retentionSvc: retentionSvc,
+		wg:           wg,
+		interval:     cfg.Retention.PurgeInterval,
+	}
+}
+
+func StartPurger(wg *sync.WaitGroup, cfg *config.StartupConfig, retentionSvc service.RetentionService) *Purger {
+	purger := newPurger(wg, cfg, retentionSvc)
+	go purger.run()
+	return purger
+}
+
+func (p *Purger) run() {
+	p.wg.Add(1)
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		ctx := context.Background()
+		err := p.retentionSvc.PurgeMessages(ctx)
+		if err != nil {
+			logger.Error(ctx, "Purger :: failed to purge messages", logger.Field("error", err.Error()))
+		}
+	}
+}
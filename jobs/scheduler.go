@@ -0,0 +1,194 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"project/chat"
+	"project/config"
+	"project/constants"
+	db "project/db/sqlc"
+	"project/logger"
+	"sync"
+	"time"
+)
+
+// Scheduler delivers scheduled messages and reminders once they are due.
+// Every app node runs one; due rows are claimed with FOR UPDATE SKIP LOCKED
+// inside a transaction, so each job fires exactly once across the cluster.
+type Scheduler struct {
+	repo      db.Repository
+	hub       *chat.Hub
+	wg        *sync.WaitGroup
+	interval  time.Duration
+	batchSize int32
+}
+
+func newScheduler(wg *sync.WaitGroup, cfg *config.StartupConfig, repo db.Repository, hub *chat.Hub) *Scheduler {
+	return &Scheduler{
+		repo:      repo,
+		hub:       hub,
+		wg:        wg,
+		interval:  cfg.Jobs.SchedulerInterval,
+		batchSize: cfg.Jobs.BatchSize,
+	}
+}
+
+func StartScheduler(wg *sync.WaitGroup, cfg *config.StartupConfig, repo db.Repository, hub *chat.Hub) *Scheduler {
+	scheduler := newScheduler(wg, cfg, repo, hub)
+	go scheduler.run()
+	return scheduler
+}
+
+func (s *Scheduler) run() {
+	s.wg.Add(1)
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		ctx := context.Background()
+		s.sendScheduledMessages(ctx)
+		s.sendReminders(ctx)
+	}
+}
+
+// sendScheduledMessages claims and sends due messages one at a time, each in
+// its own transaction, so a message that cannot be sent does not hold back
+// the rest of the batch. A failing message is marked failed and skipped.
+func (s *Scheduler) sendScheduledMessages(ctx context.Context) {
+	for i := int32(0); i < s.batchSize; i++ {
+		var claimed *db.ScheduledMessage
+		var message *chat.Message
+		err := s.repo.ExecTx(ctx, func(q *db.Queries) error {
+			claimed, message = nil, nil
+
+			scheduledMessages, err := q.ClaimDueScheduledMessages(ctx, 1)
+			if err != nil || len(scheduledMessages) == 0 {
+				return err
+			}
+			claimed = scheduledMessages[0]
+
+			message, err = s.sendScheduledMessage(ctx, q, claimed)
+			return err
+		})
+		if err != nil && claimed == nil {
+			logger.Error(ctx, "sendScheduledMessages :: failed to claim scheduled messages", logger.Field("error", err.Error()))
+			return
+		}
+		if err != nil {
+			logger.Error(ctx, "sendScheduledMessages :: failed to send scheduled message", logger.Field("scheduledMessageId", claimed.ID), logger.Field("error", err.Error()))
+			if err := s.repo.FailScheduledMessage(ctx, claimed.ID); err != nil {
+				logger.Error(ctx, "sendScheduledMessages :: failed to mark scheduled message failed", logger.Field("scheduledMessageId", claimed.ID), logger.Field("error", err.Error()))
+				return
+			}
+			continue
+		}
+		if claimed == nil {
+			return
+		}
+
+		// broadcast only after the claim is committed
+		if message != nil {
+			s.hub.Publish(message)
+		}
+	}
+}
+
+// sendScheduledMessage posts a claimed scheduled message on q, the way
+// messages typed by the author are posted. A message that is refused, because
+// the author left the channel or moderation rejected or held it, is marked
+// failed and no message is returned.
+func (s *Scheduler) sendScheduledMessage(ctx context.Context, q *db.Queries, scheduledMessage *db.ScheduledMessage) (*chat.Message, error) {
+	user, err := q.GetUserById(ctx, scheduledMessage.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	// the author may have left the channel since the message was scheduled
+	_, err = q.GetMembership(ctx, &db.GetMembershipParams{UserID: scheduledMessage.UserID, ChannelID: scheduledMessage.ChannelID})
+	if errors.Is(err, constants.ErrNoRows) {
+		logger.Info(ctx, "sendScheduledMessage :: scheduled message not sent", logger.Field("scheduledMessageId", scheduledMessage.ID), logger.Field("reason", constants.ErrNotChannelMember.Error()))
+		return nil, q.FailScheduledMessage(ctx, scheduledMessage.ID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// moderation judges the message when it goes out, rules may have changed
+	// since it was scheduled
+	message, err := s.hub.PostMessageOn(ctx, q, user.Username, &db.CreateMessageParams{
+		ChannelID: scheduledMessage.ChannelID,
+		UserID:    scheduledMessage.UserID,
+		Content:   scheduledMessage.Content,
+		IsBot:     user.IsBot,
+	})
+	if errors.Is(err, constants.ErrMessageRejected) || errors.Is(err, constants.ErrMessageHeld) {
+		logger.Info(ctx, "sendScheduledMessage :: scheduled message not sent", logger.Field("scheduledMessageId", scheduledMessage.ID), logger.Field("reason", err.Error()))
+		return nil, q.FailScheduledMessage(ctx, scheduledMessage.ID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = q.SetScheduledMessageMessageId(ctx, &db.SetScheduledMessageMessageIdParams{
+		MessageID: &message.Id,
+		ID:        scheduledMessage.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return message, nil
+}
+
+func (s *Scheduler) sendReminders(ctx context.Context) {
+	var messages []*chat.Message
+	err := s.repo.ExecTx(ctx, func(q *db.Queries) error {
+		messages = nil
+
+		reminders, err := q.ClaimDueReminders(ctx, s.batchSize)
+		if err != nil {
+			return err
+		}
+
+		for _, reminder := range reminders {
+			message, err := q.GetMessageById(ctx, reminder.MessageID)
+			if err != nil {
+				return err
+			}
+
+			author, err := q.GetUserById(ctx, message.UserID)
+			if err != nil {
+				return err
+			}
+
+			username := author.Username
+			if message.UsernameOverride != nil {
+				username = *message.UsernameOverride
+			}
+
+			messages = append(messages, &chat.Message{
+				Type:        chat.EVENT_REMINDER,
+				Content:     message.Content,
+				ChannelId:   message.ChannelID,
+				Username:    username,
+				RecipientId: reminder.UserID,
+				Spans:       message.Spans,
+				Payload: map[string]int64{
+					"reminderId": reminder.ID,
+					"messageId":  message.ID,
+				},
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		logger.Error(ctx, "sendReminders :: failed to send reminders", logger.Field("error", err.Error()))
+		return
+	}
+
+	for _, message := range messages {
+		s.hub.WriteBroadcast <- message
+	}
+}
@@ -0,0 +1,79 @@
+package jobs
+
+import (
+	"context"
+	"project/chat"
+	"project/config"
+	db "project/db/sqlc"
+	"project/logger"
+	"sync"
+	"time"
+)
+
+// Sweeper deletes messages whose expires_at has passed and tells clients to
+// purge them. Rows are deleted with FOR UPDATE SKIP LOCKED, so every app node
+// can sweep at the same time without announcing a message twice. Each tick
+// deletes batches until a short one shows the backlog is drained.
+type Sweeper struct {
+	repo      db.Repository
+	hub       *chat.Hub
+	wg        *sync.WaitGroup
+	interval  time.Duration
+	batchSize int32
+}
+
+func newSweeper(wg *sync.WaitGroup, cfg *config.StartupConfig, repo db.Repository, hub *chat.Hub) *Sweeper {
+	return &Sweeper{
+		repo:      repo,
+		hub:       hub,
+		wg:        wg,
+		interval:  cfg.Jobs.SweeperInterval,
+		batchSize: cfg.Jobs.BatchSize,
+	}
+}
+
+func StartSweeper(wg *sync.WaitGroup, cfg *config.StartupConfig, repo db.Repository, hub *chat.Hub) *Sweeper {
+	sweeper := newSweeper(wg, cfg, repo, hub)
+	go sweeper.run()
+	return sweeper
+}
+
+func (s *Sweeper) run() {
+	s.wg.Add(1)
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		s.deleteExpiredMessages(context.Background())
+	}
+}
+
+func (s *Sweeper) deleteExpiredMessages(ctx context.Context) {
+	for {
+		expired, err := s.repo.DeleteExpiredMessages(ctx, s.batchSize)
+		if err != nil {
+			logger.Error(ctx, "deleteExpiredMessages :: failed to delete expired messages", logger.Field("error", err.Error()))
+			return
+		}
+
+		// one event per channel listing every expired message
+		messageIds := make(map[int64][]int64)
+		for _, message := range expired {
+			messageIds[message.ChannelID] = append(messageIds[message.ChannelID], message.ID)
+		}
+
+		for channelId, ids := range messageIds {
+			s.hub.WriteBroadcast <- &chat.Message{
+				Type:      chat.EVENT_MESSAGE_EXPIRED,
+				ChannelId: channelId,
+				Payload:   map[string][]int64{"messageIds": ids},
+			}
+		}
+
+		if len(expired) < int(s.batchSize) {
+			return
+		}
+	}
+}
@@ -0,0 +1,263 @@
+// Package jwtkeys holds the keys tokens are signed and verified with.
+//
+// Every key is identified by the kid set in the header of the tokens it
+// signs, and follows a schedule: it signs new tokens from its SignFrom until
+// a newer key takes over, and verifies tokens until its VerifyUntil. A key is
+// rotated by adding the next one with a SignFrom in the future, so other
+// services see it in the JWKS before any token uses it, and by keeping the
+// old one until the last tokens it signed have expired. Nobody is logged out.
+//
+// Without configured keys the deployment secret signs HS256 tokens, as it
+// always did; those keys are never published. Once keys are configured the
+// secret, while still set, only verifies the tokens it signed before the
+// switch. It can be removed when the refresh token duration has passed.
+package jwtkeys
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"os"
+	"project/config"
+	"project/constants"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// algorithms
+const (
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+const minRSABits = 2048
+
+type Key struct {
+	ID          string
+	Method      jwt.SigningMethod
+	SignFrom    time.Time // zero signs from the start
+	VerifyUntil time.Time // zero verifies forever
+	private     any       // nil for keys that only verify
+	public      any
+	published   bool // false for shared secrets
+}
+
+func (k *Key) canVerify(now time.Time) bool {
+	return k.VerifyUntil.IsZero() || now.Before(k.VerifyUntil)
+}
+
+func (k *Key) canSign(now time.Time) bool {
+	return k.private != nil && !now.Before(k.SignFrom) && k.canVerify(now)
+}
+
+type Keyring struct {
+	keys map[string]*Key
+}
+
+// NewSecretKeyring signs and verifies HS256 tokens with secret.
+func NewSecretKeyring(secret string) *Keyring {
+	return &Keyring{keys: map[string]*Key{
+		"": {Method: jwt.SigningMethodHS256, private: []byte(secret), public: []byte(secret)},
+	}}
+}
+
+// Load reads the configured signing keys, falling back to the secret when
+// there are none. Next to keys, the secret is kept to verify older tokens.
+func Load(cfg config.TokenConfig) (*Keyring, error) {
+	if len(cfg.SigningKeys) == 0 {
+		if len(cfg.JWTSecret) == 0 {
+			return nil, errors.New("jwtkeys: neither signing keys nor a jwt secret are configured")
+		}
+		return NewSecretKeyring(cfg.JWTSecret), nil
+	}
+
+	ring := &Keyring{keys: make(map[string]*Key, len(cfg.SigningKeys))}
+	for _, keyCfg := range cfg.SigningKeys {
+		key, err := loadKey(keyCfg)
+		if err != nil {
+			return nil, fmt.Errorf("jwtkeys: key %q: %w", keyCfg.Kid, err)
+		}
+		if _, ok := ring.keys[key.ID]; ok {
+			return nil, fmt.Errorf("jwtkeys: key %q is configured twice", key.ID)
+		}
+		ring.keys[key.ID] = key
+	}
+
+	// tokens signed with the secret carry no kid, and kid is required on keys
+	if len(cfg.JWTSecret) > 0 {
+		ring.keys[""] = &Key{Method: jwt.SigningMethodHS256, public: []byte(cfg.JWTSecret)}
+	}
+
+	if _, err := ring.SigningKey(time.Now()); err != nil {
+		return nil, err
+	}
+	return ring, nil
+}
+
+func loadKey(cfg config.SigningKeyConfig) (*Key, error) {
+	if len(cfg.Kid) == 0 {
+		return nil, errors.New("kid is required")
+	}
+
+	key := &Key{ID: cfg.Kid, published: true}
+
+	var err error
+	if len(cfg.SignFrom) > 0 {
+		key.SignFrom, err = time.Parse(time.RFC3339, cfg.SignFrom)
+		if err != nil {
+			return nil, fmt.Errorf("invalid signFrom: %w", err)
+		}
+	}
+	if len(cfg.VerifyUntil) > 0 {
+		key.VerifyUntil, err = time.Parse(time.RFC3339, cfg.VerifyUntil)
+		if err != nil {
+			return nil, fmt.Errorf("invalid verifyUntil: %w", err)
+		}
+	}
+
+	switch {
+	case len(cfg.PrivateKeyFile) > 0:
+		pem, err := os.ReadFile(cfg.PrivateKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		err = key.parsePrivate(cfg.Algorithm, pem)
+		if err != nil {
+			return nil, err
+		}
+	case len(cfg.PublicKeyFile) > 0:
+		pem, err := os.ReadFile(cfg.PublicKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		err = key.parsePublic(cfg.Algorithm, pem)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.New("either privateKeyFile or publicKeyFile is required")
+	}
+
+	return key, nil
+}
+
+func (k *Key) parsePrivate(algorithm string, pem []byte) error {
+	switch algorithm {
+	case AlgorithmRS256:
+		private, err := jwt.ParseRSAPrivateKeyFromPEM(pem)
+		if err != nil {
+			return err
+		}
+		if private.N.BitLen() < minRSABits {
+			return fmt.Errorf("rsa keys need at least %d bits", minRSABits)
+		}
+		k.Method, k.private, k.public = jwt.SigningMethodRS256, private, &private.PublicKey
+
+	case AlgorithmEdDSA:
+		parsed, err := jwt.ParseEdPrivateKeyFromPEM(pem)
+		if err != nil {
+			return err
+		}
+		private := parsed.(ed25519.PrivateKey)
+		k.Method, k.private, k.public = jwt.SigningMethodEdDSA, private, private.Public()
+
+	default:
+		return fmt.Errorf("unsupported algorithm %q", algorithm)
+	}
+	return nil
+}
+
+func (k *Key) parsePublic(algorithm string, pem []byte) error {
+	switch algorithm {
+	case AlgorithmRS256:
+		public, err := jwt.ParseRSAPublicKeyFromPEM(pem)
+		if err != nil {
+			return err
+		}
+		k.Method, k.public = jwt.SigningMethodRS256, public
+
+	case AlgorithmEdDSA:
+		public, err := jwt.ParseEdPublicKeyFromPEM(pem)
+		if err != nil {
+			return err
+		}
+		k.Method, k.public = jwt.SigningMethodEdDSA, public
+
+	default:
+		return fmt.Errorf("unsupported algorithm %q", algorithm)
+	}
+	return nil
+}
+
+// SigningKey returns the key that signs tokens at now: of the keys allowed
+// to sign, the one that started last.
+func (r *Keyring) SigningKey(now time.Time) (*Key, error) {
+	var signing *Key
+	for _, key := range r.keys {
+		if !key.canSign(now) {
+			continue
+		}
+		if signing == nil || key.SignFrom.After(signing.SignFrom) || (key.SignFrom.Equal(signing.SignFrom) && key.ID > signing.ID) {
+			signing = key
+		}
+	}
+	if signing == nil {
+		return nil, constants.ErrNoSigningKey
+	}
+	return signing, nil
+}
+
+// Sign signs claims with the current signing key.
+func (r *Keyring) Sign(claims jwt.Claims) (string, error) {
+	key, err := r.SigningKey(time.Now())
+	if err != nil {
+		return "", err
+	}
+
+	token := jwt.NewWithClaims(key.Method, claims)
+	if len(key.ID) > 0 {
+		token.Header["kid"] = key.ID
+	}
+	return token.SignedString(key.private)
+}
+
+// Methods lists the algorithms of the keys, for jwt.WithValidMethods.
+func (r *Keyring) Methods() []string {
+	seen := make(map[string]bool)
+	methods := make([]string, 0)
+	for _, key := range r.keys {
+		if alg := key.Method.Alg(); !seen[alg] {
+			seen[alg] = true
+			methods = append(methods, alg)
+		}
+	}
+	return methods
+}
+
+// Keyfunc finds the key a token was signed with, for jwt.Parse. The token
+// must use the algorithm of the key, so a public key can never be mistaken
+// for an HMAC secret.
+func (r *Keyring) Keyfunc(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := r.keys[kid]
+	if !ok || !key.canVerify(time.Now()) {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, fmt.Errorf("key %q does not sign %s tokens", kid, token.Method.Alg())
+	}
+	return key.public, nil
+}
+
+// verifiable returns the published keys that verify tokens at now, including
+// those that will only sign later.
+func (r *Keyring) verifiable(now time.Time) []*Key {
+	keys := make([]*Key, 0, len(r.keys))
+	for _, key := range r.keys {
+		if key.published && key.canVerify(now) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
@@ -0,0 +1,53 @@
+package mailer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"project/config"
+	"project/logger"
+	"strconv"
+	"time"
+)
+
+// fileMailer writes every mail as an .eml file to a directory, for
+// development and tests against a real client.
+type fileMailer struct {
+	dir  string
+	from string
+}
+
+func newFileMailer(cfg config.MailConfig) (*fileMailer, error) {
+	if err := os.MkdirAll(cfg.Dir, 0o700); err != nil {
+		return nil, err
+	}
+	return &fileMailer{dir: cfg.Dir, from: cfg.From}, nil
+}
+
+func (m *fileMailer) Send(ctx context.Context, mail *Mail) error {
+	msg, err := message(m.from, mail)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(m.dir, strconv.FormatInt(time.Now().UnixNano(), 10)+"-*.eml")
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if _, err := file.Write(msg); err != nil {
+		return err
+	}
+	logger.Info(ctx, "Send :: mail written", logger.Field("to", mail.To), logger.Field("file", filepath.Base(file.Name())))
+	return nil
+}
+
+// logMailer only logs that a mail would be sent. The body, which holds the
+// tokens, is left out of the logs.
+type logMailer struct{}
+
+func (m *logMailer) Send(ctx context.Context, mail *Mail) error {
+	logger.Info(ctx, "Send :: mail not sent, log driver", logger.Field("to", mail.To), logger.Field("subject", mail.Subject))
+	return nil
+}
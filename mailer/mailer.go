@@ -0,0 +1,60 @@
+// Package mailer sends the mails of the server. Mails are written to an
+// outbox on the transaction of the change they are about and sent later by
+// the mail dispatcher job, so a slow or unreachable mail server never fails
+// a request and no mail goes out for a change that was rolled back.
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"project/config"
+	db "project/db/sqlc"
+	"strings"
+)
+
+// mail drivers
+const (
+	DriverSMTP = "smtp"
+	DriverFile = "file"
+	DriverLog  = "log"
+)
+
+type Mail struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+type Mailer interface {
+	Send(ctx context.Context, mail *Mail) error
+}
+
+// New returns the Mailer of the configured driver.
+func New(cfg config.MailConfig) (Mailer, error) {
+	switch cfg.Driver {
+	case DriverSMTP:
+		return newSMTPMailer(cfg), nil
+	case DriverFile:
+		return newFileMailer(cfg)
+	case DriverLog, "":
+		return &logMailer{}, nil
+	default:
+		return nil, fmt.Errorf("unknown mail driver %q", cfg.Driver)
+	}
+}
+
+// Enqueue writes mail to the outbox. It must run on the transaction of the
+// change the mail is about.
+func Enqueue(ctx context.Context, q *db.Queries, mail *Mail) error {
+	return q.CreateMailOutboxEntry(ctx, &db.CreateMailOutboxEntryParams{
+		Recipient: mail.To,
+		Subject:   mail.Subject,
+		Body:      mail.Body,
+	})
+}
+
+// Link returns the web client url of path with token as its query, the page
+// that takes the token from the user to the api.
+func Link(baseURL string, path string, token string) string {
+	return strings.TrimRight(baseURL, "/") + path + "?token=" + token
+}
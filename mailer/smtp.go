@@ -0,0 +1,103 @@
+package mailer
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"project/config"
+	"time"
+)
+
+// smtpMailer sends mails through an SMTP relay, upgrading the connection
+// with STARTTLS when the server offers it. Credentials are only sent over
+// TLS, net/smtp refuses PLAIN auth on a clear connection to a remote host.
+type smtpMailer struct {
+	addr     string
+	host     string
+	from     string
+	username string
+	password string
+	timeout  time.Duration
+}
+
+func newSMTPMailer(cfg config.MailConfig) *smtpMailer {
+	return &smtpMailer{
+		addr:     net.JoinHostPort(cfg.SMTP.Host, cfg.SMTP.Port),
+		host:     cfg.SMTP.Host,
+		from:     cfg.From,
+		username: cfg.SMTP.Username,
+		password: cfg.SMTP.Password,
+		timeout:  cfg.Timeout,
+	}
+}
+
+func (m *smtpMailer) Send(ctx context.Context, mail *Mail) error {
+	msg, err := message(m.from, mail)
+	if err != nil {
+		return err
+	}
+	sender, err := envelopeAddress(m.from)
+	if err != nil {
+		return err
+	}
+	recipient, err := envelopeAddress(mail.To)
+	if err != nil {
+		return err
+	}
+
+	dialer := &net.Dialer{Timeout: m.timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", m.addr)
+	if err != nil {
+		return err
+	}
+	// bounds the whole conversation, net/smtp has no timeouts of its own
+	if m.timeout > 0 {
+		_ = conn.SetDeadline(time.Now().Add(m.timeout))
+	}
+
+	client, err := smtp.NewClient(conn, m.host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: m.host}); err != nil {
+			return err
+		}
+	}
+	if len(m.username) > 0 {
+		if err := client.Auth(smtp.PlainAuth("", m.username, m.password, m.host)); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(sender); err != nil {
+		return err
+	}
+	if err := client.Rcpt(recipient); err != nil {
+		return err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+func envelopeAddress(address string) (string, error) {
+	parsed, err := mail.ParseAddress(address)
+	if err != nil {
+		return "", err
+	}
+	return parsed.Address, nil
+}
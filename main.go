@@ -4,13 +4,22 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"project/avatar"
 	"project/chat"
 	"project/config"
+	"project/constants"
 	db "project/db/sqlc"
 	"project/delivery"
+	"project/denylist"
+	"project/jobs"
+	"project/jwtkeys"
+	"project/mailer"
 	"project/middleware"
 	"project/models"
+	"project/password"
+	"project/ratelimit"
 	service "project/service/impl"
+	"project/totp"
 	"project/validator"
 	"sync"
 
@@ -50,23 +59,109 @@ func main() {
 	}
 
 	router := gin.Default()
+	err = router.SetTrustedProxies(config.Server.TrustedProxies)
+	if err != nil {
+		log.Fatalln("failed setting trusted proxies", err)
+		return
+	}
 	err = validator.Init()
 	if err != nil {
 		log.Println(err)
 	}
 
+	repository := db.NewRepository(database)
+
+	mail, err := mailer.New(config.Mail)
+	if err != nil {
+		log.Fatalln("failed configuring mailer", err)
+		return
+	}
+
+	signingKeys, err := jwtkeys.Load(config.Token)
+	if err != nil {
+		log.Fatalln("failed loading signing keys", err)
+		return
+	}
+
+	var totpCipher *totp.Cipher
+	if len(config.TwoFactor.EncryptionKey) > 0 {
+		totpCipher, err = totp.NewCipher(config.TwoFactor.EncryptionKey)
+		if err != nil {
+			log.Fatalln("failed loading totp encryption key", err)
+			return
+		}
+	} else if config.TwoFactor.Enforce == constants.TwoFactorEnforceAdmins || config.TwoFactor.Enforce == constants.TwoFactorEnforceAll {
+		log.Fatalln("two-factor authentication is enforced without an encryption key")
+		return
+	}
+
 	// Init Hub
-	hub := chat.InitHub(&wg, config, redis.Client)
+	hub := chat.InitHub(&wg, config, redis.Client, repository)
+	limiter := ratelimit.NewLimiter(redis.Client)
 
-	repository := db.NewRepository(database)
-	tokenService := service.ConfigureTokenService(config, repository)
-	userService := service.ConfigureUserService(config, repository, tokenService)
+	tokenDenylist := denylist.New(redis.Client, config.Token.AccessTokenDuration, config.Denylist.CacheTTL, config.Denylist.CacheSize)
+	passwords, err := password.LoadPolicy(config.Password)
+	if err != nil {
+		log.Fatalln("failed loading password policy", err)
+		return
+	}
 
-	authMiddleware := middleware.AuthMiddleware(tokenService)
+	avatars, err := avatar.NewStore(config.Profile.AvatarDir)
+	if err != nil {
+		log.Fatalln("failed creating avatar directory", err)
+		return
+	}
 
-	delivery.ConfigureTokenHandler(&router.RouterGroup, tokenService)
-	delivery.ConfigureUserHandler(&router.RouterGroup, authMiddleware, userService)
-	delivery.ConfigureWSHandler(&router.RouterGroup, hub, userService, repository)
+	tokenService := service.ConfigureTokenService(config, repository, hub, signingKeys, tokenDenylist)
+	userService := service.ConfigureUserService(config, repository, hub, redis.Client, tokenService, tokenDenylist, totpCipher, passwords)
+	pinService := service.ConfigurePinService(config, repository, hub)
+	scheduleService := service.ConfigureScheduleService(config, repository)
+	channelService := service.ConfigureChannelService(config, repository)
+	retentionService := service.ConfigureRetentionService(config, repository, hub)
+	archiveService := service.ConfigureArchiveService(config, repository)
+	webhookService := service.ConfigureWebhookService(config, repository, hub, limiter)
+	botService := service.ConfigureBotService(config, repository)
+	pollService := service.ConfigurePollService(config, repository, hub)
+	moderationService := service.ConfigureModerationService(config, repository, hub)
+	sessionService := service.ConfigureSessionService(config, repository, hub, tokenDenylist)
+	accountService := service.ConfigureAccountService(config, repository, redis.Client, hub, tokenDenylist, passwords)
+	twoFactorService := service.ConfigureTwoFactorService(config, repository, totpCipher, passwords)
+	ssoService := service.ConfigureSSOService(config, repository, redis.Client, tokenService, totpCipher)
+	profileService := service.ConfigureProfileService(config, repository, hub, avatars)
+
+	// Background jobs
+	jobs.StartScheduler(&wg, config, repository, hub)
+	jobs.StartSweeper(&wg, config, repository, hub)
+	jobs.StartPurger(&wg, config, retentionService)
+	jobs.StartDispatcher(&wg, config, repository)
+	jobs.StartPollCloser(&wg, config, pollService)
+	jobs.StartMailDispatcher(&wg, config, repository, mail)
+
+	authMiddleware := middleware.AuthMiddleware(tokenService, botService, tokenDenylist)
+	wsAuthMiddleware := middleware.WSAuthMiddleware(tokenService, botService, tokenDenylist)
+	adminMiddleware := middleware.AdminMiddleware(userService)
+	authRateLimit := middleware.RateLimitMiddleware(limiter, "auth", ratelimit.PerPeriod(config.RateLimit.Auth.Requests, config.RateLimit.Auth.Per))
+
+	// every route shares the api limit, before any handler is registered
+	router.Use(middleware.RateLimitMiddleware(limiter, "api", ratelimit.PerPeriod(config.RateLimit.API.Requests, config.RateLimit.API.Per)))
+
+	delivery.ConfigureTokenHandler(&router.RouterGroup, authRateLimit, tokenService)
+	delivery.ConfigureUserHandler(&router.RouterGroup, authMiddleware, adminMiddleware, authRateLimit, userService)
+	delivery.ConfigureWSHandler(&router.RouterGroup, authMiddleware, wsAuthMiddleware, hub, userService, repository)
+	delivery.ConfigurePinHandler(&router.RouterGroup, authMiddleware, pinService)
+	delivery.ConfigureScheduleHandler(&router.RouterGroup, authMiddleware, scheduleService)
+	delivery.ConfigureChannelHandler(&router.RouterGroup, authMiddleware, adminMiddleware, channelService)
+	delivery.ConfigureRetentionHandler(&router.RouterGroup, authMiddleware, adminMiddleware, retentionService)
+	delivery.ConfigureArchiveHandler(&router.RouterGroup, authMiddleware, adminMiddleware, archiveService)
+	delivery.ConfigureWebhookHandler(&router.RouterGroup, authMiddleware, webhookService)
+	delivery.ConfigureBotHandler(&router.RouterGroup, authMiddleware, botService)
+	delivery.ConfigurePollHandler(&router.RouterGroup, authMiddleware, pollService)
+	delivery.ConfigureModerationHandler(&router.RouterGroup, authMiddleware, moderationService)
+	delivery.ConfigureSessionHandler(&router.RouterGroup, authMiddleware, adminMiddleware, sessionService)
+	delivery.ConfigureAccountHandler(&router.RouterGroup, authMiddleware, authRateLimit, accountService)
+	delivery.ConfigureTwoFactorHandler(&router.RouterGroup, authMiddleware, adminMiddleware, authRateLimit, twoFactorService)
+	delivery.ConfigureSSOHandler(&router.RouterGroup, authMiddleware, authRateLimit, ssoService)
+	delivery.ConfigureProfileHandler(&router.RouterGroup, authMiddleware, profileService)
 
 	err = router.Run(config.Server.Address)
 	if err != nil {
@@ -0,0 +1,111 @@
+// Package markdown validates the message markup accepted by the server and
+// turns it into spans every client renders the same way.
+//
+// The supported subset is:
+//
+//	**bold**
+//	_italic_ or *italic*
+//	`code`
+//	```lang
+//	code block
+//	```
+//	[text](https://example.com)
+//	https://example.com         (bare links)
+//	@username                   (mentions)
+//	\*                          (escapes the next markup character)
+//
+// Spans do not nest and never carry markup of their own: the text of a span
+// is plain text, HTML included, and clients are expected to render it as
+// such. Link targets are limited to http, https and mailto, anything else is
+// kept as text. Markup that is not closed is kept as text too.
+package markdown
+
+import (
+	"fmt"
+	"project/constants"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// span types
+const (
+	SpanText      = "text"
+	SpanBold      = "bold"
+	SpanItalic    = "italic"
+	SpanCode      = "code"
+	SpanCodeBlock = "code_block"
+	SpanLink      = "link"
+	SpanMention   = "mention"
+)
+
+type Span struct {
+	Type     string `json:"type"`
+	Text     string `json:"text"`
+	URL      string `json:"url,omitempty"`      // links
+	Language string `json:"language,omitempty"` // code blocks
+}
+
+// Format normalizes source, enforces maxLength, counted in characters after
+// normalization, and parses it. A maxLength of zero disables the check.
+func Format(source string, maxLength int) (string, []Span, error) {
+	normalized, err := Normalize(source)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if length := utf8.RuneCountInString(normalized); maxLength > 0 && length > maxLength {
+		return "", nil, fmt.Errorf("%w: %d characters, at most %d are allowed", constants.ErrMessageTooLong, length, maxLength)
+	}
+
+	return normalized, Parse(normalized), nil
+}
+
+// Normalize rejects invalid UTF-8 and returns source in NFC with unix line
+// endings, without control or bidirectional override characters, without
+// trailing spaces and with at most one blank line in a row.
+func Normalize(source string) (string, error) {
+	if !utf8.ValidString(source) {
+		return "", constants.ErrInvalidMessageEncoding
+	}
+
+	source = strings.ReplaceAll(source, "\r\n", "\n")
+	source = strings.ReplaceAll(source, "\r", "\n")
+	source = norm.NFC.String(source)
+
+	source = strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\t' {
+			return r
+		}
+		if unicode.IsControl(r) || isBidiControl(r) {
+			return -1
+		}
+		return r
+	}, source)
+
+	lines := strings.Split(source, "\n")
+	normalized := make([]string, 0, len(lines))
+	blank := 0
+	for _, line := range lines {
+		line = strings.TrimRightFunc(line, unicode.IsSpace)
+		if len(line) == 0 {
+			blank++
+			if blank > 1 {
+				continue
+			}
+		} else {
+			blank = 0
+		}
+		normalized = append(normalized, line)
+	}
+
+	return strings.Trim(strings.Join(normalized, "\n"), "\n"), nil
+}
+
+// isBidiControl reports the embedding, override and isolate characters that
+// can make text display differently from how it reads.
+func isBidiControl(r rune) bool {
+	return (r >= '\u202a' && r <= '\u202e') || (r >= '\u2066' && r <= '\u2069')
+}
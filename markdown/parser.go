@@ -0,0 +1,244 @@
+package markdown
+
+import (
+	"net/url"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	maxLanguageLength = 20
+	maxUsernameLength = 64
+)
+
+// Parse splits normalized source into spans. It never fails, anything that
+// is not valid markup is kept as text.
+func Parse(source string) []Span {
+	p := &parser{src: source}
+	p.parse()
+	return p.spans
+}
+
+type parser struct {
+	src   string
+	pos   int
+	text  strings.Builder
+	spans []Span
+}
+
+func (p *parser) parse() {
+	for p.pos < len(p.src) {
+		rest := p.src[p.pos:]
+		switch {
+		case rest[0] == '\\' && len(rest) > 1 && isMarkup(rest[1]):
+			p.text.WriteByte(rest[1])
+			p.pos += 2
+			continue
+		case strings.HasPrefix(rest, "```"):
+			if p.codeBlock() {
+				continue
+			}
+		case rest[0] == '`':
+			if p.delimited("`", SpanCode, false) {
+				continue
+			}
+		case strings.HasPrefix(rest, "**"):
+			if p.delimited("**", SpanBold, true) {
+				continue
+			}
+		case rest[0] == '*' || rest[0] == '_':
+			if p.atWordStart() && p.delimited(rest[:1], SpanItalic, true) {
+				continue
+			}
+		case rest[0] == '[':
+			if p.link() {
+				continue
+			}
+		case rest[0] == '@':
+			if p.atWordStart() && p.mention() {
+				continue
+			}
+		case strings.HasPrefix(rest, "http://") || strings.HasPrefix(rest, "https://"):
+			if p.atWordStart() && p.autolink() {
+				continue
+			}
+		}
+
+		r, size := utf8.DecodeRuneInString(rest)
+		p.text.WriteRune(r)
+		p.pos += size
+	}
+	p.flush()
+}
+
+func isMarkup(c byte) bool {
+	return strings.IndexByte("\\`*_[]()@", c) >= 0
+}
+
+// atWordStart reports whether the current position does not follow a letter
+// or digit, so snake_case names and email addresses stay text.
+func (p *parser) atWordStart() bool {
+	if p.pos == 0 {
+		return true
+	}
+	r, _ := utf8.DecodeLastRuneInString(p.src[:p.pos])
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+func (p *parser) flush() {
+	if p.text.Len() > 0 {
+		p.spans = append(p.spans, Span{Type: SpanText, Text: p.text.String()})
+		p.text.Reset()
+	}
+}
+
+func (p *parser) emit(span Span) {
+	p.flush()
+	p.spans = append(p.spans, span)
+}
+
+// delimited parses spanType text enclosed in delim on a single line. Emphasis
+// may not start or end with a space, so "2 * 3 * 4" stays text.
+func (p *parser) delimited(delim string, spanType string, emphasis bool) bool {
+	start := p.pos + len(delim)
+	end := strings.Index(p.src[start:], delim)
+	if end <= 0 {
+		return false
+	}
+	inner := p.src[start : start+end]
+	if strings.Contains(inner, "\n") {
+		return false
+	}
+	if emphasis {
+		first, _ := utf8.DecodeRuneInString(inner)
+		last, _ := utf8.DecodeLastRuneInString(inner)
+		if unicode.IsSpace(first) || unicode.IsSpace(last) {
+			return false
+		}
+		next, _ := utf8.DecodeRuneInString(p.src[start+end+len(delim):])
+		if unicode.IsLetter(next) || unicode.IsDigit(next) {
+			return false
+		}
+	}
+
+	p.emit(Span{Type: spanType, Text: inner})
+	p.pos = start + end + len(delim)
+	return true
+}
+
+// codeBlock parses a fenced block; the rest of the opening line names the
+// language.
+func (p *parser) codeBlock() bool {
+	start := p.pos + 3
+	end := strings.Index(p.src[start:], "```")
+	if end < 0 {
+		return false
+	}
+	inner := p.src[start : start+end]
+
+	language := ""
+	if firstLine, body, ok := strings.Cut(inner, "\n"); ok {
+		if len(firstLine) <= maxLanguageLength && !strings.ContainsFunc(firstLine, unicode.IsSpace) {
+			language, inner = firstLine, body
+		}
+	}
+	inner = strings.TrimSuffix(inner, "\n")
+	if len(inner) == 0 {
+		return false
+	}
+
+	p.emit(Span{Type: SpanCodeBlock, Text: inner, Language: language})
+	p.pos = start + end + 3
+	return true
+}
+
+// link parses [text](url).
+func (p *parser) link() bool {
+	rest := p.src[p.pos:]
+	closeText := strings.Index(rest, "](")
+	if closeText <= 1 {
+		return false
+	}
+	closeURL := strings.IndexByte(rest[closeText+2:], ')')
+	if closeURL <= 0 {
+		return false
+	}
+
+	text := rest[1:closeText]
+	target, ok := sanitizeURL(rest[closeText+2 : closeText+2+closeURL])
+	if !ok || strings.Contains(text, "\n") {
+		return false
+	}
+
+	p.emit(Span{Type: SpanLink, Text: text, URL: target})
+	p.pos += closeText + 2 + closeURL + 1
+	return true
+}
+
+// autolink parses a bare http(s) link up to the next space. Trailing
+// punctuation is left out, it usually ends the sentence.
+func (p *parser) autolink() bool {
+	rest := p.src[p.pos:]
+	end := strings.IndexFunc(rest, unicode.IsSpace)
+	if end < 0 {
+		end = len(rest)
+	}
+	raw := strings.TrimRight(rest[:end], ".,;:!?)'\"")
+
+	target, ok := sanitizeURL(raw)
+	if !ok {
+		return false
+	}
+
+	p.emit(Span{Type: SpanLink, Text: raw, URL: target})
+	p.pos += len(raw)
+	return true
+}
+
+// mention parses @username.
+func (p *parser) mention() bool {
+	rest := p.src[p.pos+1:]
+	end := strings.IndexFunc(rest, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '.' && r != '-'
+	})
+	if end < 0 {
+		end = len(rest)
+	}
+	username := strings.TrimRight(rest[:end], ".-")
+	if len(username) == 0 || len(username) > maxUsernameLength {
+		return false
+	}
+
+	p.emit(Span{Type: SpanMention, Text: username})
+	p.pos += 1 + len(username)
+	return true
+}
+
+// sanitizeURL accepts absolute http, https and mailto urls and returns them
+// re-encoded.
+func sanitizeURL(raw string) (string, bool) {
+	if strings.ContainsFunc(raw, unicode.IsSpace) {
+		return "", false
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", false
+	}
+
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https":
+		if len(u.Host) == 0 {
+			return "", false
+		}
+	case "mailto":
+		if len(u.Opaque) == 0 {
+			return "", false
+		}
+	default:
+		return "", false
+	}
+
+	return u.String(), true
+}
@@ -0,0 +1,62 @@
+package markdown
+
+import "testing"
+
+func TestSanitizeURL(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+		ok   bool
+	}{
+		{"https://example.com/a?b=c#d", "https://example.com/a?b=c#d", true},
+		{"http://example.com", "http://example.com", true},
+		{"HTTPS://example.com/", "https://example.com/", true},
+		{"mailto:someone@example.com", "mailto:someone@example.com", true},
+		{"https://example.com/a\"b<c>", "https://example.com/a%22b%3Cc%3E", true},
+
+		{"javascript:alert(1)", "", false},
+		{"JavaScript:alert(1)", "", false},
+		{"data:text/html;base64,PHNjcmlwdD4=", "", false},
+		{"vbscript:msgbox(1)", "", false},
+		{"file:///etc/passwd", "", false},
+		{"//example.com/a", "", false},
+		{"/relative/path", "", false},
+		{"http:///nohost", "", false},
+		{"https:example.com", "", false},
+		{"mailto:", "", false},
+		{"https://example.com/a b", "", false},
+		{"java\tscript:alert(1)", "", false},
+		{"https://example.com/%zz", "", false},
+	}
+
+	for _, test := range tests {
+		got, ok := sanitizeURL(test.raw)
+		if ok != test.ok || got != test.want {
+			t.Errorf("sanitizeURL(%q) = %q, %v, want %q, %v", test.raw, got, ok, test.want, test.ok)
+		}
+	}
+}
+
+func TestParseLinks(t *testing.T) {
+	tests := []struct {
+		source string
+		url    string
+	}{
+		{"[click](https://example.com)", "https://example.com"},
+		{"see https://example.com/page.", "https://example.com/page"},
+		{"[click](javascript:alert(1))", ""},
+		{"javascript:alert(1)", ""},
+	}
+
+	for _, test := range tests {
+		var url string
+		for _, span := range Parse(test.source) {
+			if span.Type == SpanLink {
+				url = span.URL
+			}
+		}
+		if url != test.url {
+			t.Errorf("Parse(%q) link = %q, want %q", test.source, url, test.url)
+		}
+	}
+}
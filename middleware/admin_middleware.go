@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"net/http"
+	"project/constants"
+	"project/models"
+	"project/models/request"
+	"project/service"
+	"project/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminMiddleware must run after AuthMiddleware; it only lets deployment
+// admins through.
+func AdminMiddleware(userSvc service.UserService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims := c.MustGet(constants.JWTClaims).(*models.JWTClaims)
+		user, err := userSvc.GetUserByEmail(c, &request.GetUserByEmailRequest{Email: claims.Email})
+		if err != nil {
+			c.AbortWithStatusJSON(utils.GetHTTPStatusCode(err), err.Error())
+			return
+		}
+
+		if !user.IsAdmin {
+			err := constants.ErrAdminRequired
+			c.AbortWithStatusJSON(http.StatusForbidden, err.Error())
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -1,47 +1,115 @@
 package middleware
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"project/constants"
+	"project/denylist"
+	"project/logger"
+	"project/models"
 	"project/service"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
-func AuthMiddleware(tokenSvc service.TokenService) gin.HandlerFunc {
+func AuthMiddleware(tokenSvc service.TokenService, botSvc service.BotService, tokenDenylist *denylist.Denylist) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader(constants.HeaderAuthorization)
-		if len(authHeader) == 0 {
-			err := constants.ErrEmptyAuthHeader
+		token, err := parseAuthHeader(authHeader)
+		if err != nil {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, err.Error())
 			return
 		}
 
-		fields := strings.Fields(authHeader)
-		if len(fields) < 2 {
-			err := constants.ErrInvalidAuthHeader
+		claims, err := verifyCredentials(c, tokenSvc, botSvc, tokenDenylist, token)
+		if err != nil {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, err.Error())
 			return
 		}
 
-		authType := strings.ToLower(fields[0])
-		if authType != constants.BearerAuthorizationType {
-			err := fmt.Errorf("unsupported authorization type %s", authType)
-			c.AbortWithStatusJSON(http.StatusUnauthorized, err.Error())
+		if !claims.HasScope(constants.ScopeApi) {
+			err := constants.ErrInsufficientScope
+			c.AbortWithStatusJSON(http.StatusForbidden, err.Error())
 			return
 		}
 
-		token := fields[1]
-		// TODO: only allow verification of access token
-		claims, err := tokenSvc.VerifyToken(c, token)
+		c.Set(constants.JWTClaims, claims)
+		c.Next()
+	}
+}
+
+// WSAuthMiddleware authenticates the websocket upgrade. Browsers cannot set
+// headers on websocket requests, so the token may also be passed in the token
+// query param.
+func WSAuthMiddleware(tokenSvc service.TokenService, botSvc service.BotService, tokenDenylist *denylist.Denylist) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := c.Query("token")
+		if len(token) == 0 {
+			var err error
+			token, err = parseAuthHeader(c.GetHeader(constants.HeaderAuthorization))
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, err.Error())
+				return
+			}
+		}
+
+		claims, err := verifyCredentials(c, tokenSvc, botSvc, tokenDenylist, token)
 		if err != nil {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, err.Error())
 			return
 		}
 
+		if !claims.HasScope(constants.ScopeWSRead) {
+			err := constants.ErrInsufficientScope
+			c.AbortWithStatusJSON(http.StatusForbidden, err.Error())
+			return
+		}
+
 		c.Set(constants.JWTClaims, claims)
 		c.Next()
 	}
 }
+
+func parseAuthHeader(authHeader string) (string, error) {
+	if len(authHeader) == 0 {
+		return "", constants.ErrEmptyAuthHeader
+	}
+
+	fields := strings.Fields(authHeader)
+	if len(fields) < 2 {
+		return "", constants.ErrInvalidAuthHeader
+	}
+
+	authType := strings.ToLower(fields[0])
+	if authType != constants.BearerAuthorizationType {
+		return "", fmt.Errorf("unsupported authorization type %s", authType)
+	}
+
+	return fields[1], nil
+}
+
+// verifyCredentials accepts either an api key or a jwt that was not revoked.
+// The denylist fails open: while redis is unavailable, revoked tokens work
+// until they expire.
+func verifyCredentials(ctx context.Context, tokenSvc service.TokenService, botSvc service.BotService, tokenDenylist *denylist.Denylist, token string) (*models.JWTClaims, error) {
+	if strings.HasPrefix(token, constants.ApiKeyPrefix) {
+		return botSvc.VerifyApiKey(ctx, token)
+	}
+
+	claims, err := tokenSvc.VerifyToken(ctx, token, constants.TokenTypeAccess)
+	if err != nil {
+		return nil, err
+	}
+
+	denied, err := tokenDenylist.IsDenied(ctx, claims)
+	if err != nil {
+		logger.Error(ctx, "verifyCredentials :: failed to check denylist", logger.Field("error", err.Error()))
+	}
+	if denied {
+		return nil, constants.ErrTokenRevoked
+	}
+
+	return claims, nil
+}
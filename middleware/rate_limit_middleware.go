@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"net/http"
+	"project/constants"
+	"project/logger"
+	"project/ratelimit"
+	"project/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimitMiddleware takes a token from the bucket of the client address
+// under name, so routes sharing a name share a limit. Every response carries
+// the X-RateLimit headers; refused requests get 429 with Retry-After. When
+// redis is unreachable requests are let through.
+func RateLimitMiddleware(limiter *ratelimit.Limiter, name string, limit ratelimit.Limit) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if limit.Disabled() {
+			c.Next()
+			return
+		}
+
+		// ClientIP only follows X-Forwarded-For from server.trustedProxies
+		result, err := limiter.Allow(c, name+":ip:"+c.ClientIP(), limit)
+		if err != nil {
+			logger.Error(c, "RateLimitMiddleware :: failed to check rate limit", logger.Field("error", err.Error()))
+			c.Next()
+			return
+		}
+
+		c.Header(constants.HeaderRateLimitLimit, strconv.Itoa(result.Limit))
+		c.Header(constants.HeaderRateLimitRemaining, strconv.Itoa(result.Remaining))
+		c.Header(constants.HeaderRateLimitReset, strconv.Itoa(utils.RetryAfterSeconds(result.ResetAfter)))
+
+		if !result.Allowed {
+			c.Header(constants.HeaderRetryAfter, strconv.Itoa(utils.RetryAfterSeconds(result.RetryAfter)))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, constants.ErrRateLimited.Error())
+			return
+		}
+
+		c.Next()
+	}
+}
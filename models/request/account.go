@@ -0,0 +1,43 @@
+package request
+
+import "github.com/google/uuid"
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,isEmail"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,max=128"`
+	ClientIp string
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type ResendVerificationEmailRequest struct {
+	Email string `json:"email" binding:"required,isEmail"`
+}
+
+type ChangePasswordRequest struct {
+	// not needed by accounts without a password, like those provisioned by
+	// single sign-on
+	CurrentPassword  string `json:"currentPassword"`
+	NewPassword      string `json:"newPassword" binding:"required,max=128"`
+	Email            string
+	CurrentSessionId uuid.UUID
+	ClientIp         string
+}
+
+type ChangeEmailRequest struct {
+	NewEmail string `json:"newEmail" binding:"required,isEmail"`
+	// not needed by accounts without a password
+	Password string `json:"password"`
+	Email    string
+}
+
+type ConfirmEmailChangeRequest struct {
+	Token    string `json:"token" binding:"required"`
+	ClientIp string
+}
@@ -0,0 +1,15 @@
+package request
+
+type ExportChannelRequest struct {
+	ChannelId int64  `uri:"channelId"`
+	Format    string `form:"format" binding:"omitempty,oneof=json jsonl"`
+}
+
+type ImportChannelRequest struct {
+	Format string `form:"format" binding:"omitempty,oneof=json jsonl"`
+	// Name overrides the channel name stored in the archive.
+	Name string `form:"name"`
+	// CreateMissingUsers creates a placeholder account, which cannot log in,
+	// for every archived email with no matching user.
+	CreateMissingUsers bool `form:"createMissingUsers"`
+}
@@ -0,0 +1,29 @@
+package request
+
+type CreateBotRequest struct {
+	Username string `json:"username" binding:"required,max=64"`
+	Email    string
+}
+
+type GetBotsRequest struct {
+	Email string
+}
+
+type CreateApiKeyRequest struct {
+	BotId         int64    `uri:"botId"`
+	Name          string   `json:"name" binding:"required,max=64"`
+	Scopes        []string `json:"scopes" binding:"required,min=1,dive,oneof=api ws:read ws:write"`
+	ExpiresInDays *int32   `json:"expiresInDays" binding:"omitempty,min=1,max=3650"`
+	Email         string
+}
+
+type GetApiKeysRequest struct {
+	BotId int64 `uri:"botId" binding:"required"`
+	Email string
+}
+
+type RevokeApiKeyRequest struct {
+	BotId int64 `uri:"botId" binding:"required"`
+	KeyId int64 `uri:"keyId" binding:"required"`
+	Email string
+}
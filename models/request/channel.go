@@ -0,0 +1,31 @@
+package request
+
+type UpdateMessageTtlRequest struct {
+	ChannelId  int64  `uri:"channelId"`
+	TtlSeconds *int32 `json:"ttlSeconds" binding:"omitempty,min=1"`
+	Email      string
+}
+
+// UpdateSlowModeRequest sets the seconds members must wait between two
+// messages, up to six hours; 0 turns slow mode off.
+type UpdateSlowModeRequest struct {
+	ChannelId int64  `uri:"channelId"`
+	Seconds   *int32 `json:"seconds" binding:"required,min=0,max=21600"`
+	Email     string
+}
+
+type UpdateChannelRetentionRequest struct {
+	ChannelId int64  `uri:"channelId"`
+	Policy    string `json:"policy" binding:"required,oneof=inherit forever days messages"`
+	Value     *int32 `json:"value" binding:"omitempty,min=1"`
+	Email     string
+}
+
+// UpdateMemberRoleRequest lets a site admin make a channel member an admin of
+// the channel, or a plain member again.
+type UpdateMemberRoleRequest struct {
+	ChannelId int64  `uri:"channelId"`
+	UserId    int64  `uri:"userId"`
+	Role      string `json:"role" binding:"required,oneof=member admin"`
+	Email     string
+}
@@ -0,0 +1,38 @@
+package request
+
+type CreateModerationRuleRequest struct {
+	ChannelId int64  `uri:"channelId"`
+	Kind      string `json:"kind" binding:"required,oneof=word regex link_allow link_deny"`
+	Pattern   string `json:"pattern" binding:"required,max=256"`
+	Action    string `json:"action" binding:"omitempty,oneof=reject hold"` // reject by default
+	Email     string
+}
+
+type GetModerationRulesRequest struct {
+	ChannelId int64 `uri:"channelId" binding:"required"`
+	Email     string
+}
+
+type DeleteModerationRuleRequest struct {
+	ChannelId int64 `uri:"channelId" binding:"required"`
+	RuleId    int64 `uri:"ruleId" binding:"required"`
+	Email     string
+}
+
+type GetHeldMessagesRequest struct {
+	ChannelId int64 `uri:"channelId" binding:"required"`
+	Email     string
+}
+
+type ApproveHeldMessageRequest struct {
+	ChannelId     int64 `uri:"channelId" binding:"required"`
+	HeldMessageId int64 `uri:"heldMessageId" binding:"required"`
+	Email         string
+}
+
+type RejectHeldMessageRequest struct {
+	ChannelId     int64  `uri:"channelId"`
+	HeldMessageId int64  `uri:"heldMessageId"`
+	Reason        string `json:"reason" binding:"max=256"` // sent to the author
+	Email         string
+}
@@ -0,0 +1,32 @@
+package request
+
+type GetPinsRequest struct {
+	ChannelId int64 `uri:"channelId" binding:"required"`
+	Email     string
+}
+
+type PinMessageRequest struct {
+	ChannelId int64 `uri:"channelId"`
+	MessageId int64 `json:"messageId" binding:"required"`
+	Email     string
+}
+
+type UnpinMessageRequest struct {
+	ChannelId int64 `uri:"channelId" binding:"required"`
+	MessageId int64 `uri:"messageId" binding:"required"`
+	Email     string
+}
+
+type GetSavedItemsRequest struct {
+	Email string
+}
+
+type SaveMessageRequest struct {
+	MessageId int64 `json:"messageId" binding:"required"`
+	Email     string
+}
+
+type UnsaveMessageRequest struct {
+	MessageId int64 `uri:"messageId" binding:"required"`
+	Email     string
+}
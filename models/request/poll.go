@@ -0,0 +1,34 @@
+package request
+
+import "time"
+
+type CreatePollRequest struct {
+	ChannelId      int64      `uri:"channelId"`
+	Question       string     `json:"question" binding:"required,max=300"`
+	Options        []string   `json:"options" binding:"required,min=2,max=10,dive,required,max=100"`
+	MultipleChoice bool       `json:"multipleChoice"`
+	Anonymous      bool       `json:"anonymous"`
+	ClosesAt       *time.Time `json:"closesAt"`
+	Email          string
+}
+
+type GetPollRequest struct {
+	PollId int64 `uri:"pollId" binding:"required"`
+	Email  string
+}
+
+type VotePollRequest struct {
+	PollId    int64   `uri:"pollId"`
+	OptionIds []int64 `json:"optionIds" binding:"required,min=1,max=10,dive,required"`
+	Email     string
+}
+
+type RetractPollVoteRequest struct {
+	PollId int64 `uri:"pollId" binding:"required"`
+	Email  string
+}
+
+type ClosePollRequest struct {
+	PollId int64 `uri:"pollId" binding:"required"`
+	Email  string
+}
@@ -0,0 +1,23 @@
+package request
+
+// UpdateProfileRequest changes the fields that are set, an empty string
+// clears a field.
+type UpdateProfileRequest struct {
+	DisplayName *string `json:"displayName" binding:"omitempty,max=64"`
+	Bio         *string `json:"bio" binding:"omitempty,max=500"`
+	TimeZone    *string `json:"timeZone" binding:"omitempty,max=64"`
+	Phone       *string `json:"phone" binding:"omitempty,max=32"`
+	Email       string
+}
+
+type UpdateAvatarRequest struct {
+	Email string
+}
+
+type DeleteAvatarRequest struct {
+	Email string
+}
+
+type GetAvatarRequest struct {
+	File string `uri:"file" binding:"required"`
+}
@@ -0,0 +1,34 @@
+package request
+
+import "time"
+
+type ScheduleMessageRequest struct {
+	ChannelId int64     `uri:"channelId"`
+	Content   string    `json:"content" binding:"required"`
+	SendAt    time.Time `json:"sendAt" binding:"required"`
+	Email     string
+}
+
+type GetScheduledMessagesRequest struct {
+	Email string
+}
+
+type CancelScheduledMessageRequest struct {
+	Id    int64 `uri:"scheduledMessageId" binding:"required"`
+	Email string
+}
+
+type CreateReminderRequest struct {
+	MessageId int64     `json:"messageId" binding:"required"`
+	RemindAt  time.Time `json:"remindAt" binding:"required"`
+	Email     string
+}
+
+type GetRemindersRequest struct {
+	Email string
+}
+
+type CancelReminderRequest struct {
+	Id    int64 `uri:"reminderId" binding:"required"`
+	Email string
+}
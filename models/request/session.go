@@ -0,0 +1,32 @@
+package request
+
+import "github.com/google/uuid"
+
+type GetSessionsRequest struct {
+	Email            string
+	CurrentSessionId uuid.UUID
+}
+
+type RevokeSessionRequest struct {
+	SessionId        string `uri:"sessionId" binding:"required,uuid"`
+	Email            string
+	CurrentSessionId uuid.UUID
+}
+
+type RevokeOtherSessionsRequest struct {
+	Email            string
+	CurrentSessionId uuid.UUID
+}
+
+type AdminRevokeSessionRequest struct {
+	UserId    int64  `uri:"userId" binding:"required"`
+	SessionId string `uri:"sessionId" binding:"required,uuid"`
+	Email     string
+	ClientIp  string
+}
+
+type AdminRevokeSessionsRequest struct {
+	UserId   int64 `uri:"userId" binding:"required"`
+	Email    string
+	ClientIp string
+}
@@ -0,0 +1,36 @@
+package request
+
+type StartSSOLoginRequest struct {
+	Provider string `uri:"provider" binding:"required"`
+}
+
+type CompleteSSOLoginRequest struct {
+	Provider  string `uri:"provider"`
+	Code      string `json:"code" binding:"required"`
+	State     string `json:"state" binding:"required"`
+	UserAgent string
+	ClientIp  string
+}
+
+type StartIdentityLinkRequest struct {
+	Provider string `uri:"provider" binding:"required"`
+	Email    string
+}
+
+type CompleteIdentityLinkRequest struct {
+	Provider string `uri:"provider"`
+	Code     string `json:"code" binding:"required"`
+	State    string `json:"state" binding:"required"`
+	Email    string
+	ClientIp string
+}
+
+type GetIdentitiesRequest struct {
+	Email string
+}
+
+type UnlinkIdentityRequest struct {
+	IdentityId int64 `uri:"identityId" binding:"required"`
+	Email      string
+	ClientIp   string
+}
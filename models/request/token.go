@@ -1,13 +1,21 @@
 package request
 
-import "time"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type CreateTokenRequest struct {
-	Email     string
 	Type      string
+	UserId    int64
+	Email     string
+	SessionId uuid.UUID
 	ExpiresIn time.Duration
 }
 
 type RenewAccessTokenRequest struct {
 	RefreshToken string `json:"refreshToken" binding:"required"`
+	UserAgent    string
+	ClientIp     string
 }
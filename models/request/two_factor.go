@@ -0,0 +1,45 @@
+package request
+
+type LoginSecondFactorRequest struct {
+	ChallengeToken string `json:"challengeToken" binding:"required"`
+	Code           string `json:"code" binding:"required"` // totp or recovery code
+	UserAgent      string
+	ClientIp       string
+}
+
+type EnrollLoginSecondFactorRequest struct {
+	ChallengeToken string `json:"challengeToken" binding:"required"`
+}
+
+type GetTwoFactorStatusRequest struct {
+	Email string
+}
+
+type EnrollTOTPRequest struct {
+	Email string
+}
+
+type ConfirmTOTPRequest struct {
+	Code     string `json:"code" binding:"required"`
+	Email    string
+	ClientIp string
+}
+
+type DisableTOTPRequest struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required"` // totp or recovery code
+	Email    string
+	ClientIp string
+}
+
+type RegenerateRecoveryCodesRequest struct {
+	Code     string `json:"code" binding:"required"`
+	Email    string
+	ClientIp string
+}
+
+type AdminResetTwoFactorRequest struct {
+	UserId   int64 `uri:"userId" binding:"required"`
+	Email    string
+	ClientIp string
+}
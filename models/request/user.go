@@ -3,7 +3,7 @@ package request
 type CreateUserRequest struct {
 	Username string  `json:"username" binding:"required"`
 	Email    string  `json:"email" binding:"required,isEmail"`
-	Password string  `json:"password" binding:"required,min=6,max=10"`
+	Password string  `json:"password" binding:"required,max=128"`
 	Phone    *string `json:"phone"`
 }
 
@@ -25,3 +25,8 @@ type LoginUserRequest struct {
 type LogoutUserRequest struct {
 	RefreshToken string `json:"refreshToken" bindind:"required"`
 }
+
+type UnlockUserRequest struct {
+	UserId int64 `uri:"userId" binding:"required"`
+	Email  string
+}
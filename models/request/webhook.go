@@ -0,0 +1,60 @@
+package request
+
+type CreateIncomingWebhookRequest struct {
+	ChannelId int64  `uri:"channelId"`
+	Name      string `json:"name" binding:"required,max=64"`
+	Email     string
+}
+
+type GetIncomingWebhooksRequest struct {
+	ChannelId int64 `uri:"channelId" binding:"required"`
+	Email     string
+}
+
+type RevokeIncomingWebhookRequest struct {
+	ChannelId int64 `uri:"channelId" binding:"required"`
+	WebhookId int64 `uri:"webhookId" binding:"required"`
+	Email     string
+}
+
+type WebhookAttachment struct {
+	Title     string `json:"title,omitempty" binding:"max=256"`
+	TitleLink string `json:"titleLink,omitempty" binding:"omitempty,url"`
+	Text      string `json:"text,omitempty" binding:"max=4000"`
+	Color     string `json:"color,omitempty" binding:"omitempty,hexcolor"`
+	ImageUrl  string `json:"imageUrl,omitempty" binding:"omitempty,url"`
+}
+
+// PostIncomingWebhookRequest is the payload integrations send to the webhook
+// URL, authenticated by the token in the path.
+type PostIncomingWebhookRequest struct {
+	Token       string              `uri:"token"`
+	Text        string              `json:"text" binding:"required_without=Attachments,max=4000"`
+	Username    string              `json:"username" binding:"max=64"`
+	Attachments []WebhookAttachment `json:"attachments" binding:"max=10,dive"`
+}
+
+type CreateOutgoingWebhookRequest struct {
+	ChannelId    int64    `uri:"channelId"`
+	Url          string   `json:"url" binding:"required,http_url,max=2048"`
+	TriggerWords []string `json:"triggerWords" binding:"max=10,dive,min=1,max=32"`
+	Email        string
+}
+
+type GetOutgoingWebhooksRequest struct {
+	ChannelId int64 `uri:"channelId" binding:"required"`
+	Email     string
+}
+
+type RevokeOutgoingWebhookRequest struct {
+	ChannelId int64 `uri:"channelId" binding:"required"`
+	WebhookId int64 `uri:"webhookId" binding:"required"`
+	Email     string
+}
+
+type GetWebhookDeliveriesRequest struct {
+	ChannelId int64 `uri:"channelId" binding:"required"`
+	WebhookId int64 `uri:"webhookId" binding:"required"`
+	Limit     int32 `form:"limit" binding:"omitempty,min=1,max=100"`
+	Email     string
+}
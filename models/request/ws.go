@@ -4,11 +4,14 @@ type CreateChannelRequest struct {
 	Name string `json:"name" binding:"required"`
 }
 
+type GetChannelRequest struct {
+	ChannelId int64 `uri:"channelId" binding:"required"`
+}
+
 type JoinChatRequest struct {
 	UserId int64 `form:"userId"`
 }
 
 type JoinChannelRequest struct {
-	UserId    int64 `form:"userId"`
-	ChannelId int64 `uri:"channelId"`
+	ChannelId int64 `uri:"channelId" binding:"required"`
 }
@@ -0,0 +1,10 @@
+package response
+
+type ImportChannelResponse struct {
+	Channel      *ChannelResponse `json:"channel"`
+	Members      int64            `json:"members"`
+	Messages     int64            `json:"messages"`
+	Pins         int64            `json:"pins"`
+	SkippedPins  int64            `json:"skippedPins"`
+	CreatedUsers []string         `json:"createdUsers"`
+}
@@ -0,0 +1,32 @@
+package response
+
+import (
+	db "project/db/sqlc"
+	"time"
+)
+
+type ApiKeyResponse struct {
+	Id         int64      `json:"id"`
+	BotId      int64      `json:"botId"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expiresAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	// Key is only returned on creation.
+	Key string `json:"key,omitempty"`
+}
+
+func BuildApiKeyResponse(apiKey *db.ApiKey) *ApiKeyResponse {
+	return &ApiKeyResponse{
+		Id:         apiKey.ID,
+		BotId:      apiKey.UserID,
+		Name:       apiKey.Name,
+		Prefix:     apiKey.Prefix,
+		Scopes:     apiKey.Scopes,
+		ExpiresAt:  apiKey.ExpiresAt,
+		LastUsedAt: apiKey.LastUsedAt,
+		CreatedAt:  apiKey.CreatedAt,
+	}
+}
@@ -0,0 +1,49 @@
+package response
+
+import (
+	db "project/db/sqlc"
+	"time"
+)
+
+type ChannelResponse struct {
+	Id                int64     `json:"id"`
+	Name              string    `json:"name"`
+	Topic             *string   `json:"topic"`
+	PinCount          int64     `json:"pinCount"`
+	MessageTtlSeconds *int32    `json:"messageTtlSeconds"`
+	RetentionPolicy   string    `json:"retentionPolicy"`
+	RetentionValue    *int32    `json:"retentionValue"`
+	SlowModeSeconds   int32     `json:"slowModeSeconds"`
+	CreatedAt         time.Time `json:"createdAt"`
+}
+
+func BuildChannelResponse(channel *db.Channel, pinCount int64) *ChannelResponse {
+	return &ChannelResponse{
+		Id:                channel.ID,
+		Name:              channel.Name,
+		Topic:             channel.Topic,
+		PinCount:          pinCount,
+		MessageTtlSeconds: channel.MessageTtlSeconds,
+		RetentionPolicy:   channel.RetentionPolicy,
+		RetentionValue:    channel.RetentionValue,
+		SlowModeSeconds:   channel.SlowModeSeconds,
+		CreatedAt:         channel.CreatedAt,
+	}
+}
+
+type ChannelRetentionReport struct {
+	ChannelId         int64  `json:"channelId"`
+	Name              string `json:"name"`
+	Policy            string `json:"policy"`
+	Value             int32  `json:"value"`
+	Inherited         bool   `json:"inherited"`
+	PurgeableMessages int64  `json:"purgeableMessages"`
+}
+
+type RetentionReportResponse struct {
+	DefaultPolicy          string                   `json:"defaultPolicy"`
+	DefaultValue           int32                    `json:"defaultValue"`
+	TotalPurgeableMessages int64                    `json:"totalPurgeableMessages"`
+	Channels               []ChannelRetentionReport `json:"channels"`
+	GeneratedAt            time.Time                `json:"generatedAt"`
+}
@@ -0,0 +1,65 @@
+package response
+
+import (
+	db "project/db/sqlc"
+	"time"
+)
+
+type ModerationRuleResponse struct {
+	Id        int64     `json:"id"`
+	ChannelId int64     `json:"channelId"`
+	Kind      string    `json:"kind"`
+	Pattern   string    `json:"pattern"`
+	Action    string    `json:"action"`
+	CreatedBy int64     `json:"createdBy"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func BuildModerationRuleResponse(rule *db.ModerationRule) *ModerationRuleResponse {
+	return &ModerationRuleResponse{
+		Id:        rule.ID,
+		ChannelId: rule.ChannelID,
+		Kind:      rule.Kind,
+		Pattern:   rule.Pattern,
+		Action:    rule.Action,
+		CreatedBy: rule.CreatedBy,
+		CreatedAt: rule.CreatedAt,
+	}
+}
+
+type HeldMessageResponse struct {
+	Id         int64      `json:"id"`
+	ChannelId  int64      `json:"channelId"`
+	UserId     int64      `json:"userId"`
+	Username   string     `json:"username,omitempty"`
+	Content    string     `json:"content"`
+	IsBot      bool       `json:"isBot"`
+	Filter     string     `json:"filter"`
+	Reason     string     `json:"reason"`
+	Status     string     `json:"status"`
+	ReviewedBy *int64     `json:"reviewedBy"`
+	ReviewedAt *time.Time `json:"reviewedAt"`
+	MessageId  *int64     `json:"messageId"` // set once approved
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+func BuildHeldMessageResponse(held *db.HeldMessage, username string) *HeldMessageResponse {
+	if held.UsernameOverride != nil {
+		username = *held.UsernameOverride
+	}
+	return &HeldMessageResponse{
+		Id:         held.ID,
+		ChannelId:  held.ChannelID,
+		UserId:     held.UserID,
+		Username:   username,
+		Content:    held.Content,
+		IsBot:      held.IsBot,
+		Filter:     held.Filter,
+		Reason:     held.Reason,
+		Status:     held.Status,
+		ReviewedBy: held.ReviewedBy,
+		ReviewedAt: held.ReviewedAt,
+		MessageId:  held.MessageID,
+		CreatedAt:  held.CreatedAt,
+	}
+}
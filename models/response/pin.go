@@ -0,0 +1,74 @@
+package response
+
+import (
+	db "project/db/sqlc"
+	"time"
+)
+
+type PinResponse struct {
+	Id        int64     `json:"id"`
+	ChannelId int64     `json:"channelId"`
+	MessageId int64     `json:"messageId"`
+	Content   string    `json:"content"`
+	UserId    int64     `json:"userId"`
+	Username  string    `json:"username"`
+	PinnedBy  int64     `json:"pinnedBy"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func BuildPinResponse(pin *db.Pin, message *db.Message, author *db.User) *PinResponse {
+	return &PinResponse{
+		Id:        pin.ID,
+		ChannelId: pin.ChannelID,
+		MessageId: pin.MessageID,
+		Content:   message.Content,
+		UserId:    author.ID,
+		Username:  author.Username,
+		PinnedBy:  pin.PinnedBy,
+		CreatedAt: pin.CreatedAt,
+	}
+}
+
+func BuildPinResponseFromRow(row *db.GetPinsByChannelIdRow) *PinResponse {
+	return &PinResponse{
+		Id:        row.ID,
+		ChannelId: row.ChannelID,
+		MessageId: row.MessageID,
+		Content:   row.Content,
+		UserId:    row.UserID,
+		Username:  row.Username,
+		PinnedBy:  row.PinnedBy,
+		CreatedAt: row.CreatedAt,
+	}
+}
+
+type SavedItemResponse struct {
+	Id        int64     `json:"id"`
+	MessageId int64     `json:"messageId"`
+	ChannelId int64     `json:"channelId"`
+	Content   string    `json:"content"`
+	Username  string    `json:"username"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func BuildSavedItemResponse(savedItem *db.SavedItem, message *db.Message, author *db.User) *SavedItemResponse {
+	return &SavedItemResponse{
+		Id:        savedItem.ID,
+		MessageId: savedItem.MessageID,
+		ChannelId: message.ChannelID,
+		Content:   message.Content,
+		Username:  author.Username,
+		CreatedAt: savedItem.CreatedAt,
+	}
+}
+
+func BuildSavedItemResponseFromRow(row *db.GetSavedItemsByUserIdRow) *SavedItemResponse {
+	return &SavedItemResponse{
+		Id:        row.ID,
+		MessageId: row.MessageID,
+		ChannelId: row.ChannelID,
+		Content:   row.Content,
+		Username:  row.Username,
+		CreatedAt: row.CreatedAt,
+	}
+}
@@ -0,0 +1,78 @@
+package response
+
+import (
+	db "project/db/sqlc"
+	"time"
+)
+
+type PollOptionResponse struct {
+	Id     int64    `json:"id"`
+	Text   string   `json:"text"`
+	Votes  int64    `json:"votes"`
+	Voters []string `json:"voters,omitempty"` // never set on anonymous polls
+}
+
+type PollResponse struct {
+	Id             int64                `json:"id"`
+	MessageId      int64                `json:"messageId"`
+	ChannelId      int64                `json:"channelId"`
+	CreatedBy      int64                `json:"createdBy"`
+	Question       string               `json:"question"`
+	MultipleChoice bool                 `json:"multipleChoice"`
+	Anonymous      bool                 `json:"anonymous"`
+	Closed         bool                 `json:"closed"`
+	ClosesAt       *time.Time           `json:"closesAt"`
+	ClosedAt       *time.Time           `json:"closedAt"`
+	TotalVoters    int64                `json:"totalVoters"`
+	Options        []PollOptionResponse `json:"options"`
+	MyVotes        []int64              `json:"myVotes,omitempty"` // options picked by the requesting user
+	CreatedAt      time.Time            `json:"createdAt"`
+}
+
+// BuildPollResponse tallies votes per option. voters is ignored for anonymous
+// polls.
+func BuildPollResponse(poll *db.Poll, options []*db.PollOption, votes []*db.CountPollVotesRow, voters []*db.GetPollVotersRow, totalVoters int64) *PollResponse {
+	counts := make(map[int64]int64)
+	for _, vote := range votes {
+		counts[vote.OptionID] = vote.Votes
+	}
+
+	names := make(map[int64][]string)
+	if !poll.Anonymous {
+		for _, voter := range voters {
+			names[voter.OptionID] = append(names[voter.OptionID], voter.Username)
+		}
+	}
+
+	optionsResp := make([]PollOptionResponse, 0)
+	for _, option := range options {
+		optionsResp = append(optionsResp, PollOptionResponse{
+			Id:     option.ID,
+			Text:   option.Text,
+			Votes:  counts[option.ID],
+			Voters: names[option.ID],
+		})
+	}
+
+	return &PollResponse{
+		Id:             poll.ID,
+		MessageId:      poll.MessageID,
+		ChannelId:      poll.ChannelID,
+		CreatedBy:      poll.CreatedBy,
+		Question:       poll.Question,
+		MultipleChoice: poll.MultipleChoice,
+		Anonymous:      poll.Anonymous,
+		Closed:         IsPollClosed(poll),
+		ClosesAt:       poll.ClosesAt,
+		ClosedAt:       poll.ClosedAt,
+		TotalVoters:    totalVoters,
+		Options:        optionsResp,
+		CreatedAt:      poll.CreatedAt,
+	}
+}
+
+// IsPollClosed reports whether the poll stopped taking votes, either closed
+// explicitly or past its closing time.
+func IsPollClosed(poll *db.Poll) bool {
+	return poll.ClosedAt != nil || (poll.ClosesAt != nil && !time.Now().Before(*poll.ClosesAt))
+}
@@ -0,0 +1,44 @@
+package response
+
+import (
+	db "project/db/sqlc"
+	"time"
+)
+
+type ScheduledMessageResponse struct {
+	Id        int64     `json:"id"`
+	ChannelId int64     `json:"channelId"`
+	Content   string    `json:"content"`
+	SendAt    time.Time `json:"sendAt"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func BuildScheduledMessageResponse(scheduledMessage *db.ScheduledMessage) *ScheduledMessageResponse {
+	return &ScheduledMessageResponse{
+		Id:        scheduledMessage.ID,
+		ChannelId: scheduledMessage.ChannelID,
+		Content:   scheduledMessage.Content,
+		SendAt:    scheduledMessage.SendAt,
+		Status:    scheduledMessage.Status,
+		CreatedAt: scheduledMessage.CreatedAt,
+	}
+}
+
+type ReminderResponse struct {
+	Id        int64     `json:"id"`
+	MessageId int64     `json:"messageId"`
+	RemindAt  time.Time `json:"remindAt"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func BuildReminderResponse(reminder *db.Reminder) *ReminderResponse {
+	return &ReminderResponse{
+		Id:        reminder.ID,
+		MessageId: reminder.MessageID,
+		RemindAt:  reminder.RemindAt,
+		Status:    reminder.Status,
+		CreatedAt: reminder.CreatedAt,
+	}
+}
@@ -0,0 +1,32 @@
+package response
+
+import (
+	db "project/db/sqlc"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type SessionResponse struct {
+	Id        uuid.UUID `json:"id"`
+	UserAgent string    `json:"userAgent"`
+	ClientIp  string    `json:"clientIp"`
+	Current   bool      `json:"current"` // the session of the calling token
+	CreatedAt time.Time `json:"createdAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+func BuildSessionResponse(session *db.Session, currentSessionId uuid.UUID) *SessionResponse {
+	return &SessionResponse{
+		Id:        session.ID,
+		UserAgent: session.UserAgent,
+		ClientIp:  session.ClientIp,
+		Current:   session.ID == currentSessionId,
+		CreatedAt: session.CreatedAt,
+		ExpiresAt: session.ExpiresAt,
+	}
+}
+
+type RevokedSessionsResponse struct {
+	Revoked int `json:"revoked"`
+}
@@ -0,0 +1,37 @@
+package response
+
+import (
+	db "project/db/sqlc"
+	"time"
+)
+
+type SSOProviderResponse struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+}
+
+// AuthorizationResponse holds the url of the identity provider to send the
+// user to. The state in it comes back to the redirect url and must be
+// checked there against the one the client started with.
+type AuthorizationResponse struct {
+	AuthorizationURL string `json:"authorizationUrl"`
+	State            string `json:"state"`
+}
+
+type IdentityResponse struct {
+	Id          int64      `json:"id"`
+	Provider    string     `json:"provider"`
+	Email       string     `json:"email"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	LastLoginAt *time.Time `json:"lastLoginAt"`
+}
+
+func BuildIdentityResponse(identity *db.UserIdentity) *IdentityResponse {
+	return &IdentityResponse{
+		Id:          identity.ID,
+		Provider:    identity.Provider,
+		Email:       identity.Email,
+		CreatedAt:   identity.CreatedAt,
+		LastLoginAt: identity.LastLoginAt,
+	}
+}
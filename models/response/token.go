@@ -1,10 +1,17 @@
 package response
 
 import (
+	"project/jwtkeys"
 	"time"
 )
 
 type RenewAccessTokenResponse struct {
-	AccessToken          string    `json:"accessToke"`
-	AccessTokenExpiresAt time.Time `json:"expiesAt"`
+	AccessToken           string    `json:"accessToke"`
+	AccessTokenExpiresAt  time.Time `json:"expiesAt"`
+	RefreshToken          string    `json:"refreshToken"`
+	RefreshTokenExpiresAt time.Time `json:"refreshTokenExpiresAt"`
+}
+
+type JWKSResponse struct {
+	Keys []jwtkeys.JWK `json:"keys"`
 }
@@ -0,0 +1,29 @@
+package response
+
+import "time"
+
+type TOTPEnrollmentResponse struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"` // otpauth uri, shown as a QR code
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+type TwoFactorStatusResponse struct {
+	Enabled           bool       `json:"enabled"`
+	EnabledAt         *time.Time `json:"enabledAt"`
+	Required          bool       `json:"required"`
+	RecoveryCodesLeft int64      `json:"recoveryCodesLeft"`
+}
+
+// LoginChallengeResponse answers a login that needs a second factor. With
+// EnrollmentRequired the account must set up TOTP first, see
+// POST /login/2fa/enroll.
+type LoginChallengeResponse struct {
+	SecondFactorRequired bool      `json:"secondFactorRequired"`
+	EnrollmentRequired   bool      `json:"enrollmentRequired"`
+	ChallengeToken       string    `json:"challengeToken"`
+	ChallengeExpiresAt   time.Time `json:"challengeExpiresAt"`
+}
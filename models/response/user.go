@@ -1,6 +1,7 @@
 package response
 
 import (
+	"project/avatar"
 	db "project/db/sqlc"
 	"time"
 
@@ -8,23 +9,71 @@ import (
 )
 
 type UserResponse struct {
-	Id        int64     `json:"id"`
-	Username  string    `json:"username"`
-	Email     string    `json:"email"`
-	Phone     *string   `json:"phone"`
-	CreatedAt time.Time `json:"createdAt"`
+	Id          int64      `json:"id"`
+	Username    string     `json:"username"`
+	Email       string     `json:"email"`
+	Phone       *string    `json:"phone"`
+	DisplayName *string    `json:"displayName"`
+	Bio         *string    `json:"bio"`
+	TimeZone    *string    `json:"timeZone"`
+	AvatarURL   *string    `json:"avatarUrl"`
+	IsAdmin     bool       `json:"isAdmin"`
+	IsBot       bool       `json:"isBot"`
+	OwnerId     *int64     `json:"ownerId,omitempty"`
+	VerifiedAt  *time.Time `json:"verifiedAt"`
+	CreatedAt   time.Time  `json:"createdAt"`
 }
 
 func BuildUserResponse(user *db.User) *UserResponse {
 	return &UserResponse{
-		Id:        user.ID,
-		Username:  user.Username,
-		Email:     user.Email,
-		Phone:     user.Phone,
-		CreatedAt: user.CreatedAt,
+		Id:          user.ID,
+		Username:    user.Username,
+		Email:       user.Email,
+		Phone:       user.Phone,
+		DisplayName: user.DisplayName,
+		Bio:         user.Bio,
+		TimeZone:    user.TimeZone,
+		AvatarURL:   avatarURL(user),
+		IsAdmin:     user.IsAdmin,
+		IsBot:       user.IsBot,
+		OwnerId:     user.OwnerID,
+		VerifiedAt:  user.VerifiedAt,
+		CreatedAt:   user.CreatedAt,
 	}
 }
 
+// ProfileResponse is what other users see of a user, pushed to them when
+// it changes.
+type ProfileResponse struct {
+	Id          int64   `json:"id"`
+	Username    string  `json:"username"`
+	DisplayName *string `json:"displayName"`
+	Bio         *string `json:"bio"`
+	TimeZone    *string `json:"timeZone"`
+	AvatarURL   *string `json:"avatarUrl"`
+	IsBot       bool    `json:"isBot"`
+}
+
+func BuildProfileResponse(user *db.User) *ProfileResponse {
+	return &ProfileResponse{
+		Id:          user.ID,
+		Username:    user.Username,
+		DisplayName: user.DisplayName,
+		Bio:         user.Bio,
+		TimeZone:    user.TimeZone,
+		AvatarURL:   avatarURL(user),
+		IsBot:       user.IsBot,
+	}
+}
+
+func avatarURL(user *db.User) *string {
+	if user.AvatarKey == nil {
+		return nil
+	}
+	url := avatar.URL(*user.AvatarKey)
+	return &url
+}
+
 type LoginUserResponse struct {
 	SessionId             uuid.UUID     `json:"sessionId"`
 	AccessToken           string        `json:"accessToken"`
@@ -32,4 +81,8 @@ type LoginUserResponse struct {
 	RefreshToken          string        `json:"refreshToken"`
 	RefreshTokenExpiresAt time.Time     `json:"refreshTokenExpiresAt"`
 	User                  *UserResponse `json:"user"`
+	// recovery codes of a TOTP enrolled during the login, shown once
+	RecoveryCodes []string `json:"recoveryCodes,omitempty"`
+	// set instead of everything else when the login needs a second factor
+	Challenge *LoginChallengeResponse `json:"-"`
 }
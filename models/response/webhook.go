@@ -0,0 +1,78 @@
+package response
+
+import (
+	db "project/db/sqlc"
+	"time"
+)
+
+type IncomingWebhookResponse struct {
+	Id        int64     `json:"id"`
+	ChannelId int64     `json:"channelId"`
+	Name      string    `json:"name"`
+	CreatedBy int64     `json:"createdBy"`
+	CreatedAt time.Time `json:"createdAt"`
+	// Url carries the secret token and is only returned on creation.
+	Url string `json:"url,omitempty"`
+}
+
+func BuildIncomingWebhookResponse(webhook *db.IncomingWebhook) *IncomingWebhookResponse {
+	return &IncomingWebhookResponse{
+		Id:        webhook.ID,
+		ChannelId: webhook.ChannelID,
+		Name:      webhook.Name,
+		CreatedBy: webhook.CreatedBy,
+		CreatedAt: webhook.CreatedAt,
+	}
+}
+
+type PostIncomingWebhookResponse struct {
+	MessageId int64 `json:"messageId"`
+}
+
+type OutgoingWebhookResponse struct {
+	Id           int64     `json:"id"`
+	ChannelId    int64     `json:"channelId"`
+	Url          string    `json:"url"`
+	TriggerWords []string  `json:"triggerWords"`
+	CreatedBy    int64     `json:"createdBy"`
+	CreatedAt    time.Time `json:"createdAt"`
+	// Secret signs the deliveries and is only returned on creation.
+	Secret string `json:"secret,omitempty"`
+}
+
+func BuildOutgoingWebhookResponse(webhook *db.OutgoingWebhook) *OutgoingWebhookResponse {
+	return &OutgoingWebhookResponse{
+		Id:           webhook.ID,
+		ChannelId:    webhook.ChannelID,
+		Url:          webhook.Url,
+		TriggerWords: webhook.TriggerWords,
+		CreatedBy:    webhook.CreatedBy,
+		CreatedAt:    webhook.CreatedAt,
+	}
+}
+
+type WebhookDeliveryResponse struct {
+	Id         int64     `json:"id"`
+	OutboxId   int64     `json:"outboxId"`
+	MessageId  int64     `json:"messageId"`
+	Attempt    int32     `json:"attempt"`
+	StatusCode *int32    `json:"statusCode"`
+	Error      *string   `json:"error"`
+	DurationMs int32     `json:"durationMs"`
+	Status     string    `json:"status"` // current status of the outbox entry
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+func BuildWebhookDeliveryResponseFromRow(row *db.GetWebhookDeliveriesRow) *WebhookDeliveryResponse {
+	return &WebhookDeliveryResponse{
+		Id:         row.ID,
+		OutboxId:   row.OutboxID,
+		MessageId:  row.MessageID,
+		Attempt:    row.Attempt,
+		StatusCode: row.StatusCode,
+		Error:      row.Error,
+		DurationMs: row.DurationMs,
+		Status:     row.Status,
+		CreatedAt:  row.CreatedAt,
+	}
+}
@@ -1,16 +1,36 @@
 package models
 
 import (
-	"time"
-
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 )
 
-// TODO: add type to claims to differentiate between refresh token and refresh token
+// JWTClaims are the claims of access and refresh tokens. The registered
+// claims carry the user id as subject and a unique id per token (jti); both
+// tokens of a login share the session id.
 type JWTClaims struct {
-	Id       uuid.UUID
-	Email    string
-	IssuedAt time.Time
+	Type      string    `json:"type"`
+	SessionId uuid.UUID `json:"sid"`
+	Email     string    `json:"email"`
 	jwt.RegisteredClaims
+	// UserId is parsed from the subject.
+	UserId int64 `json:"-"`
+	// ApiKeyId and Scopes are set when the request authenticated with an api
+	// key instead of a jwt; they are never part of a signed token.
+	ApiKeyId int64    `json:"-"`
+	Scopes   []string `json:"-"`
+}
+
+// HasScope reports whether the credentials grant scope. JWTs, issued to
+// users who logged in, grant every scope.
+func (c *JWTClaims) HasScope(scope string) bool {
+	if c.ApiKeyId == 0 {
+		return true
+	}
+	for _, s := range c.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,172 @@
+package moderation
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"project/markdown"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// BlocklistFilter applies the word and regex rules of the channel.
+type BlocklistFilter struct {
+	rules *RuleCache
+}
+
+func NewBlocklistFilter(rules *RuleCache) *BlocklistFilter {
+	return &BlocklistFilter{rules}
+}
+
+func (f *BlocklistFilter) Name() string {
+	return "blocklist"
+}
+
+// Check implements Filter.
+func (f *BlocklistFilter) Check(ctx context.Context, candidate *Candidate) (Verdict, error) {
+	rules, err := f.rules.get(ctx, candidate.ChannelId)
+	if err != nil {
+		return allow, err
+	}
+
+	verdict := allow
+	for _, m := range rules.matchers {
+		if !m.expr.MatchString(candidate.Content) {
+			continue
+		}
+		// the pattern is not echoed back, that would help working around it
+		verdict.Action = stricter(verdict.Action, m.action)
+		verdict.Reason = "message contains blocked content"
+		if verdict.Action == ActionReject {
+			break
+		}
+	}
+	return verdict, nil
+}
+
+// LinkFilter applies the link allow and deny lists of the channel. Links
+// listed as denied get the action of the deny rule; when the channel has an
+// allow list, links to any other domain get the strictest action among the
+// allow rules.
+type LinkFilter struct {
+	rules *RuleCache
+}
+
+func NewLinkFilter(rules *RuleCache) *LinkFilter {
+	return &LinkFilter{rules}
+}
+
+func (f *LinkFilter) Name() string {
+	return "links"
+}
+
+// Check implements Filter.
+func (f *LinkFilter) Check(ctx context.Context, candidate *Candidate) (Verdict, error) {
+	hosts := linkHosts(candidate.Spans)
+	if len(hosts) == 0 {
+		return allow, nil
+	}
+
+	rules, err := f.rules.get(ctx, candidate.ChannelId)
+	if err != nil {
+		return allow, err
+	}
+
+	verdict := allow
+	for _, host := range hosts {
+		for _, rule := range rules.denied {
+			if matchesDomain(host, rule.domain) {
+				verdict.Action = stricter(verdict.Action, rule.action)
+				verdict.Reason = fmt.Sprintf("links to %s are not allowed in this channel", host)
+			}
+		}
+
+		if len(rules.allowed) == 0 {
+			continue
+		}
+		action := ActionAllow
+		for _, rule := range rules.allowed {
+			if matchesDomain(host, rule.domain) {
+				action = ActionAllow
+				break
+			}
+			action = stricter(action, rule.action)
+		}
+		if action != ActionAllow {
+			verdict.Action = stricter(verdict.Action, action)
+			verdict.Reason = fmt.Sprintf("links to %s are not allowed in this channel", host)
+		}
+	}
+	return verdict, nil
+}
+
+// linkHosts returns the hosts of the link spans, mailto links excluded.
+func linkHosts(spans []markdown.Span) []string {
+	hosts := make([]string, 0)
+	for _, span := range spans {
+		if span.Type != markdown.SpanLink {
+			continue
+		}
+		u, err := url.Parse(span.URL)
+		if err != nil || len(u.Hostname()) == 0 {
+			continue
+		}
+		hosts = append(hosts, strings.TrimSuffix(strings.ToLower(u.Hostname()), "."))
+	}
+	return hosts
+}
+
+const spamKeyPrefix = "spam:"
+
+// SpamFilter catches a user posting the same message over and over. Posts
+// are counted in redis, so repeats are caught whichever node they reach. The
+// count of a message starts over once window passes without it.
+type SpamFilter struct {
+	redisClient *redis.Client
+	window      time.Duration
+	maxRepeats  int64
+	action      string
+}
+
+func NewSpamFilter(redisClient *redis.Client, window time.Duration, maxRepeats int64, action string) *SpamFilter {
+	return &SpamFilter{
+		redisClient: redisClient,
+		window:      window,
+		maxRepeats:  maxRepeats,
+		action:      action,
+	}
+}
+
+func (f *SpamFilter) Name() string {
+	return "spam"
+}
+
+// Check implements Filter. Bots are exempt, repeating themselves is what
+// alerting integrations do.
+func (f *SpamFilter) Check(ctx context.Context, candidate *Candidate) (Verdict, error) {
+	if candidate.IsBot || f.maxRepeats <= 0 {
+		return allow, nil
+	}
+
+	// the same message in other words still counts: case and spacing are
+	// ignored
+	normalized := strings.ToLower(strings.Join(strings.Fields(candidate.Content), " "))
+	sum := sha256.Sum256([]byte(normalized))
+	key := fmt.Sprintf("%s%d:%d:%s", spamKeyPrefix, candidate.ChannelId, candidate.UserId, hex.EncodeToString(sum[:16]))
+
+	pipe := f.redisClient.TxPipeline()
+	count := pipe.Incr(ctx, key)
+	pipe.Expire(ctx, key, f.window)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return allow, err
+	}
+
+	if count.Val() <= f.maxRepeats {
+		return allow, nil
+	}
+	return Verdict{Action: f.action, Reason: "message was posted too many times"}, nil
+}
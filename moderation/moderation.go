@@ -0,0 +1,103 @@
+// Package moderation decides what happens to a message before it is stored
+// and broadcast. Every message goes through a Chain of filters; each filter
+// allows it, rejects it with a reason, or holds it for a channel admin to
+// review.
+//
+// The built-in filters are per-channel word and regex blocklists, per-channel
+// link allow and deny lists, and repeated-message spam detection. Custom
+// filters implement Filter and are added with Chain.Use.
+package moderation
+
+import (
+	"context"
+	"project/logger"
+	"project/markdown"
+)
+
+// actions, strictest last
+const (
+	ActionAllow  = "allow"
+	ActionHold   = "hold"
+	ActionReject = "reject"
+)
+
+// Candidate is a message waiting for a verdict. Content is normalized and
+// Spans is its parsed markdown.
+type Candidate struct {
+	ChannelId int64
+	UserId    int64
+	Username  string
+	Content   string
+	Spans     []markdown.Span
+	IsBot     bool
+}
+
+type Verdict struct {
+	Action string
+	Filter string // name of the filter that decided
+	Reason string // shown to the author and to reviewers
+}
+
+var allow = Verdict{Action: ActionAllow}
+
+// Filter judges candidates. Filters must be safe for concurrent use, they run
+// on the reader goroutine of every connection.
+type Filter interface {
+	Name() string
+	Check(ctx context.Context, candidate *Candidate) (Verdict, error)
+}
+
+// FilterFunc adapts a function to Filter.
+type FilterFunc struct {
+	FilterName string
+	Fn         func(ctx context.Context, candidate *Candidate) (Verdict, error)
+}
+
+func (f FilterFunc) Name() string {
+	return f.FilterName
+}
+
+func (f FilterFunc) Check(ctx context.Context, candidate *Candidate) (Verdict, error) {
+	return f.Fn(ctx, candidate)
+}
+
+type Chain struct {
+	filters []Filter
+}
+
+func NewChain(filters ...Filter) *Chain {
+	return &Chain{filters: filters}
+}
+
+// Use appends a filter to the chain. It must be called before clients
+// connect.
+func (c *Chain) Use(filter Filter) {
+	c.filters = append(c.filters, filter)
+}
+
+// Check runs the filters in order. The first rejection wins; a hold is kept
+// while the remaining filters get a chance to reject. A filter that fails
+// allows the message, moderation being down should not take chat down with
+// it.
+func (c *Chain) Check(ctx context.Context, candidate *Candidate) Verdict {
+	verdict := allow
+	for _, filter := range c.filters {
+		v, err := filter.Check(ctx, candidate)
+		if err != nil {
+			logger.Error(ctx, "Check :: moderation filter failed", logger.Field("filter", filter.Name()), logger.Field("error", err.Error()))
+			continue
+		}
+
+		switch v.Action {
+		case ActionReject:
+			v.Filter = filter.Name()
+			return v
+		case ActionHold:
+			if verdict.Action == ActionAllow {
+				verdict = v
+				verdict.Filter = filter.Name()
+			}
+		}
+	}
+	return verdict
+}
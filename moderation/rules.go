@@ -0,0 +1,168 @@
+package moderation
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"project/constants"
+	db "project/db/sqlc"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// rule kinds
+const (
+	RuleWord      = "word"       // a word or phrase, matched case-insensitively on word boundaries
+	RuleRegex     = "regex"      // an RE2 expression matched against the content
+	RuleLinkAllow = "link_allow" // a domain; links elsewhere get the rule action
+	RuleLinkDeny  = "link_deny"  // a domain; links there get the rule action
+)
+
+const maxPatternLength = 256
+
+// ValidateRule checks a rule before it is stored and returns its pattern in
+// canonical form: words are lower cased, domains reduced to their host.
+func ValidateRule(kind string, pattern string, action string) (string, error) {
+	if action != ActionReject && action != ActionHold {
+		return "", fmt.Errorf("%w: action must be %s or %s", constants.ErrInvalidModerationRule, ActionReject, ActionHold)
+	}
+
+	pattern = strings.TrimSpace(pattern)
+	if len(pattern) == 0 || len(pattern) > maxPatternLength {
+		return "", fmt.Errorf("%w: pattern must be 1 to %d characters", constants.ErrInvalidModerationRule, maxPatternLength)
+	}
+
+	switch kind {
+	case RuleWord:
+		return strings.ToLower(pattern), nil
+	case RuleRegex:
+		if _, err := regexp.Compile(pattern); err != nil {
+			return "", fmt.Errorf("%w: %s", constants.ErrInvalidModerationRule, err.Error())
+		}
+		return pattern, nil
+	case RuleLinkAllow, RuleLinkDeny:
+		domain := normalizeDomain(pattern)
+		if len(domain) == 0 || strings.ContainsAny(domain, "/?#@ ") {
+			return "", fmt.Errorf("%w: %q is not a domain", constants.ErrInvalidModerationRule, pattern)
+		}
+		return domain, nil
+	default:
+		return "", fmt.Errorf("%w: unknown kind %q", constants.ErrInvalidModerationRule, kind)
+	}
+}
+
+// normalizeDomain accepts example.com as well as https://example.com/path.
+func normalizeDomain(pattern string) string {
+	if strings.Contains(pattern, "://") {
+		u, err := url.Parse(pattern)
+		if err != nil {
+			return ""
+		}
+		pattern = u.Hostname()
+	}
+	return strings.TrimSuffix(strings.ToLower(pattern), ".")
+}
+
+type matcher struct {
+	expr   *regexp.Regexp
+	action string
+	source string
+}
+
+type domainRule struct {
+	domain string
+	action string
+}
+
+// channelRules are the compiled rules of a channel.
+type channelRules struct {
+	matchers  []matcher
+	allowed   []domainRule
+	denied    []domainRule
+	expiresAt time.Time
+}
+
+// RuleCache keeps the compiled rules of recently active channels for ttl, so
+// messages do not cost a query each. Rule changes on this node take effect
+// immediately, on other nodes within ttl.
+type RuleCache struct {
+	repo  db.Repository
+	ttl   time.Duration
+	mu    sync.Mutex
+	rules map[int64]*channelRules
+}
+
+func NewRuleCache(repo db.Repository, ttl time.Duration) *RuleCache {
+	return &RuleCache{
+		repo:  repo,
+		ttl:   ttl,
+		rules: make(map[int64]*channelRules),
+	}
+}
+
+// Invalidate drops the cached rules of a channel.
+func (c *RuleCache) Invalidate(channelId int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.rules, channelId)
+}
+
+func (c *RuleCache) get(ctx context.Context, channelId int64) (*channelRules, error) {
+	c.mu.Lock()
+	cached, ok := c.rules[channelId]
+	c.mu.Unlock()
+	if ok && time.Now().Before(cached.expiresAt) {
+		return cached, nil
+	}
+
+	rows, err := c.repo.GetModerationRulesByChannelId(ctx, channelId)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := &channelRules{expiresAt: time.Now().Add(c.ttl)}
+	for _, row := range rows {
+		switch row.Kind {
+		case RuleWord:
+			rules.matchers = append(rules.matchers, matcher{
+				expr:   regexp.MustCompile(`(?i)(^|[^\pL\pN_])` + regexp.QuoteMeta(row.Pattern) + `($|[^\pL\pN_])`),
+				action: row.Action,
+				source: row.Pattern,
+			})
+		case RuleRegex:
+			// validated when stored
+			expr, err := regexp.Compile(row.Pattern)
+			if err != nil {
+				continue
+			}
+			rules.matchers = append(rules.matchers, matcher{expr: expr, action: row.Action, source: row.Pattern})
+		case RuleLinkAllow:
+			rules.allowed = append(rules.allowed, domainRule{domain: row.Pattern, action: row.Action})
+		case RuleLinkDeny:
+			rules.denied = append(rules.denied, domainRule{domain: row.Pattern, action: row.Action})
+		}
+	}
+
+	c.mu.Lock()
+	c.rules[channelId] = rules
+	c.mu.Unlock()
+	return rules, nil
+}
+
+// matchesDomain reports whether host is domain or one of its subdomains.
+func matchesDomain(host string, domain string) bool {
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
+
+// stricter returns the stricter of two actions.
+func stricter(a string, b string) string {
+	if a == ActionReject || b == ActionReject {
+		return ActionReject
+	}
+	if a == ActionHold || b == ActionHold {
+		return ActionHold
+	}
+	return ActionAllow
+}
@@ -0,0 +1,250 @@
+package oidc
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"project/config"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	discoveryPath = "/.well-known/openid-configuration"
+
+	// bounds the responses read from providers
+	maxResponseSize = 1 << 20
+
+	// tolerated clock difference with the provider
+	leeway = time.Minute
+)
+
+// signing algorithms accepted for ID tokens, never "none" nor HMAC
+var idTokenMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+
+type metadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type idTokenClaims struct {
+	Nonce             string       `json:"nonce"`
+	AuthorizedParty   string       `json:"azp"`
+	Email             string       `json:"email"`
+	EmailVerified     flexibleBool `json:"email_verified"`
+	Name              string       `json:"name"`
+	PreferredUsername string       `json:"preferred_username"`
+	jwt.RegisteredClaims
+}
+
+// Provider is a configured identity provider. Its metadata is discovered on
+// first use and kept; its keys are refetched when a token names an unknown
+// one.
+type Provider struct {
+	cfg    config.OIDCProviderConfig
+	client *http.Client
+
+	mu       sync.Mutex
+	metadata *metadata
+	keys     *keySet
+}
+
+func NewProvider(cfg config.OIDCProviderConfig, client *http.Client) *Provider {
+	return &Provider{cfg: cfg, client: client}
+}
+
+func (p *Provider) Config() config.OIDCProviderConfig {
+	return p.cfg
+}
+
+// AuthCodeURL returns the url of the provider to send the user to.
+func (p *Provider) AuthCodeURL(ctx context.Context, state string, nonce string, codeVerifier string) (string, error) {
+	meta, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	scopes := append([]string{"openid"}, p.cfg.Scopes...)
+	query := url.Values{}
+	query.Set("response_type", "code")
+	query.Set("client_id", p.cfg.ClientID)
+	query.Set("redirect_uri", p.cfg.RedirectURL)
+	query.Set("scope", strings.Join(dedupe(scopes), " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", CodeChallenge(codeVerifier))
+	query.Set("code_challenge_method", "S256")
+
+	separator := "?"
+	if strings.Contains(meta.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return meta.AuthorizationEndpoint + separator + query.Encode(), nil
+}
+
+// Authenticate exchanges the code of a login for its ID token and returns
+// the verified claims.
+func (p *Provider) Authenticate(ctx context.Context, code string, codeVerifier string, nonce string) (*Claims, error) {
+	rawIDToken, err := p.exchange(ctx, code, codeVerifier)
+	if err != nil {
+		return nil, err
+	}
+	return p.verify(ctx, rawIDToken, nonce)
+}
+
+func (p *Provider) exchange(ctx context.Context, code string, codeVerifier string) (string, error) {
+	meta, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.cfg.RedirectURL)
+	form.Set("code_verifier", codeVerifier)
+	form.Set("client_id", p.cfg.ClientID)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, meta.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	// public clients authenticate with PKCE alone
+	if len(p.cfg.ClientSecret) > 0 {
+		req.SetBasicAuth(url.QueryEscape(p.cfg.ClientID), url.QueryEscape(p.cfg.ClientSecret))
+	}
+
+	var token struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	status, err := p.do(req, &token)
+	if err != nil {
+		return "", err
+	}
+	if status != http.StatusOK || len(token.Error) > 0 {
+		return "", fmt.Errorf("token endpoint answered %d: %s %s", status, token.Error, token.ErrorDescription)
+	}
+	if len(token.IDToken) == 0 {
+		return "", errors.New("token response has no id_token")
+	}
+	return token.IDToken, nil
+}
+
+func (p *Provider) verify(ctx context.Context, rawIDToken string, nonce string) (*Claims, error) {
+	meta, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := &idTokenClaims{}
+	_, err = jwt.ParseWithClaims(rawIDToken, claims, func(token *jwt.Token) (any, error) {
+		kid, _ := token.Header["kid"].(string)
+		return p.key(ctx, meta, kid, token.Method.Alg())
+	},
+		jwt.WithValidMethods(idTokenMethods),
+		jwt.WithIssuer(meta.Issuer),
+		jwt.WithAudience(p.cfg.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(leeway),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id token: %w", err)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(claims.Nonce), []byte(nonce)) != 1 {
+		return nil, errors.New("id token nonce does not match")
+	}
+	// a token meant for several clients must name us as the one it was issued to
+	if len(claims.Audience) > 1 && claims.AuthorizedParty != p.cfg.ClientID {
+		return nil, errors.New("id token was issued to another client")
+	}
+	if len(claims.Subject) == 0 {
+		return nil, errors.New("id token has no subject")
+	}
+
+	return &Claims{
+		Subject:           claims.Subject,
+		Email:             claims.Email,
+		EmailVerified:     bool(claims.EmailVerified),
+		Name:              claims.Name,
+		PreferredUsername: claims.PreferredUsername,
+	}, nil
+}
+
+func (p *Provider) discover(ctx context.Context) (*metadata, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.metadata != nil {
+		return p.metadata, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(p.cfg.Issuer, "/")+discoveryPath, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	meta := &metadata{}
+	status, err := p.do(req, meta)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("discovery answered %d", status)
+	}
+	// the issuer must be the configured one, or tokens of another would pass
+	if meta.Issuer != p.cfg.Issuer {
+		return nil, fmt.Errorf("discovered issuer %q does not match %q", meta.Issuer, p.cfg.Issuer)
+	}
+	if len(meta.AuthorizationEndpoint) == 0 || len(meta.TokenEndpoint) == 0 || len(meta.JWKSURI) == 0 {
+		return nil, errors.New("discovery document is missing endpoints")
+	}
+
+	p.metadata = meta
+	return meta, nil
+}
+
+// do sends req and decodes the JSON response into v, whatever its status.
+func (p *Provider) do(req *http.Request, v any) (int, error) {
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return 0, err
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return resp.StatusCode, fmt.Errorf("%s answered %d with invalid JSON: %w", req.URL.Host, resp.StatusCode, err)
+	}
+	return resp.StatusCode, nil
+}
+
+func dedupe(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	unique := make([]string, 0, len(values))
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			unique = append(unique, value)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,110 @@
+// Package password hashes passwords with Argon2id and decides which
+// passwords are accepted.
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"project/config"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var ErrMismatch = errors.New("password does not match")
+
+// params are the Argon2id parameters of a hash, as they appear in its PHC
+// string.
+type params struct {
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+	saltLength  uint32
+	keyLength   uint32
+}
+
+// Hash returns the PHC string of password hashed with the configured
+// parameters, e.g. $argon2id$v=19$m=65536,t=3,p=2$<salt>$<key>.
+func (p *Policy) Hash(password string) (string, error) {
+	salt := make([]byte, p.params.saltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, p.params.iterations, p.params.memory, p.params.parallelism, p.params.keyLength)
+
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version,
+		p.params.memory, p.params.iterations, p.params.parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// Verify checks password against a hash made by Hash or by bcrypt, which
+// stored passwords before. rehash tells that the password matched but the
+// hash should be replaced with a new one: it is bcrypt or was made with
+// other parameters. Hashes of neither kind, like the unusable hash of bots,
+// never match.
+func (p *Policy) Verify(hash string, password string) (rehash bool, err error) {
+	if strings.HasPrefix(hash, "$2") {
+		if bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) != nil {
+			return false, ErrMismatch
+		}
+		return true, nil
+	}
+
+	hashParams, salt, key, err := decode(hash)
+	if err != nil {
+		return false, ErrMismatch
+	}
+	derived := argon2.IDKey([]byte(password), salt, hashParams.iterations, hashParams.memory, hashParams.parallelism, hashParams.keyLength)
+	if subtle.ConstantTimeCompare(derived, key) != 1 {
+		return false, ErrMismatch
+	}
+	return hashParams != p.params, nil
+}
+
+func decode(hash string) (params, []byte, []byte, error) {
+	var hashParams params
+	// "", "argon2id", "v=19", "m=..,t=..,p=..", salt, key
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[0] != "" || parts[1] != "argon2id" {
+		return hashParams, nil, nil, errors.New("not an argon2id hash")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return hashParams, nil, nil, errors.New("unsupported argon2 version")
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &hashParams.memory, &hashParams.iterations, &hashParams.parallelism); err != nil {
+		return hashParams, nil, nil, fmt.Errorf("invalid argon2 parameters: %w", err)
+	}
+	if hashParams.iterations == 0 || hashParams.parallelism == 0 {
+		return hashParams, nil, nil, errors.New("invalid argon2 parameters")
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return hashParams, nil, nil, fmt.Errorf("invalid salt: %w", err)
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return hashParams, nil, nil, errors.New("invalid key")
+	}
+	hashParams.saltLength = uint32(len(salt))
+	hashParams.keyLength = uint32(len(key))
+
+	return hashParams, salt, key, nil
+}
+
+func newParams(cfg config.Argon2Config) (params, error) {
+	hashParams := params{cfg.Memory, cfg.Iterations, cfg.Parallelism, cfg.SaltLength, cfg.KeyLength}
+	if hashParams.iterations == 0 || hashParams.parallelism == 0 || hashParams.memory < 8*uint32(hashParams.parallelism) {
+		return hashParams, fmt.Errorf("argon2 needs iterations and parallelism of at least 1 and 8 KiB of memory per lane")
+	}
+	if hashParams.saltLength < 16 || hashParams.keyLength < 16 {
+		return hashParams, fmt.Errorf("argon2 salt and key must be at least 16 bytes")
+	}
+	return hashParams, nil
+}
@@ -0,0 +1,126 @@
+package password
+
+import (
+	"errors"
+	"project/config"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// testArgon2 keeps the tests fast, real deployments use far more memory.
+var testArgon2 = config.Argon2Config{Memory: 64, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+
+func newTestPolicy(t *testing.T, argon2 config.Argon2Config) *Policy {
+	t.Helper()
+
+	policy, err := LoadPolicy(config.PasswordConfig{Argon2: argon2, MinLength: 8, MaxLength: 64})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return policy
+}
+
+func TestHashVerify(t *testing.T) {
+	policy := newTestPolicy(t, testArgon2)
+
+	hash, err := policy.Hash("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=64,t=1,p=1$") {
+		t.Errorf("Hash = %s, want a PHC string with the configured parameters", hash)
+	}
+
+	hashParams, salt, key, err := decode(hash)
+	if err != nil {
+		t.Fatalf("decode(%s): %v", hash, err)
+	}
+	if hashParams != policy.params || len(salt) != 16 || len(key) != 32 {
+		t.Errorf("decode(%s) = %+v with %d byte salt and %d byte key", hash, hashParams, len(salt), len(key))
+	}
+
+	rehash, err := policy.Verify(hash, "correct horse")
+	if err != nil || rehash {
+		t.Errorf("Verify with the right password = %v, %v, want false, nil", rehash, err)
+	}
+	if _, err := policy.Verify(hash, "correct horsf"); !errors.Is(err, ErrMismatch) {
+		t.Errorf("Verify with a wrong password = %v, want ErrMismatch", err)
+	}
+
+	other, err := policy.Hash("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other == hash {
+		t.Error("two hashes of a password share their salt")
+	}
+}
+
+func TestVerifyRehashesOutdatedParameters(t *testing.T) {
+	old := newTestPolicy(t, testArgon2)
+	hash, err := old.Hash("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	updates := map[string]func(*config.Argon2Config){
+		"memory":      func(c *config.Argon2Config) { c.Memory = 128 },
+		"iterations":  func(c *config.Argon2Config) { c.Iterations = 2 },
+		"parallelism": func(c *config.Argon2Config) { c.Parallelism = 2 },
+		"salt length": func(c *config.Argon2Config) { c.SaltLength = 24 },
+		"key length":  func(c *config.Argon2Config) { c.KeyLength = 64 },
+	}
+	for name, update := range updates {
+		argon2 := testArgon2
+		update(&argon2)
+		policy := newTestPolicy(t, argon2)
+
+		rehash, err := policy.Verify(hash, "correct horse")
+		if err != nil || !rehash {
+			t.Errorf("%s changed: Verify = %v, %v, want true, nil", name, rehash, err)
+		}
+		if _, err := policy.Verify(hash, "wrong horse"); !errors.Is(err, ErrMismatch) {
+			t.Errorf("%s changed: Verify with a wrong password = %v, want ErrMismatch", name, err)
+		}
+	}
+}
+
+func TestVerifyBcrypt(t *testing.T) {
+	policy := newTestPolicy(t, testArgon2)
+	hash, err := bcrypt.GenerateFromPassword([]byte("correct horse"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rehash, err := policy.Verify(string(hash), "correct horse")
+	if err != nil || !rehash {
+		t.Errorf("Verify of a bcrypt hash = %v, %v, want true, nil", rehash, err)
+	}
+	if _, err := policy.Verify(string(hash), "wrong horse"); !errors.Is(err, ErrMismatch) {
+		t.Errorf("Verify of a bcrypt hash with a wrong password = %v, want ErrMismatch", err)
+	}
+}
+
+func TestVerifyMalformedHashes(t *testing.T) {
+	policy := newTestPolicy(t, testArgon2)
+	hash, err := policy.Hash("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, malformed := range []string{
+		"",
+		"!",
+		"$argon2i$v=19$m=64,t=1,p=1$c2FsdHNhbHRzYWx0c2FsdA$a2V5",
+		"$argon2id$v=16$m=64,t=1,p=1$c2FsdHNhbHRzYWx0c2FsdA$a2V5",
+		"$argon2id$v=19$m=64,t=0,p=1$c2FsdHNhbHRzYWx0c2FsdA$a2V5",
+		"$argon2id$v=19$m=64,t=1,p=1$c2FsdHNhbHRzYWx0c2FsdA$",
+		strings.TrimSuffix(hash, hash[strings.LastIndexByte(hash, '$'):]),
+	} {
+		if _, err := policy.Verify(malformed, "correct horse"); !errors.Is(err, ErrMismatch) {
+			t.Errorf("Verify(%q) = %v, want ErrMismatch", malformed, err)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package password
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"project/config"
+	"project/constants"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxLength caps the configurable maximum length, hashing is slow enough
+// without megabyte passwords.
+const MaxLength = 128
+
+// Policy hashes passwords and decides which are accepted.
+type Policy struct {
+	params    params
+	minLength int
+	maxLength int
+	// SHA-1 digests of the breached passwords
+	breached map[[sha1.Size]byte]struct{}
+}
+
+// LoadPolicy checks cfg and reads its breached list.
+func LoadPolicy(cfg config.PasswordConfig) (*Policy, error) {
+	hashParams, err := newParams(cfg.Argon2)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.MinLength < 1 || cfg.MaxLength < cfg.MinLength || cfg.MaxLength > MaxLength {
+		return nil, fmt.Errorf("password lengths must satisfy 1 <= minLength <= maxLength <= %d", MaxLength)
+	}
+
+	policy := &Policy{params: hashParams, minLength: cfg.MinLength, maxLength: cfg.MaxLength}
+	if len(cfg.BreachedList) > 0 {
+		policy.breached, err = loadBreachedList(cfg.BreachedList)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return policy, nil
+}
+
+// Check returns an error wrapping constants.ErrWeakPassword that tells what
+// is wrong with password, if anything. Lengths count characters, not bytes.
+func (p *Policy) Check(password string) error {
+	length := utf8.RuneCountInString(password)
+	if length < p.minLength {
+		return fmt.Errorf("%w: must be at least %d characters", constants.ErrWeakPassword, p.minLength)
+	}
+	if length > p.maxLength {
+		return fmt.Errorf("%w: must be at most %d characters", constants.ErrWeakPassword, p.maxLength)
+	}
+	if _, ok := p.breached[sha1.Sum([]byte(password))]; ok {
+		return fmt.Errorf("%w: it appears in a known data breach, choose another", constants.ErrWeakPassword)
+	}
+	return nil
+}
+
+// loadBreachedList reads a file with a password per line. Lines of 40 hex
+// digits, optionally followed by :count as in the Pwned Passwords
+// downloads, are SHA-1 digests; any other line is the password itself.
+func loadBreachedList(path string) (map[[sha1.Size]byte]struct{}, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed opening breached password list: %w", err)
+	}
+	defer file.Close()
+
+	breached := make(map[[sha1.Size]byte]struct{})
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(line) == 0 {
+			continue
+		}
+
+		digest, count, _ := strings.Cut(line, ":")
+		var sum [sha1.Size]byte
+		if len(digest) == 2*sha1.Size && isDigits(count) {
+			if _, err := hex.Decode(sum[:], []byte(digest)); err == nil {
+				breached[sum] = struct{}{}
+				continue
+			}
+		}
+		breached[sha1.Sum([]byte(line))] = struct{}{}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed reading breached password list: %w", err)
+	}
+	return breached, nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,85 @@
+package password
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"project/config"
+	"project/constants"
+	"strings"
+	"testing"
+)
+
+func TestLoadPolicyLengths(t *testing.T) {
+	tests := []struct {
+		min, max int
+		ok       bool
+	}{
+		{8, 64, true},
+		{1, 1, true},
+		{8, MaxLength, true},
+		{0, 64, false},
+		{8, 7, false},
+		{8, MaxLength + 1, false},
+	}
+
+	for _, test := range tests {
+		_, err := LoadPolicy(config.PasswordConfig{Argon2: testArgon2, MinLength: test.min, MaxLength: test.max})
+		if (err == nil) != test.ok {
+			t.Errorf("LoadPolicy with lengths %d to %d: %v", test.min, test.max, err)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	digest := sha1.Sum([]byte("hunter2hunter2"))
+	list := strings.Join([]string{
+		"password123",
+		strings.ToUpper(hex.EncodeToString(digest[:])) + ":42",
+		"",
+		"letmein99\r",
+	}, "\n")
+	path := filepath.Join(t.TempDir(), "breached.txt")
+	if err := os.WriteFile(path, []byte(list), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	policy, err := LoadPolicy(config.PasswordConfig{Argon2: testArgon2, MinLength: 8, MaxLength: 16, BreachedList: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		password string
+		ok       bool
+	}{
+		{"correct horse", true},
+		{"exactly8", true},
+		{"ééééééééééééééé", true}, // 15 characters, 30 bytes
+		{"short", false},
+		{"seventeen chars!!", false},
+		{"password123", false},
+		{"hunter2hunter2", false},
+		{"letmein99", false},
+		{"Password123", true},
+	}
+
+	for _, test := range tests {
+		err := policy.Check(test.password)
+		if test.ok && err != nil {
+			t.Errorf("Check(%q) = %v, want nil", test.password, err)
+		}
+		if !test.ok && !errors.Is(err, constants.ErrWeakPassword) {
+			t.Errorf("Check(%q) = %v, want ErrWeakPassword", test.password, err)
+		}
+	}
+}
+
+func TestLoadPolicyMissingBreachedList(t *testing.T) {
+	_, err := LoadPolicy(config.PasswordConfig{Argon2: testArgon2, MinLength: 8, MaxLength: 64, BreachedList: filepath.Join(t.TempDir(), "missing.txt")})
+	if err == nil {
+		t.Error("LoadPolicy accepted a breached list that does not exist")
+	}
+}
@@ -0,0 +1,137 @@
+// Package ratelimit implements token buckets kept in redis, so a limit holds
+// across every app node.
+//
+// A bucket holds up to Burst tokens and refills at Rate tokens per second.
+// Each request takes one token; requests finding the bucket empty are refused
+// until enough has refilled. Buckets are updated by a script that reads the
+// redis clock, so nodes with skewed clocks still agree.
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const keyPrefix = "ratelimit:"
+
+// tokenBucket takes cost tokens from the bucket at KEYS[1] when it holds
+// enough. ARGV: rate per second, burst, cost. Returns whether the tokens were
+// taken, the tokens left and the seconds until cost tokens are available.
+var tokenBucket = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local cost = tonumber(ARGV[3])
+
+local time = redis.call('TIME')
+local now = tonumber(time[1]) + tonumber(time[2]) / 1000000
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1]) or burst
+local ts = tonumber(state[2]) or now
+tokens = math.min(burst, tokens + math.max(0, now - ts) * rate)
+
+local allowed = 0
+local wait = 0
+if tokens >= cost then
+  tokens = tokens - cost
+  allowed = 1
+else
+  wait = (cost - tokens) / rate
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tokens, 'ts', now)
+redis.call('PEXPIRE', KEYS[1], math.ceil(burst / rate * 1000) + 1000)
+return {allowed, tostring(tokens), tostring(wait)}
+`)
+
+// Limit allows Burst requests at once and Rate requests per second after
+// that. A zero Limit allows everything.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// PerPeriod returns the limit allowing count requests per period, all of them
+// at once if need be.
+func PerPeriod(count int, period time.Duration) Limit {
+	if count <= 0 || period <= 0 {
+		return Limit{}
+	}
+	return Limit{Rate: float64(count) / period.Seconds(), Burst: count}
+}
+
+func (l Limit) Disabled() bool {
+	return l.Rate <= 0 || l.Burst <= 0
+}
+
+type Result struct {
+	Allowed    bool
+	Limit      int           // the burst of the bucket
+	Remaining  int           // whole tokens left
+	RetryAfter time.Duration // zero when allowed
+	ResetAfter time.Duration // until the bucket is full again
+}
+
+type Limiter struct {
+	redisClient *redis.Client
+}
+
+func NewLimiter(redisClient *redis.Client) *Limiter {
+	return &Limiter{redisClient}
+}
+
+// Allow takes a token from the bucket named key.
+func (l *Limiter) Allow(ctx context.Context, key string, limit Limit) (*Result, error) {
+	if limit.Disabled() {
+		return &Result{Allowed: true}, nil
+	}
+
+	values, err := tokenBucket.Run(ctx, l.redisClient, []string{keyPrefix + key}, limit.Rate, limit.Burst, 1).Slice()
+	if err != nil {
+		return nil, err
+	}
+
+	tokens, _ := strconv.ParseFloat(values[1].(string), 64)
+	wait, _ := strconv.ParseFloat(values[2].(string), 64)
+
+	return &Result{
+		Allowed:    values[0].(int64) == 1,
+		Limit:      limit.Burst,
+		Remaining:  int(math.Floor(tokens)),
+		RetryAfter: seconds(wait),
+		ResetAfter: seconds((float64(limit.Burst) - tokens) / limit.Rate),
+	}, nil
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+// Cooldown lets one request through per interval under key and returns how
+// long the others must wait.
+func (l *Limiter) Cooldown(ctx context.Context, key string, interval time.Duration) (time.Duration, error) {
+	if interval <= 0 {
+		return 0, nil
+	}
+
+	key = keyPrefix + key
+	for {
+		ok, err := l.redisClient.SetNX(ctx, key, 1, interval).Result()
+		if err != nil || ok {
+			return 0, err
+		}
+
+		wait, err := l.redisClient.PTTL(ctx, key).Result()
+		if err != nil {
+			return 0, err
+		}
+		// otherwise the key expired in between, try again
+		if wait > 0 {
+			return wait, nil
+		}
+	}
+}
@@ -0,0 +1,78 @@
+// Package safehttp makes HTTP requests to urls given by users without
+// letting them reach the internal network: connections to addresses that are
+// not on the public internet are refused.
+package safehttp
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"project/constants"
+	"syscall"
+	"time"
+)
+
+// blockedPrefixes are the ranges that are not on the public internet beyond
+// what netip.Addr reports as loopback, private, link-local or multicast.
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),       // "this" network
+	netip.MustParsePrefix("100.64.0.0/10"),   // carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),    // IETF protocol assignments
+	netip.MustParsePrefix("192.0.2.0/24"),    // documentation
+	netip.MustParsePrefix("198.18.0.0/15"),   // benchmarking
+	netip.MustParsePrefix("198.51.100.0/24"), // documentation
+	netip.MustParsePrefix("203.0.113.0/24"),  // documentation
+	netip.MustParsePrefix("240.0.0.0/4"),     // reserved, broadcast included
+	netip.MustParsePrefix("64:ff9b::/96"),    // NAT64, embeds IPv4 addresses
+	netip.MustParsePrefix("64:ff9b:1::/48"),  // local-use NAT64
+	netip.MustParsePrefix("2001:db8::/32"),   // documentation
+}
+
+// NewTransport returns a transport that only connects to public addresses.
+// The check runs on every dial, so redirects and DNS answers that change
+// between a check of the url and the request are covered too.
+func NewTransport(timeout time.Duration) *http.Transport {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: CheckDialAddress,
+	}
+
+	return &http.Transport{
+		// a proxy would make the connection, and the address check, for us
+		Proxy:                 nil,
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   timeout,
+		ResponseHeaderTimeout: timeout,
+		MaxIdleConns:          10,
+		IdleConnTimeout:       30 * time.Second,
+	}
+}
+
+// CheckDialAddress is a net.Dialer Control that refuses non-public addresses.
+// It runs after name resolution, right before each connection, so it sees the
+// address actually dialed.
+func CheckDialAddress(network string, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	if IsBlockedAddress(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", constants.ErrBlockedAddress, addrPort.Addr().Unmap())
+	}
+	return nil
+}
+
+// IsBlockedAddress reports whether addr is not on the public internet.
+func IsBlockedAddress(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() || addr.IsLoopback() || addr.IsLinkLocalUnicast() {
+		return true
+	}
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,86 @@
+package safehttp
+
+import (
+	"errors"
+	"net/netip"
+	"project/constants"
+	"testing"
+)
+
+func TestIsBlockedAddress(t *testing.T) {
+	tests := []struct {
+		addr    string
+		blocked bool
+	}{
+		{"0.0.0.0", true},
+		{"0.1.2.3", true},
+		{"10.0.0.1", true},
+		{"100.64.0.1", true},
+		{"127.0.0.1", true},
+		{"169.254.169.254", true},
+		{"172.16.0.1", true},
+		{"192.0.0.8", true},
+		{"192.0.2.1", true},
+		{"192.168.1.1", true},
+		{"198.18.0.1", true},
+		{"198.51.100.1", true},
+		{"203.0.113.1", true},
+		{"224.0.0.1", true},
+		{"240.0.0.1", true},
+		{"255.255.255.255", true},
+		{"::", true},
+		{"::1", true},
+		{"fc00::1", true},
+		{"fe80::1", true},
+		{"ff02::1", true},
+		{"2001:db8::1", true},
+		{"::ffff:127.0.0.1", true},
+		{"::ffff:10.0.0.1", true},
+		{"::ffff:169.254.169.254", true},
+		{"64:ff9b::7f00:1", true},
+		{"64:ff9b::a9fe:a9fe", true},
+		{"64:ff9b:1::1", true},
+
+		{"1.1.1.1", false},
+		{"8.8.8.8", false},
+		{"100.128.0.1", false},
+		{"172.32.0.1", false},
+		{"::ffff:8.8.8.8", false},
+		{"2606:4700:4700::1111", false},
+	}
+
+	for _, test := range tests {
+		if got := IsBlockedAddress(netip.MustParseAddr(test.addr)); got != test.blocked {
+			t.Errorf("IsBlockedAddress(%s) = %v, want %v", test.addr, got, test.blocked)
+		}
+	}
+}
+
+func TestCheckDialAddress(t *testing.T) {
+	tests := []struct {
+		address string
+		blocked bool
+	}{
+		{"127.0.0.1:80", true},
+		{"10.0.0.1:443", true},
+		{"[::1]:80", true},
+		{"[::ffff:127.0.0.1]:80", true},
+		{"[64:ff9b::7f00:1]:443", true},
+		{"93.184.216.34:80", false},
+		{"[2606:4700:4700::1111]:443", false},
+	}
+
+	for _, test := range tests {
+		err := CheckDialAddress("tcp", test.address, nil)
+		if test.blocked && !errors.Is(err, constants.ErrBlockedAddress) {
+			t.Errorf("CheckDialAddress(%s) = %v, want ErrBlockedAddress", test.address, err)
+		}
+		if !test.blocked && err != nil {
+			t.Errorf("CheckDialAddress(%s) = %v, want nil", test.address, err)
+		}
+	}
+
+	if err := CheckDialAddress("tcp", "localhost:80", nil); err == nil {
+		t.Error("CheckDialAddress accepted an unresolved address")
+	}
+}
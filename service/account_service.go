@@ -0,0 +1,17 @@
+package service
+
+import (
+	"context"
+	"project/models/request"
+	"project/models/response"
+)
+
+type AccountService interface {
+	ForgotPassword(ctx context.Context, req *request.ForgotPasswordRequest) error
+	ResetPassword(ctx context.Context, req *request.ResetPasswordRequest) error
+	VerifyEmail(ctx context.Context, req *request.VerifyEmailRequest) (*response.UserResponse, error)
+	ResendVerificationEmail(ctx context.Context, req *request.ResendVerificationEmailRequest) error
+	ChangePassword(ctx context.Context, req *request.ChangePasswordRequest) (*response.RevokedSessionsResponse, error)
+	ChangeEmail(ctx context.Context, req *request.ChangeEmailRequest) error
+	ConfirmEmailChange(ctx context.Context, req *request.ConfirmEmailChangeRequest) (*response.UserResponse, error)
+}
@@ -0,0 +1,13 @@
+package service
+
+import (
+	"context"
+	"io"
+	"project/models/request"
+	"project/models/response"
+)
+
+type ArchiveService interface {
+	ExportChannel(ctx context.Context, req *request.ExportChannelRequest, w io.Writer) error
+	ImportChannel(ctx context.Context, req *request.ImportChannelRequest, r io.Reader) (*response.ImportChannelResponse, error)
+}
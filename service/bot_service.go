@@ -0,0 +1,17 @@
+package service
+
+import (
+	"context"
+	"project/models"
+	"project/models/request"
+	"project/models/response"
+)
+
+type BotService interface {
+	CreateBot(ctx context.Context, req *request.CreateBotRequest) (*response.UserResponse, error)
+	GetBots(ctx context.Context, req *request.GetBotsRequest) (*[]response.UserResponse, error)
+	CreateApiKey(ctx context.Context, req *request.CreateApiKeyRequest) (*response.ApiKeyResponse, error)
+	GetApiKeys(ctx context.Context, req *request.GetApiKeysRequest) (*[]response.ApiKeyResponse, error)
+	RevokeApiKey(ctx context.Context, req *request.RevokeApiKeyRequest) error
+	VerifyApiKey(ctx context.Context, key string) (*models.JWTClaims, error)
+}
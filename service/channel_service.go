@@ -0,0 +1,15 @@
+package service
+
+import (
+	"context"
+	db "project/db/sqlc"
+	"project/models/request"
+	"project/models/response"
+)
+
+type ChannelService interface {
+	UpdateMessageTtl(ctx context.Context, req *request.UpdateMessageTtlRequest) (*response.ChannelResponse, error)
+	UpdateChannelRetention(ctx context.Context, req *request.UpdateChannelRetentionRequest) (*response.ChannelResponse, error)
+	UpdateSlowMode(ctx context.Context, req *request.UpdateSlowModeRequest) (*response.ChannelResponse, error)
+	UpdateMemberRole(ctx context.Context, req *request.UpdateMemberRoleRequest) (*db.Membership, error)
+}
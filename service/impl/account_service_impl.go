@@ -0,0 +1,365 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"project/chat"
+	"project/config"
+	"project/constants"
+	db "project/db/sqlc"
+	"project/denylist"
+	"project/logger"
+	"project/models/request"
+	"project/models/response"
+	"project/password"
+	"project/service"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+type AccountServiceImpl struct {
+	cfg           *config.StartupConfig
+	repo          db.Repository
+	hub           *chat.Hub
+	loginGuard    *loginGuard
+	tokenDenylist *denylist.Denylist
+	passwords     *password.Policy
+}
+
+func ConfigureAccountService(cfg *config.StartupConfig, repo db.Repository, redisClient *redis.Client, hub *chat.Hub, tokenDenylist *denylist.Denylist, passwords *password.Policy) service.AccountService {
+	return &AccountServiceImpl{cfg, repo, hub, &loginGuard{redisClient, cfg.Login}, tokenDenylist, passwords}
+}
+
+// ForgotPassword implements service.AccountService. It answers the same
+// whether the email belongs to an account or not, so it cannot be used to
+// find out which addresses have one.
+func (svc *AccountServiceImpl) ForgotPassword(ctx context.Context, req *request.ForgotPasswordRequest) error {
+	user, err := svc.repo.GetUserByEmail(ctx, req.Email)
+	if errors.Is(err, constants.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		logger.Error(ctx, "ForgotPassword :: failed to get user", logger.Field("error", err.Error()))
+		return err
+	}
+
+	// bots authenticate with api keys only
+	if user.IsBot {
+		return nil
+	}
+
+	err = svc.repo.ExecTx(ctx, func(q *db.Queries) error {
+		return sendPasswordResetEmail(ctx, q, svc.cfg, user)
+	})
+	if err != nil {
+		logger.Error(ctx, "ForgotPassword :: failed to send password reset email", logger.Field("userId", user.ID), logger.Field("error", err.Error()))
+		return err
+	}
+
+	return nil
+}
+
+// ResetPassword implements service.AccountService. Every session of the user
+// is logged out, whoever knew the old password is logged out with them.
+func (svc *AccountServiceImpl) ResetPassword(ctx context.Context, req *request.ResetPasswordRequest) error {
+	err := svc.passwords.Check(req.Password)
+	if err != nil {
+		return err
+	}
+
+	hashedPassword, err := svc.passwords.Hash(req.Password)
+	if err != nil {
+		logger.Error(ctx, "ResetPassword :: failed to hash password", logger.Field("error", err.Error()))
+		return err
+	}
+
+	var user *db.User
+	var sessionIds []uuid.UUID
+	err = svc.repo.ExecTx(ctx, func(q *db.Queries) error {
+		token, err := q.UseUserToken(ctx, &db.UseUserTokenParams{
+			TokenHash: hashUserToken(req.Token),
+			Purpose:   constants.UserTokenPasswordReset,
+		})
+		if errors.Is(err, constants.ErrNoRows) {
+			return constants.ErrInvalidUserToken
+		}
+		if err != nil {
+			return err
+		}
+
+		user, err = q.GetUserById(ctx, token.UserID)
+		if err != nil {
+			return err
+		}
+		// the link went to an address the account no longer uses
+		if user.Email != token.Email {
+			return constants.ErrInvalidUserToken
+		}
+
+		err = q.UpdateUserPassword(ctx, &db.UpdateUserPasswordParams{HashedPassword: hashedPassword, ID: user.ID})
+		if err != nil {
+			return err
+		}
+
+		err = expireAccountTokens(ctx, q, user.ID)
+		if err != nil {
+			return err
+		}
+
+		sessionIds, err = q.LogoutSessionsByEmail(ctx, user.Email)
+		return err
+	})
+	if errors.Is(err, constants.ErrInvalidUserToken) {
+		return err
+	}
+	if err != nil {
+		logger.Error(ctx, "ResetPassword :: failed to reset password", logger.Field("error", err.Error()))
+		return err
+	}
+
+	terminateSessions(ctx, svc.tokenDenylist, svc.hub, sessionIds...)
+
+	// the owner proved who they are, a lockout would only keep them out
+	err = svc.loginGuard.unlock(ctx, user.Email)
+	if err != nil {
+		logger.Error(ctx, "ResetPassword :: failed to unlock user", logger.Field("userId", user.ID), logger.Field("error", err.Error()))
+	}
+
+	writeAuditLog(ctx, svc.repo, &db.CreateAuditLogParams{
+		ActorID:      &user.ID,
+		Action:       constants.AuditPasswordReset,
+		TargetUserID: &user.ID,
+		ClientIp:     &req.ClientIp,
+	}, map[string]any{"sessionIds": sessionIds})
+
+	return nil
+}
+
+// ChangePassword implements service.AccountService. Every other session of
+// the user is logged out, the one changing the password stays.
+func (svc *AccountServiceImpl) ChangePassword(ctx context.Context, req *request.ChangePasswordRequest) (*response.RevokedSessionsResponse, error) {
+	user, err := svc.repo.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		logger.Error(ctx, "ChangePassword :: failed to get user", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	// bots authenticate with api keys only
+	if user.IsBot {
+		return nil, constants.ErrAccessDenied
+	}
+
+	if user.HashedPassword != constants.UnusablePasswordHash {
+		_, err = svc.passwords.Verify(user.HashedPassword, req.CurrentPassword)
+		if err != nil {
+			return nil, constants.ErrPasswordIncorrect
+		}
+	}
+
+	err = svc.passwords.Check(req.NewPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	hashedPassword, err := svc.passwords.Hash(req.NewPassword)
+	if err != nil {
+		logger.Error(ctx, "ChangePassword :: failed to hash password", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	var sessionIds []uuid.UUID
+	err = svc.repo.ExecTx(ctx, func(q *db.Queries) error {
+		err := q.UpdateUserPassword(ctx, &db.UpdateUserPasswordParams{HashedPassword: hashedPassword, ID: user.ID})
+		if err != nil {
+			return err
+		}
+
+		err = expireAccountTokens(ctx, q, user.ID)
+		if err != nil {
+			return err
+		}
+
+		sessionIds, err = q.LogoutOtherSessions(ctx, &db.LogoutOtherSessionsParams{Email: user.Email, ID: req.CurrentSessionId})
+		return err
+	})
+	if err != nil {
+		logger.Error(ctx, "ChangePassword :: failed to change password", logger.Field("userId", user.ID), logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	terminateSessions(ctx, svc.tokenDenylist, svc.hub, sessionIds...)
+
+	writeAuditLog(ctx, svc.repo, &db.CreateAuditLogParams{
+		ActorID:      &user.ID,
+		Action:       constants.AuditPasswordChanged,
+		TargetUserID: &user.ID,
+		ClientIp:     &req.ClientIp,
+	}, map[string]any{"sessionIds": sessionIds})
+
+	return &response.RevokedSessionsResponse{Revoked: len(sessionIds)}, nil
+}
+
+// ChangeEmail implements service.AccountService. The address changes once
+// the link mailed to the new one is opened, see ConfirmEmailChange.
+func (svc *AccountServiceImpl) ChangeEmail(ctx context.Context, req *request.ChangeEmailRequest) error {
+	user, err := svc.repo.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		logger.Error(ctx, "ChangeEmail :: failed to get user", logger.Field("error", err.Error()))
+		return err
+	}
+
+	// bots authenticate with api keys only
+	if user.IsBot {
+		return constants.ErrAccessDenied
+	}
+
+	if user.HashedPassword != constants.UnusablePasswordHash {
+		_, err = svc.passwords.Verify(user.HashedPassword, req.Password)
+		if err != nil {
+			return constants.ErrPasswordIncorrect
+		}
+	}
+
+	if strings.EqualFold(req.NewEmail, user.Email) {
+		return constants.ErrEmailTaken
+	}
+	_, err = svc.repo.GetUserByEmail(ctx, req.NewEmail)
+	if err == nil {
+		return constants.ErrEmailTaken
+	}
+	if !errors.Is(err, constants.ErrNoRows) {
+		logger.Error(ctx, "ChangeEmail :: failed to check new email", logger.Field("error", err.Error()))
+		return err
+	}
+
+	err = svc.repo.ExecTx(ctx, func(q *db.Queries) error {
+		return sendEmailChangeEmail(ctx, q, svc.cfg, user, req.NewEmail)
+	})
+	if err != nil {
+		logger.Error(ctx, "ChangeEmail :: failed to send email change email", logger.Field("userId", user.ID), logger.Field("error", err.Error()))
+		return err
+	}
+
+	return nil
+}
+
+// ConfirmEmailChange implements service.AccountService. Sessions and tokens
+// carry the email, so every session of the user is logged out and they log
+// in again with the new address.
+func (svc *AccountServiceImpl) ConfirmEmailChange(ctx context.Context, req *request.ConfirmEmailChangeRequest) (*response.UserResponse, error) {
+	var user *db.User
+	var oldEmail string
+	var sessionIds []uuid.UUID
+	err := svc.repo.ExecTx(ctx, func(q *db.Queries) error {
+		token, err := q.UseUserToken(ctx, &db.UseUserTokenParams{
+			TokenHash: hashUserToken(req.Token),
+			Purpose:   constants.UserTokenEmailChange,
+		})
+		if errors.Is(err, constants.ErrNoRows) {
+			return constants.ErrInvalidUserToken
+		}
+		if err != nil {
+			return err
+		}
+
+		user, err = q.GetUserById(ctx, token.UserID)
+		if err != nil {
+			return err
+		}
+		oldEmail = user.Email
+
+		user, err = q.UpdateUserEmail(ctx, &db.UpdateUserEmailParams{Email: token.Email, ID: user.ID, OldEmail: oldEmail})
+		if err != nil {
+			return uniqueUserError(err)
+		}
+
+		sessionIds, err = q.LogoutSessionsByEmail(ctx, oldEmail)
+		return err
+	})
+	if errors.Is(err, constants.ErrInvalidUserToken) || errors.Is(err, constants.ErrEmailTaken) {
+		return nil, err
+	}
+	if err != nil {
+		logger.Error(ctx, "ConfirmEmailChange :: failed to change email", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	terminateSessions(ctx, svc.tokenDenylist, svc.hub, sessionIds...)
+
+	writeAuditLog(ctx, svc.repo, &db.CreateAuditLogParams{
+		ActorID:      &user.ID,
+		Action:       constants.AuditEmailChanged,
+		TargetUserID: &user.ID,
+		ClientIp:     &req.ClientIp,
+	}, map[string]any{"from": oldEmail, "to": user.Email, "sessionIds": sessionIds})
+
+	return response.BuildUserResponse(user), nil
+}
+
+// VerifyEmail implements service.AccountService.
+func (svc *AccountServiceImpl) VerifyEmail(ctx context.Context, req *request.VerifyEmailRequest) (*response.UserResponse, error) {
+	var user *db.User
+	err := svc.repo.ExecTx(ctx, func(q *db.Queries) error {
+		token, err := q.UseUserToken(ctx, &db.UseUserTokenParams{
+			TokenHash: hashUserToken(req.Token),
+			Purpose:   constants.UserTokenEmailVerification,
+		})
+		if errors.Is(err, constants.ErrNoRows) {
+			return constants.ErrInvalidUserToken
+		}
+		if err != nil {
+			return err
+		}
+
+		user, err = q.VerifyUserEmail(ctx, &db.VerifyUserEmailParams{ID: token.UserID, Email: token.Email})
+		if errors.Is(err, constants.ErrNoRows) {
+			return constants.ErrInvalidUserToken
+		}
+		return err
+	})
+	if errors.Is(err, constants.ErrInvalidUserToken) {
+		return nil, err
+	}
+	if err != nil {
+		logger.Error(ctx, "VerifyEmail :: failed to verify email", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	writeAuditLog(ctx, svc.repo, &db.CreateAuditLogParams{
+		ActorID:      &user.ID,
+		Action:       constants.AuditEmailVerified,
+		TargetUserID: &user.ID,
+	}, map[string]any{"email": user.Email})
+
+	return response.BuildUserResponse(user), nil
+}
+
+// ResendVerificationEmail implements service.AccountService. Like
+// ForgotPassword, it does not tell whether the email has an account, or
+// whether it is verified already.
+func (svc *AccountServiceImpl) ResendVerificationEmail(ctx context.Context, req *request.ResendVerificationEmailRequest) error {
+	user, err := svc.repo.GetUserByEmail(ctx, req.Email)
+	if errors.Is(err, constants.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		logger.Error(ctx, "ResendVerificationEmail :: failed to get user", logger.Field("error", err.Error()))
+		return err
+	}
+
+	if user.IsBot || user.VerifiedAt != nil {
+		return nil
+	}
+
+	err = svc.repo.ExecTx(ctx, func(q *db.Queries) error {
+		return sendVerificationEmail(ctx, q, svc.cfg, user)
+	})
+	if err != nil {
+		logger.Error(ctx, "ResendVerificationEmail :: failed to send verification email", logger.Field("userId", user.ID), logger.Field("error", err.Error()))
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,352 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"project/archive"
+	"project/config"
+	"project/constants"
+	db "project/db/sqlc"
+	"project/logger"
+	"project/markdown"
+	"project/models/request"
+	"project/models/response"
+	"project/service"
+	"time"
+)
+
+// exportPageSize is the number of messages read per query while exporting.
+const exportPageSize = 500
+
+type ArchiveServiceImpl struct {
+	repo db.Repository
+}
+
+func ConfigureArchiveService(cfg *config.StartupConfig, repo db.Repository) service.ArchiveService {
+	return &ArchiveServiceImpl{repo}
+}
+
+// ExportChannel implements service.ArchiveService. Messages are paged by id
+// and written as they are read, so the channel is never held in memory.
+func (svc *ArchiveServiceImpl) ExportChannel(ctx context.Context, req *request.ExportChannelRequest, w io.Writer) error {
+	if req.Format == "" {
+		req.Format = archive.FormatJSON
+	}
+
+	channel, err := svc.repo.GetChannelById(ctx, req.ChannelId)
+	if err != nil {
+		logger.Error(ctx, "ExportChannel :: failed to get channel", logger.Field("error", err.Error()))
+		return err
+	}
+
+	encoder, err := archive.NewEncoder(req.Format, w)
+	if err != nil {
+		return fmt.Errorf("%w: %v", constants.ErrInvalidArchive, err)
+	}
+
+	header := &archive.Header{Version: archive.Version, ExportedAt: time.Now().UTC()}
+	err = encoder.WriteChannel(header, &archive.Channel{
+		Id:                channel.ID,
+		Name:              channel.Name,
+		MessageTtlSeconds: channel.MessageTtlSeconds,
+		RetentionPolicy:   channel.RetentionPolicy,
+		RetentionValue:    channel.RetentionValue,
+		CreatedAt:         channel.CreatedAt,
+	})
+	if err != nil {
+		logger.Error(ctx, "ExportChannel :: failed to write channel", logger.Field("error", err.Error()))
+		return err
+	}
+
+	members, err := svc.repo.GetChannelMembersForExport(ctx, channel.ID)
+	if err != nil {
+		logger.Error(ctx, "ExportChannel :: failed to get members", logger.Field("error", err.Error()))
+		return err
+	}
+	for _, member := range members {
+		err = encoder.WriteMember(&archive.Member{
+			Email:    member.Email,
+			Username: member.Username,
+			Role:     member.Role,
+			JoinedAt: member.CreatedAt,
+		})
+		if err != nil {
+			logger.Error(ctx, "ExportChannel :: failed to write member", logger.Field("error", err.Error()))
+			return err
+		}
+	}
+
+	var afterId int64
+	for {
+		messages, err := svc.repo.GetChannelMessagesForExport(ctx, &db.GetChannelMessagesForExportParams{
+			ChannelID: channel.ID,
+			AfterID:   afterId,
+			PageSize:  exportPageSize,
+		})
+		if err != nil {
+			logger.Error(ctx, "ExportChannel :: failed to get messages", logger.Field("error", err.Error()))
+			return err
+		}
+
+		for _, message := range messages {
+			err = encoder.WriteMessage(&archive.Message{
+				Id:               message.ID,
+				AuthorEmail:      message.Email,
+				AuthorUsername:   message.Username,
+				Content:          message.Content,
+				CreatedAt:        message.CreatedAt,
+				ExpiresAt:        message.ExpiresAt,
+				IsBot:            message.IsBot,
+				UsernameOverride: message.UsernameOverride,
+				Attachments:      message.Attachments,
+			})
+			if err != nil {
+				logger.Error(ctx, "ExportChannel :: failed to write message", logger.Field("error", err.Error()))
+				return err
+			}
+			afterId = message.ID
+		}
+
+		if len(messages) < exportPageSize {
+			break
+		}
+	}
+
+	pins, err := svc.repo.GetChannelPinsForExport(ctx, channel.ID)
+	if err != nil {
+		logger.Error(ctx, "ExportChannel :: failed to get pins", logger.Field("error", err.Error()))
+		return err
+	}
+	for _, pin := range pins {
+		err = encoder.WritePin(&archive.Pin{
+			MessageId:     pin.MessageID,
+			PinnedByEmail: pin.Email,
+			CreatedAt:     pin.CreatedAt,
+		})
+		if err != nil {
+			logger.Error(ctx, "ExportChannel :: failed to write pin", logger.Field("error", err.Error()))
+			return err
+		}
+	}
+
+	return encoder.Close()
+}
+
+// ImportChannel implements service.ArchiveService. The whole archive is
+// imported in one transaction: a failure anywhere leaves no partial channel.
+func (svc *ArchiveServiceImpl) ImportChannel(ctx context.Context, req *request.ImportChannelRequest, r io.Reader) (*response.ImportChannelResponse, error) {
+	if req.Format == "" {
+		req.Format = archive.FormatJSON
+	}
+
+	var importer *channelImporter
+	err := svc.repo.ExecTx(ctx, func(q *db.Queries) error {
+		importer = newChannelImporter(ctx, q, req)
+		err := archive.Decode(req.Format, r, importer)
+		if err != nil && importer.err == nil {
+			// the error comes from the archive itself, not from the database
+			return fmt.Errorf("%w: %v", constants.ErrInvalidArchive, err)
+		}
+		return err
+	})
+	if err != nil {
+		logger.Error(ctx, "ImportChannel :: failed to import channel", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	importer.result.Channel = response.BuildChannelResponse(importer.channel, importer.result.Pins)
+	return importer.result, nil
+}
+
+// channelImporter is the archive.Handler writing decoded records through the
+// transaction's queries. Archived users are matched by email and archived
+// message ids are mapped onto the new ids so pins can follow them.
+type channelImporter struct {
+	ctx     context.Context
+	q       *db.Queries
+	req     *request.ImportChannelRequest
+	channel *db.Channel
+	users   map[string]int64
+	members map[int64]bool
+	ids     map[int64]int64
+	pinned  map[int64]bool
+	result  *response.ImportChannelResponse
+	err     error
+}
+
+func newChannelImporter(ctx context.Context, q *db.Queries, req *request.ImportChannelRequest) *channelImporter {
+	return &channelImporter{
+		ctx:     ctx,
+		q:       q,
+		req:     req,
+		users:   make(map[string]int64),
+		members: make(map[int64]bool),
+		ids:     make(map[int64]int64),
+		pinned:  make(map[int64]bool),
+		result:  &response.ImportChannelResponse{CreatedUsers: make([]string, 0)},
+	}
+}
+
+// fail records err as a database error so ImportChannel can tell it apart
+// from a malformed archive.
+func (im *channelImporter) fail(err error) error {
+	im.err = err
+	return err
+}
+
+func (im *channelImporter) resolveUser(email string, username string) (int64, error) {
+	if userId, ok := im.users[email]; ok {
+		return userId, nil
+	}
+
+	user, err := im.q.GetUserByEmail(im.ctx, email)
+	if err != nil {
+		if !errors.Is(err, constants.ErrNoRows) {
+			return 0, im.fail(err)
+		}
+		if !im.req.CreateMissingUsers {
+			return 0, im.fail(fmt.Errorf("%w: %s", constants.ErrArchiveUserNotFound, email))
+		}
+
+		if username == "" {
+			username = email
+		}
+		username, err = availableUsername(im.ctx, im.q, username)
+		if err != nil {
+			return 0, im.fail(err)
+		}
+		user, err = im.q.CreateUser(im.ctx, &db.CreateUserParams{
+			Username:       username,
+			Email:          email,
+			HashedPassword: constants.UnusablePasswordHash,
+		})
+		if err != nil {
+			return 0, im.fail(err)
+		}
+		im.result.CreatedUsers = append(im.result.CreatedUsers, email)
+	}
+
+	im.users[email] = user.ID
+	return user.ID, nil
+}
+
+func (im *channelImporter) Channel(header *archive.Header, channel *archive.Channel) error {
+	if im.channel != nil {
+		return errors.New("archive has more than one channel record")
+	}
+
+	name := channel.Name
+	if im.req.Name != "" {
+		name = im.req.Name
+	}
+	policy := channel.RetentionPolicy
+	if policy == "" {
+		policy = constants.RetentionPolicyInherit
+	}
+
+	created, err := im.q.ImportChannel(im.ctx, &db.ImportChannelParams{
+		Name:              name,
+		CreatedAt:         channel.CreatedAt,
+		MessageTtlSeconds: channel.MessageTtlSeconds,
+		RetentionPolicy:   policy,
+		RetentionValue:    channel.RetentionValue,
+	})
+	if err != nil {
+		return im.fail(err)
+	}
+
+	im.channel = created
+	return nil
+}
+
+func (im *channelImporter) Member(member *archive.Member) error {
+	userId, err := im.resolveUser(member.Email, member.Username)
+	if err != nil {
+		return err
+	}
+	if im.members[userId] {
+		return nil
+	}
+
+	role := member.Role
+	if role != constants.MembershipRoleAdmin {
+		role = constants.MembershipRoleMember
+	}
+	_, err = im.q.ImportMembership(im.ctx, &db.ImportMembershipParams{
+		UserID:    userId,
+		ChannelID: im.channel.ID,
+		Role:      role,
+		CreatedAt: member.JoinedAt,
+	})
+	if err != nil {
+		return im.fail(err)
+	}
+
+	im.members[userId] = true
+	im.result.Members++
+	return nil
+}
+
+func (im *channelImporter) Message(message *archive.Message) error {
+	userId, err := im.resolveUser(message.AuthorEmail, message.AuthorUsername)
+	if err != nil {
+		return err
+	}
+
+	// history is kept verbatim, only the spans are derived from it
+	spans, err := json.Marshal(markdown.Parse(message.Content))
+	if err != nil {
+		return im.fail(err)
+	}
+
+	created, err := im.q.ImportMessage(im.ctx, &db.ImportMessageParams{
+		ChannelID:        im.channel.ID,
+		UserID:           userId,
+		Content:          message.Content,
+		CreatedAt:        message.CreatedAt,
+		ExpiresAt:        message.ExpiresAt,
+		IsBot:            message.IsBot,
+		UsernameOverride: message.UsernameOverride,
+		Attachments:      message.Attachments,
+		Spans:            spans,
+	})
+	if err != nil {
+		return im.fail(err)
+	}
+
+	im.ids[message.Id] = created.ID
+	im.result.Messages++
+	return nil
+}
+
+// Pin skips pins whose message is not in the archive, which happens when the
+// message was purged while the export was running.
+func (im *channelImporter) Pin(pin *archive.Pin) error {
+	messageId, ok := im.ids[pin.MessageId]
+	if !ok || im.pinned[messageId] {
+		im.result.SkippedPins++
+		return nil
+	}
+
+	userId, err := im.resolveUser(pin.PinnedByEmail, "")
+	if err != nil {
+		return err
+	}
+
+	_, err = im.q.ImportPin(im.ctx, &db.ImportPinParams{
+		ChannelID: im.channel.ID,
+		MessageID: messageId,
+		PinnedBy:  userId,
+		CreatedAt: pin.CreatedAt,
+	})
+	if err != nil {
+		return im.fail(err)
+	}
+
+	im.pinned[messageId] = true
+	im.result.Pins++
+	return nil
+}
@@ -0,0 +1,21 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	db "project/db/sqlc"
+	"project/logger"
+)
+
+// writeAuditLog records a security event. Failing to do so is logged, it
+// never fails the request that caused the event.
+func writeAuditLog(ctx context.Context, q db.Querier, arg *db.CreateAuditLogParams, details map[string]any) {
+	var err error
+	arg.Details, err = json.Marshal(details)
+	if err == nil {
+		err = q.CreateAuditLog(ctx, arg)
+	}
+	if err != nil {
+		logger.Error(ctx, "writeAuditLog :: failed to write audit log", logger.Field("action", arg.Action), logger.Field("error", err.Error()))
+	}
+}
@@ -0,0 +1,249 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"project/config"
+	"project/constants"
+	db "project/db/sqlc"
+	"project/logger"
+	"project/models"
+	"project/models/request"
+	"project/models/response"
+	"project/service"
+	"project/utils"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// apiKeySize is the number of random bytes in an api key.
+const apiKeySize = 32
+
+// apiKeyDisplayLength is the number of leading characters of a key kept in
+// clear so owners can tell their keys apart.
+const apiKeyDisplayLength = 12
+
+type BotServiceImpl struct {
+	repo db.Repository
+}
+
+func ConfigureBotService(cfg *config.StartupConfig, repo db.Repository) service.BotService {
+	return &BotServiceImpl{repo}
+}
+
+// getOwnedBot returns the bot when owner created it or is a deployment admin.
+func (svc *BotServiceImpl) getOwnedBot(ctx context.Context, owner *db.User, botId int64) (*db.User, error) {
+	bot, err := svc.repo.GetUserById(ctx, botId)
+	if err != nil {
+		return nil, err
+	}
+
+	if !bot.IsBot {
+		return nil, constants.ErrNoRows
+	}
+	if owner.IsAdmin {
+		return bot, nil
+	}
+	if bot.OwnerID == nil || *bot.OwnerID != owner.ID {
+		return nil, constants.ErrNotBotOwner
+	}
+
+	return bot, nil
+}
+
+// CreateBot implements service.BotService. Bots get an unusable password and
+// an address under the reserved .invalid domain, they can only authenticate
+// with api keys.
+func (svc *BotServiceImpl) CreateBot(ctx context.Context, req *request.CreateBotRequest) (*response.UserResponse, error) {
+	owner, err := svc.repo.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		logger.Error(ctx, "CreateBot :: failed to get user", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	if owner.IsBot {
+		return nil, constants.ErrNotBotOwner
+	}
+
+	suffix, err := utils.RandomToken(8)
+	if err != nil {
+		logger.Error(ctx, "CreateBot :: failed to generate email", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	arg := &db.CreateBotUserParams{
+		Username:       req.Username,
+		Email:          fmt.Sprintf("bot.%s@bots.invalid", strings.ToLower(suffix)),
+		HashedPassword: constants.UnusablePasswordHash,
+		OwnerID:        &owner.ID,
+	}
+	bot, err := svc.repo.CreateBotUser(ctx, arg)
+	if err != nil {
+		logger.Error(ctx, "CreateBot :: failed to create bot", logger.Field("error", err.Error()))
+		return nil, uniqueUserError(err)
+	}
+
+	return response.BuildUserResponse(bot), nil
+}
+
+// GetBots implements service.BotService.
+func (svc *BotServiceImpl) GetBots(ctx context.Context, req *request.GetBotsRequest) (*[]response.UserResponse, error) {
+	owner, err := svc.repo.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		logger.Error(ctx, "GetBots :: failed to get user", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	bots, err := svc.repo.GetBotsByOwnerId(ctx, &owner.ID)
+	if err != nil {
+		logger.Error(ctx, "GetBots :: failed to get bots", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	botsResponse := make([]response.UserResponse, 0)
+	for _, bot := range bots {
+		botsResponse = append(botsResponse, *response.BuildUserResponse(bot))
+	}
+
+	return &botsResponse, nil
+}
+
+// CreateApiKey implements service.BotService. Only the hash of the key is
+// stored, the key is returned once and cannot be recovered.
+func (svc *BotServiceImpl) CreateApiKey(ctx context.Context, req *request.CreateApiKeyRequest) (*response.ApiKeyResponse, error) {
+	owner, err := svc.repo.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		logger.Error(ctx, "CreateApiKey :: failed to get user", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	bot, err := svc.getOwnedBot(ctx, owner, req.BotId)
+	if err != nil {
+		logger.Error(ctx, "CreateApiKey :: failed to get bot", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	secret, err := utils.RandomToken(apiKeySize)
+	if err != nil {
+		logger.Error(ctx, "CreateApiKey :: failed to generate key", logger.Field("error", err.Error()))
+		return nil, err
+	}
+	key := constants.ApiKeyPrefix + secret
+
+	arg := &db.CreateApiKeyParams{
+		UserID:  bot.ID,
+		Name:    req.Name,
+		Prefix:  key[:apiKeyDisplayLength],
+		KeyHash: utils.HashToken(key),
+		Scopes:  req.Scopes,
+	}
+	if req.ExpiresInDays != nil {
+		expiresAt := time.Now().AddDate(0, 0, int(*req.ExpiresInDays))
+		arg.ExpiresAt = &expiresAt
+	}
+	apiKey, err := svc.repo.CreateApiKey(ctx, arg)
+	if err != nil {
+		logger.Error(ctx, "CreateApiKey :: failed to create key", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	apiKeyResponse := response.BuildApiKeyResponse(apiKey)
+	apiKeyResponse.Key = key
+	return apiKeyResponse, nil
+}
+
+// GetApiKeys implements service.BotService.
+func (svc *BotServiceImpl) GetApiKeys(ctx context.Context, req *request.GetApiKeysRequest) (*[]response.ApiKeyResponse, error) {
+	owner, err := svc.repo.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		logger.Error(ctx, "GetApiKeys :: failed to get user", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	bot, err := svc.getOwnedBot(ctx, owner, req.BotId)
+	if err != nil {
+		logger.Error(ctx, "GetApiKeys :: failed to get bot", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	apiKeys, err := svc.repo.GetApiKeysByUserId(ctx, bot.ID)
+	if err != nil {
+		logger.Error(ctx, "GetApiKeys :: failed to get keys", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	apiKeysResponse := make([]response.ApiKeyResponse, 0)
+	for _, apiKey := range apiKeys {
+		apiKeysResponse = append(apiKeysResponse, *response.BuildApiKeyResponse(apiKey))
+	}
+
+	return &apiKeysResponse, nil
+}
+
+// RevokeApiKey implements service.BotService. The key stops working on the
+// next request.
+func (svc *BotServiceImpl) RevokeApiKey(ctx context.Context, req *request.RevokeApiKeyRequest) error {
+	owner, err := svc.repo.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		logger.Error(ctx, "RevokeApiKey :: failed to get user", logger.Field("error", err.Error()))
+		return err
+	}
+
+	bot, err := svc.getOwnedBot(ctx, owner, req.BotId)
+	if err != nil {
+		logger.Error(ctx, "RevokeApiKey :: failed to get bot", logger.Field("error", err.Error()))
+		return err
+	}
+
+	_, err = svc.repo.RevokeApiKey(ctx, &db.RevokeApiKeyParams{ID: req.KeyId, UserID: bot.ID})
+	if err != nil {
+		logger.Error(ctx, "RevokeApiKey :: failed to revoke key", logger.Field("error", err.Error()))
+		return err
+	}
+
+	return nil
+}
+
+// VerifyApiKey implements service.BotService. It returns claims for the bot
+// holding the key, so handlers treat it like any authenticated user.
+func (svc *BotServiceImpl) VerifyApiKey(ctx context.Context, key string) (*models.JWTClaims, error) {
+	apiKey, err := svc.repo.GetApiKeyByHash(ctx, utils.HashToken(key))
+	if err != nil {
+		logger.Error(ctx, "VerifyApiKey :: failed to get key", logger.Field("error", err.Error()))
+		if errors.Is(err, constants.ErrNoRows) {
+			return nil, constants.ErrTokenInvalid
+		}
+		return nil, err
+	}
+
+	if apiKey.ExpiresAt != nil && time.Now().After(*apiKey.ExpiresAt) {
+		return nil, constants.ErrTokenExpired
+	}
+
+	user, err := svc.repo.GetUserById(ctx, apiKey.UserID)
+	if err != nil {
+		logger.Error(ctx, "VerifyApiKey :: failed to get user", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	err = svc.repo.TouchApiKey(ctx, apiKey.ID)
+	if err != nil {
+		logger.Error(ctx, "VerifyApiKey :: failed to update last use", logger.Field("error", err.Error()))
+	}
+
+	return &models.JWTClaims{
+		Type:  constants.TokenTypeAccess,
+		Email: user.Email,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:  strconv.FormatInt(user.ID, 10),
+			IssuedAt: jwt.NewNumericDate(apiKey.CreatedAt),
+		},
+		UserId:   user.ID,
+		ApiKeyId: apiKey.ID,
+		Scopes:   apiKey.Scopes,
+	}, nil
+}
@@ -0,0 +1,37 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"project/constants"
+	db "project/db/sqlc"
+)
+
+// getChannelMembership returns the membership of the user in the channel,
+// or constants.ErrNotChannelMember when the user has not joined it.
+func getChannelMembership(ctx context.Context, repo db.Repository, userId int64, channelId int64) (*db.Membership, error) {
+	membership, err := repo.GetMembership(ctx, &db.GetMembershipParams{UserID: userId, ChannelID: channelId})
+	if err != nil {
+		if errors.Is(err, constants.ErrNoRows) {
+			return nil, constants.ErrNotChannelMember
+		}
+		return nil, err
+	}
+
+	return membership, nil
+}
+
+// requireChannelAdmin returns constants.ErrNotChannelAdmin unless the user
+// holds the admin role in the channel.
+func requireChannelAdmin(ctx context.Context, repo db.Repository, userId int64, channelId int64) (*db.Membership, error) {
+	membership, err := getChannelMembership(ctx, repo, userId, channelId)
+	if err != nil {
+		return nil, err
+	}
+
+	if membership.Role != constants.MembershipRoleAdmin {
+		return nil, constants.ErrNotChannelAdmin
+	}
+
+	return membership, nil
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"project/config"
+	"project/constants"
+	db "project/db/sqlc"
+	"project/logger"
+	"project/models/request"
+	"project/models/response"
+	"project/service"
+)
+
+type ChannelServiceImpl struct {
+	repo db.Repository
+}
+
+func ConfigureChannelService(cfg *config.StartupConfig, repo db.Repository) service.ChannelService {
+	return &ChannelServiceImpl{repo}
+}
+
+// UpdateMessageTtl implements service.ChannelService.
+func (svc *ChannelServiceImpl) UpdateMessageTtl(ctx context.Context, req *request.UpdateMessageTtlRequest) (*response.ChannelResponse, error) {
+	user, err := svc.repo.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		logger.Error(ctx, "UpdateMessageTtl :: failed to get user", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	_, err = requireChannelAdmin(ctx, svc.repo, user.ID, req.ChannelId)
+	if err != nil {
+		logger.Error(ctx, "UpdateMessageTtl :: channel admin required", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	// a nil ttl keeps new messages forever
+	arg := &db.UpdateChannelMessageTtlParams{
+		MessageTtlSeconds: req.TtlSeconds,
+		ID:                req.ChannelId,
+	}
+	channel, err := svc.repo.UpdateChannelMessageTtl(ctx, arg)
+	if err != nil {
+		logger.Error(ctx, "UpdateMessageTtl :: failed to update channel", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	pinCount, err := svc.repo.CountPinsByChannelId(ctx, channel.ID)
+	if err != nil {
+		logger.Error(ctx, "UpdateMessageTtl :: failed to count pins", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	return response.BuildChannelResponse(channel, pinCount), nil
+}
+
+// UpdateChannelRetention implements service.ChannelService.
+func (svc *ChannelServiceImpl) UpdateChannelRetention(ctx context.Context, req *request.UpdateChannelRetentionRequest) (*response.ChannelResponse, error) {
+	needsValue := req.Policy == constants.RetentionPolicyDays || req.Policy == constants.RetentionPolicyMessages
+	if needsValue && req.Value == nil {
+		return nil, constants.ErrInvalidRetentionPolicy
+	}
+	if !needsValue {
+		req.Value = nil
+	}
+
+	user, err := svc.repo.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		logger.Error(ctx, "UpdateChannelRetention :: failed to get user", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	_, err = requireChannelAdmin(ctx, svc.repo, user.ID, req.ChannelId)
+	if err != nil {
+		logger.Error(ctx, "UpdateChannelRetention :: channel admin required", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	arg := &db.UpdateChannelRetentionParams{
+		RetentionPolicy: req.Policy,
+		RetentionValue:  req.Value,
+		ID:              req.ChannelId,
+	}
+	channel, err := svc.repo.UpdateChannelRetention(ctx, arg)
+	if err != nil {
+		logger.Error(ctx, "UpdateChannelRetention :: failed to update channel", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	pinCount, err := svc.repo.CountPinsByChannelId(ctx, channel.ID)
+	if err != nil {
+		logger.Error(ctx, "UpdateChannelRetention :: failed to count pins", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	return response.BuildChannelResponse(channel, pinCount), nil
+}
+
+// UpdateSlowMode implements service.ChannelService. Channel admins are not
+// slowed down.
+func (svc *ChannelServiceImpl) UpdateSlowMode(ctx context.Context, req *request.UpdateSlowModeRequest) (*response.ChannelResponse, error) {
+	user, err := svc.repo.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		logger.Error(ctx, "UpdateSlowMode :: failed to get user", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	_, err = requireChannelAdmin(ctx, svc.repo, user.ID, req.ChannelId)
+	if err != nil {
+		logger.Error(ctx, "UpdateSlowMode :: channel admin required", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	channel, err := svc.repo.UpdateChannelSlowMode(ctx, &db.UpdateChannelSlowModeParams{
+		SlowModeSeconds: *req.Seconds,
+		ID:              req.ChannelId,
+	})
+	if err != nil {
+		logger.Error(ctx, "UpdateSlowMode :: failed to update channel", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	pinCount, err := svc.repo.CountPinsByChannelId(ctx, channel.ID)
+	if err != nil {
+		logger.Error(ctx, "UpdateSlowMode :: failed to count pins", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	return response.BuildChannelResponse(channel, pinCount), nil
+}
+
+// UpdateMemberRole implements service.ChannelService. Only site admins get
+// here, it is how channels without an admin get one.
+func (svc *ChannelServiceImpl) UpdateMemberRole(ctx context.Context, req *request.UpdateMemberRoleRequest) (*db.Membership, error) {
+	admin, err := svc.repo.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		logger.Error(ctx, "UpdateMemberRole :: failed to get admin", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	membership, err := svc.repo.UpdateMembershipRole(ctx, &db.UpdateMembershipRoleParams{
+		Role:      req.Role,
+		UserID:    req.UserId,
+		ChannelID: req.ChannelId,
+	})
+	if err != nil {
+		logger.Error(ctx, "UpdateMemberRole :: failed to update membership", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	writeAuditLog(ctx, svc.repo, &db.CreateAuditLogParams{
+		ActorID:      &admin.ID,
+		Action:       constants.AuditMemberRoleChanged,
+		TargetUserID: &membership.UserID,
+	}, map[string]any{"channelId": membership.ChannelID, "role": membership.Role})
+
+	return membership, nil
+}
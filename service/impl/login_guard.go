@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"project/config"
+	"project/constants"
+	"project/utils"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// loginGuard tracks failed logins in redis, so every app node sees the same
+// counts. Failures on an account delay its next attempts, then lock it;
+// failures from an address, whatever the account, lock the address out.
+type loginGuard struct {
+	redisClient *redis.Client
+	cfg         config.LoginConfig
+}
+
+// lockout describes the lockouts a failed attempt started.
+type lockout struct {
+	account  bool
+	address  bool
+	failures int64 // failures on the account, when it got locked
+}
+
+func loginKey(kind string, subject string) string {
+	return fmt.Sprintf("login:%s:%s", kind, subject)
+}
+
+func accountSubject(email string) string {
+	return "account:" + strings.ToLower(email)
+}
+
+func addressSubject(clientIp string) string {
+	return "ip:" + clientIp
+}
+
+// check returns constants.ErrAccountLocked or constants.ErrLoginThrottled,
+// wrapped with the time to wait, when the attempt may not be made yet.
+func (g *loginGuard) check(ctx context.Context, email string, clientIp string) error {
+	pipe := g.redisClient.Pipeline()
+	accountLock := pipe.PTTL(ctx, loginKey("lock", accountSubject(email)))
+	addressLock := pipe.PTTL(ctx, loginKey("lock", addressSubject(clientIp)))
+	delay := pipe.PTTL(ctx, loginKey("delay", accountSubject(email)))
+	if _, err := pipe.Exec(ctx); err != nil {
+		return err
+	}
+
+	if wait := accountLock.Val(); wait > 0 {
+		return utils.RetryAfterError(constants.ErrAccountLocked, wait)
+	}
+	if wait := addressLock.Val(); wait > 0 {
+		return utils.RetryAfterError(constants.ErrLoginThrottled, wait)
+	}
+	if wait := delay.Val(); wait > 0 {
+		return utils.RetryAfterError(constants.ErrLoginThrottled, wait)
+	}
+	return nil
+}
+
+// fail records a failed attempt. Unknown emails are counted like accounts,
+// their counters expire with the failure window.
+func (g *loginGuard) fail(ctx context.Context, email string, clientIp string) (*lockout, error) {
+	locked := &lockout{}
+
+	addressFailures, err := g.count(ctx, loginKey("fail", addressSubject(clientIp)))
+	if err != nil {
+		return nil, err
+	}
+	if addressFailures >= g.cfg.AddressMaxFailures {
+		err = g.lock(ctx, addressSubject(clientIp))
+		if err != nil {
+			return nil, err
+		}
+		locked.address = true
+	}
+
+	accountFailures, err := g.count(ctx, loginKey("fail", accountSubject(email)))
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case accountFailures >= g.cfg.AccountMaxFailures:
+		err = g.lock(ctx, accountSubject(email))
+		if err != nil {
+			return nil, err
+		}
+		locked.account = true
+		locked.failures = accountFailures
+
+	case accountFailures > g.cfg.DelayAfter:
+		err = g.redisClient.Set(ctx, loginKey("delay", accountSubject(email)), 1, g.delay(accountFailures)).Err()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return locked, nil
+}
+
+// succeed clears the failures of the account. Those of the address are kept,
+// a valid login of their own must not let attackers start over.
+func (g *loginGuard) succeed(ctx context.Context, email string) error {
+	return g.redisClient.Del(ctx,
+		loginKey("fail", accountSubject(email)),
+		loginKey("delay", accountSubject(email)),
+	).Err()
+}
+
+// unlock lifts the lockout of an account and clears its failures.
+func (g *loginGuard) unlock(ctx context.Context, email string) error {
+	return g.redisClient.Del(ctx,
+		loginKey("lock", accountSubject(email)),
+		loginKey("fail", accountSubject(email)),
+		loginKey("delay", accountSubject(email)),
+	).Err()
+}
+
+func (g *loginGuard) count(ctx context.Context, key string) (int64, error) {
+	pipe := g.redisClient.TxPipeline()
+	count := pipe.Incr(ctx, key)
+	pipe.ExpireNX(ctx, key, g.cfg.FailureWindow)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+	return count.Val(), nil
+}
+
+// lock starts a lockout and resets the failures that led to it.
+func (g *loginGuard) lock(ctx context.Context, subject string) error {
+	pipe := g.redisClient.TxPipeline()
+	pipe.Set(ctx, loginKey("lock", subject), 1, g.cfg.LockoutDuration)
+	pipe.Del(ctx, loginKey("fail", subject), loginKey("delay", subject))
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// delay doubles from DelayBase with every failure past DelayAfter.
+func (g *loginGuard) delay(failures int64) time.Duration {
+	delay := g.cfg.DelayBase
+	for i := g.cfg.DelayAfter + 1; i < failures && delay < g.cfg.DelayMax; i++ {
+		delay *= 2
+	}
+	if delay > g.cfg.DelayMax {
+		delay = g.cfg.DelayMax
+	}
+	return delay
+}
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"project/config"
+	"project/constants"
+	db "project/db/sqlc"
+	"project/logger"
+	"project/models/request"
+	"project/models/response"
+	"project/service"
+	"project/totp"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// loginIssuer ends every kind of login once the user is authenticated, so
+// they all ask for the same second factor and issue the same tokens.
+type loginIssuer struct {
+	cfg        *config.StartupConfig
+	repo       db.Repository
+	tokenSvc   service.TokenService
+	loginGuard *loginGuard
+	twoFactor  *twoFactor
+}
+
+func newLoginIssuer(cfg *config.StartupConfig, repo db.Repository, redisClient *redis.Client, tokenSvc service.TokenService, totpCipher *totp.Cipher) *loginIssuer {
+	return &loginIssuer{cfg, repo, tokenSvc, &loginGuard{redisClient, cfg.Login}, &twoFactor{totpCipher, cfg.TwoFactor}}
+}
+
+// complete answers an authenticated login with a second factor challenge
+// when the user has or needs 2FA, with a new session otherwise.
+func (li *loginIssuer) complete(ctx context.Context, user *db.User, userAgent string, clientIp string) (*response.LoginUserResponse, error) {
+	userTotp, err := li.twoFactor.enabled(ctx, li.repo, user.ID)
+	if err != nil {
+		logger.Error(ctx, "complete :: failed to get two-factor settings", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	// failed attempts are only reset once the second factor is given too, a
+	// known password must not allow unlimited guesses at codes
+	if userTotp != nil || li.twoFactor.required(user) {
+		return li.challenge(ctx, user, userTotp == nil)
+	}
+
+	return li.startSession(ctx, user, userAgent, clientIp)
+}
+
+// challenge answers a login that needs a second factor with a short-lived
+// token to exchange, along with a code, at LoginSecondFactor.
+func (li *loginIssuer) challenge(ctx context.Context, user *db.User, enrollmentRequired bool) (*response.LoginUserResponse, error) {
+	challengeToken, challengeClaims, err := li.tokenSvc.CreateToken(ctx, &request.CreateTokenRequest{
+		Type:      constants.TokenTypeChallenge,
+		UserId:    user.ID,
+		Email:     user.Email,
+		ExpiresIn: li.cfg.TwoFactor.ChallengeTTL,
+	})
+	if err != nil {
+		logger.Error(ctx, "challenge :: failed to create challenge token", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	return &response.LoginUserResponse{
+		Challenge: &response.LoginChallengeResponse{
+			SecondFactorRequired: true,
+			EnrollmentRequired:   enrollmentRequired,
+			ChallengeToken:       challengeToken,
+			ChallengeExpiresAt:   challengeClaims.ExpiresAt.Time,
+		},
+	}, nil
+}
+
+// startSession completes a login: it creates the session of user and its
+// tokens.
+func (li *loginIssuer) startSession(ctx context.Context, user *db.User, userAgent string, clientIp string) (*response.LoginUserResponse, error) {
+	err := li.loginGuard.succeed(ctx, user.Email)
+	if err != nil {
+		logger.Error(ctx, "startSession :: failed to reset login attempts", logger.Field("error", err.Error()))
+	}
+
+	sessionId, err := uuid.NewRandom()
+	if err != nil {
+		logger.Error(ctx, "startSession :: failed generating session id", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	accessToken, accessClaims, err := li.tokenSvc.CreateToken(ctx, &request.CreateTokenRequest{
+		Type:      constants.TokenTypeAccess,
+		UserId:    user.ID,
+		Email:     user.Email,
+		SessionId: sessionId,
+		ExpiresIn: li.cfg.Token.AccessTokenDuration,
+	})
+	if err != nil {
+		logger.Error(ctx, "startSession :: failed to create access token", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	refreshToken, refreshClaims, err := li.tokenSvc.CreateToken(ctx, &request.CreateTokenRequest{
+		Type:      constants.TokenTypeRefresh,
+		UserId:    user.ID,
+		Email:     user.Email,
+		SessionId: sessionId,
+		ExpiresIn: li.cfg.Token.RefreshTokenDuration,
+	})
+	if err != nil {
+		logger.Error(ctx, "startSession :: failed to create refresh token", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	arg := &db.CreateSessionParams{
+		ID:           sessionId,
+		Email:        user.Email,
+		UserAgent:    userAgent,
+		ClientIp:     clientIp,
+		RefreshToken: refreshToken,
+		ExpiresAt:    refreshClaims.ExpiresAt.Time,
+	}
+	refreshTokenId, err := uuid.Parse(refreshClaims.ID)
+	if err != nil {
+		logger.Error(ctx, "startSession :: failed parsing refresh token id", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	var session *db.Session
+	err = li.repo.ExecTx(ctx, func(q *db.Queries) error {
+		session, err = q.CreateSession(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		return q.CreateRefreshToken(ctx, &db.CreateRefreshTokenParams{
+			ID:        refreshTokenId,
+			SessionID: session.ID,
+			ExpiresAt: refreshClaims.ExpiresAt.Time,
+		})
+	})
+	if err != nil {
+		logger.Error(ctx, "startSession :: failed to create session", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	loginUserResponse := response.LoginUserResponse{
+		SessionId:             session.ID,
+		AccessToken:           accessToken,
+		AccessTokenExpiresAt:  accessClaims.ExpiresAt.Time,
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: refreshClaims.ExpiresAt.Time,
+		User:                  response.BuildUserResponse(user),
+	}
+	return &loginUserResponse, nil
+}
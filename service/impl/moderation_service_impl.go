@@ -0,0 +1,263 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"project/chat"
+	"project/config"
+	"project/constants"
+	db "project/db/sqlc"
+	"project/logger"
+	"project/models/request"
+	"project/models/response"
+	"project/moderation"
+	"project/service"
+)
+
+type ModerationServiceImpl struct {
+	repo db.Repository
+	hub  *chat.Hub
+}
+
+func ConfigureModerationService(cfg *config.StartupConfig, repo db.Repository, hub *chat.Hub) service.ModerationService {
+	return &ModerationServiceImpl{repo, hub}
+}
+
+// requireAdmin returns the requesting user when they administer the channel.
+func (svc *ModerationServiceImpl) requireAdmin(ctx context.Context, email string, channelId int64) (*db.User, error) {
+	user, err := svc.repo.GetUserByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = requireChannelAdmin(ctx, svc.repo, user.ID, channelId)
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// getPendingHeldMessage returns a held message of the channel that still
+// awaits review.
+func (svc *ModerationServiceImpl) getPendingHeldMessage(ctx context.Context, channelId int64, heldMessageId int64) (*db.HeldMessage, error) {
+	held, err := svc.repo.GetHeldMessageById(ctx, heldMessageId)
+	if err != nil {
+		return nil, err
+	}
+	if held.ChannelID != channelId {
+		return nil, constants.ErrNoRows
+	}
+	if held.Status != constants.HeldMessageStatusPending {
+		return nil, constants.ErrHeldMessageReviewed
+	}
+
+	return held, nil
+}
+
+// CreateModerationRule implements service.ModerationService.
+func (svc *ModerationServiceImpl) CreateModerationRule(ctx context.Context, req *request.CreateModerationRuleRequest) (*response.ModerationRuleResponse, error) {
+	user, err := svc.requireAdmin(ctx, req.Email, req.ChannelId)
+	if err != nil {
+		logger.Error(ctx, "CreateModerationRule :: channel admin required", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	action := req.Action
+	if len(action) == 0 {
+		action = moderation.ActionReject
+	}
+	pattern, err := moderation.ValidateRule(req.Kind, req.Pattern, action)
+	if err != nil {
+		return nil, err
+	}
+
+	rule, err := svc.repo.CreateModerationRule(ctx, &db.CreateModerationRuleParams{
+		ChannelID: req.ChannelId,
+		Kind:      req.Kind,
+		Pattern:   pattern,
+		Action:    action,
+		CreatedBy: user.ID,
+	})
+	if err != nil {
+		logger.Error(ctx, "CreateModerationRule :: failed to create rule", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	svc.hub.InvalidateModerationRules(req.ChannelId)
+	return response.BuildModerationRuleResponse(rule), nil
+}
+
+// GetModerationRules implements service.ModerationService.
+func (svc *ModerationServiceImpl) GetModerationRules(ctx context.Context, req *request.GetModerationRulesRequest) (*[]response.ModerationRuleResponse, error) {
+	_, err := svc.requireAdmin(ctx, req.Email, req.ChannelId)
+	if err != nil {
+		logger.Error(ctx, "GetModerationRules :: channel admin required", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	rules, err := svc.repo.GetModerationRulesByChannelId(ctx, req.ChannelId)
+	if err != nil {
+		logger.Error(ctx, "GetModerationRules :: failed to get rules", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	rulesResponse := make([]response.ModerationRuleResponse, 0)
+	for _, rule := range rules {
+		rulesResponse = append(rulesResponse, *response.BuildModerationRuleResponse(rule))
+	}
+
+	return &rulesResponse, nil
+}
+
+// DeleteModerationRule implements service.ModerationService.
+func (svc *ModerationServiceImpl) DeleteModerationRule(ctx context.Context, req *request.DeleteModerationRuleRequest) error {
+	_, err := svc.requireAdmin(ctx, req.Email, req.ChannelId)
+	if err != nil {
+		logger.Error(ctx, "DeleteModerationRule :: channel admin required", logger.Field("error", err.Error()))
+		return err
+	}
+
+	_, err = svc.repo.DeleteModerationRule(ctx, &db.DeleteModerationRuleParams{ID: req.RuleId, ChannelID: req.ChannelId})
+	if err != nil {
+		logger.Error(ctx, "DeleteModerationRule :: failed to delete rule", logger.Field("error", err.Error()))
+		return err
+	}
+
+	svc.hub.InvalidateModerationRules(req.ChannelId)
+	return nil
+}
+
+// GetHeldMessages implements service.ModerationService. Only messages
+// awaiting review are listed, oldest first.
+func (svc *ModerationServiceImpl) GetHeldMessages(ctx context.Context, req *request.GetHeldMessagesRequest) (*[]response.HeldMessageResponse, error) {
+	_, err := svc.requireAdmin(ctx, req.Email, req.ChannelId)
+	if err != nil {
+		logger.Error(ctx, "GetHeldMessages :: channel admin required", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	rows, err := svc.repo.GetPendingHeldMessagesByChannelId(ctx, req.ChannelId)
+	if err != nil {
+		logger.Error(ctx, "GetHeldMessages :: failed to get held messages", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	heldResponse := make([]response.HeldMessageResponse, 0)
+	for _, row := range rows {
+		held := &db.HeldMessage{
+			ID:               row.ID,
+			ChannelID:        row.ChannelID,
+			UserID:           row.UserID,
+			Content:          row.Content,
+			TtlSeconds:       row.TtlSeconds,
+			IsBot:            row.IsBot,
+			UsernameOverride: row.UsernameOverride,
+			Attachments:      row.Attachments,
+			WebhookID:        row.WebhookID,
+			Filter:           row.Filter,
+			Reason:           row.Reason,
+			Status:           row.Status,
+			ReviewedBy:       row.ReviewedBy,
+			ReviewedAt:       row.ReviewedAt,
+			MessageID:        row.MessageID,
+			CreatedAt:        row.CreatedAt,
+		}
+		heldResponse = append(heldResponse, *response.BuildHeldMessageResponse(held, row.Username))
+	}
+
+	return &heldResponse, nil
+}
+
+// ApproveHeldMessage implements service.ModerationService. The message is
+// posted as its author would have, without going through moderation again,
+// and marked approved in the same transaction, so concurrent reviews cannot
+// post it twice.
+func (svc *ModerationServiceImpl) ApproveHeldMessage(ctx context.Context, req *request.ApproveHeldMessageRequest) (*response.HeldMessageResponse, error) {
+	user, err := svc.requireAdmin(ctx, req.Email, req.ChannelId)
+	if err != nil {
+		logger.Error(ctx, "ApproveHeldMessage :: channel admin required", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	held, err := svc.getPendingHeldMessage(ctx, req.ChannelId, req.HeldMessageId)
+	if err != nil {
+		logger.Error(ctx, "ApproveHeldMessage :: failed to get held message", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	author, err := svc.repo.GetUserById(ctx, held.UserID)
+	if err != nil {
+		logger.Error(ctx, "ApproveHeldMessage :: failed to get author", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	var approved *db.HeldMessage
+	_, err = svc.hub.PostReviewedMessage(ctx, author.Username, &db.CreateMessageParams{
+		ChannelID:        held.ChannelID,
+		UserID:           held.UserID,
+		Content:          held.Content,
+		TtlSeconds:       held.TtlSeconds,
+		IsBot:            held.IsBot,
+		UsernameOverride: held.UsernameOverride,
+		Attachments:      held.Attachments,
+		WebhookID:        held.WebhookID,
+	}, func(q *db.Queries, message *db.Message) (interface{}, error) {
+		var err error
+		approved, err = q.ApproveHeldMessage(ctx, &db.ApproveHeldMessageParams{
+			ReviewedBy: &user.ID,
+			MessageID:  &message.ID,
+			ID:         held.ID,
+		})
+		if errors.Is(err, constants.ErrNoRows) {
+			return nil, constants.ErrHeldMessageReviewed
+		}
+		return nil, err
+	})
+	if err != nil {
+		logger.Error(ctx, "ApproveHeldMessage :: failed to post message", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	return response.BuildHeldMessageResponse(approved, author.Username), nil
+}
+
+// RejectHeldMessage implements service.ModerationService. The author is told
+// on their connections to the channel, with the reason when one is given.
+func (svc *ModerationServiceImpl) RejectHeldMessage(ctx context.Context, req *request.RejectHeldMessageRequest) (*response.HeldMessageResponse, error) {
+	user, err := svc.requireAdmin(ctx, req.Email, req.ChannelId)
+	if err != nil {
+		logger.Error(ctx, "RejectHeldMessage :: channel admin required", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	held, err := svc.getPendingHeldMessage(ctx, req.ChannelId, req.HeldMessageId)
+	if err != nil {
+		logger.Error(ctx, "RejectHeldMessage :: failed to get held message", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	rejected, err := svc.repo.RejectHeldMessage(ctx, &db.RejectHeldMessageParams{ReviewedBy: &user.ID, ID: held.ID})
+	if err != nil {
+		if errors.Is(err, constants.ErrNoRows) {
+			err = constants.ErrHeldMessageReviewed
+		}
+		logger.Error(ctx, "RejectHeldMessage :: failed to reject held message", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	notice := constants.ErrMessageRejected.Error()
+	if len(req.Reason) > 0 {
+		notice = fmt.Sprintf("%s: %s", notice, req.Reason)
+	}
+	svc.hub.WriteBroadcast <- &chat.Message{
+		Type:        chat.EVENT_MESSAGE_REJECTED,
+		Content:     notice,
+		ChannelId:   held.ChannelID,
+		RecipientId: held.UserID,
+		Payload:     map[string]int64{"heldMessageId": held.ID},
+	}
+
+	return response.BuildHeldMessageResponse(rejected, ""), nil
+}
@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	"project/chat"
+	"project/config"
+	"project/constants"
+	db "project/db/sqlc"
+	"project/logger"
+	"project/models/request"
+	"project/models/response"
+	"project/service"
+)
+
+type PinServiceImpl struct {
+	repo              db.Repository
+	hub               *chat.Hub
+	maxPinsPerChannel int64
+}
+
+func ConfigurePinService(cfg *config.StartupConfig, repo db.Repository, hub *chat.Hub) service.PinService {
+	return &PinServiceImpl{repo, hub, cfg.Chat.MaxPinsPerChannel}
+}
+
+// GetPins implements service.PinService.
+func (svc *PinServiceImpl) GetPins(ctx context.Context, req *request.GetPinsRequest) (*[]response.PinResponse, error) {
+	user, err := svc.repo.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		logger.Error(ctx, "GetPins :: failed to get user", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	_, err = getChannelMembership(ctx, svc.repo, user.ID, req.ChannelId)
+	if err != nil {
+		logger.Error(ctx, "GetPins :: failed to get membership", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	pins, err := svc.repo.GetPinsByChannelId(ctx, req.ChannelId)
+	if err != nil {
+		logger.Error(ctx, "GetPins :: failed to get pins", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	pinResp := make([]response.PinResponse, 0)
+	for _, pin := range pins {
+		pinResp = append(pinResp, *response.BuildPinResponseFromRow(pin))
+	}
+
+	return &pinResp, nil
+}
+
+// PinMessage implements service.PinService.
+func (svc *PinServiceImpl) PinMessage(ctx context.Context, req *request.PinMessageRequest) (*response.PinResponse, error) {
+	user, err := svc.repo.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		logger.Error(ctx, "PinMessage :: failed to get user", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	_, err = requireChannelAdmin(ctx, svc.repo, user.ID, req.ChannelId)
+	if err != nil {
+		logger.Error(ctx, "PinMessage :: channel admin required", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	message, err := svc.repo.GetMessageById(ctx, req.MessageId)
+	if err != nil {
+		logger.Error(ctx, "PinMessage :: failed to get message", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	if message.ChannelID != req.ChannelId {
+		return nil, constants.ErrMessageNotInChannel
+	}
+
+	author, err := svc.repo.GetUserById(ctx, message.UserID)
+	if err != nil {
+		logger.Error(ctx, "PinMessage :: failed to get message author", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	var pin *db.Pin
+	err = svc.repo.ExecTx(ctx, func(q *db.Queries) error {
+		// lock the channel row so concurrent pins cannot exceed the cap
+		_, err := q.GetChannelByIdForUpdate(ctx, req.ChannelId)
+		if err != nil {
+			return err
+		}
+
+		count, err := q.CountPinsByChannelId(ctx, req.ChannelId)
+		if err != nil {
+			return err
+		}
+
+		if count >= svc.maxPinsPerChannel {
+			return constants.ErrPinLimitReached
+		}
+
+		pin, err = q.CreatePin(ctx, &db.CreatePinParams{
+			ChannelID: req.ChannelId,
+			MessageID: message.ID,
+			PinnedBy:  user.ID,
+		})
+		return err
+	})
+	if err != nil {
+		logger.Error(ctx, "PinMessage :: failed to create pin", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	pinResp := response.BuildPinResponse(pin, message, author)
+	svc.hub.WriteBroadcast <- &chat.Message{
+		Type:      chat.EVENT_PIN_ADDED,
+		ChannelId: req.ChannelId,
+		Username:  user.Username,
+		Payload:   pinResp,
+	}
+
+	return pinResp, nil
+}
+
+// UnpinMessage implements service.PinService.
+func (svc *PinServiceImpl) UnpinMessage(ctx context.Context, req *request.UnpinMessageRequest) error {
+	user, err := svc.repo.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		logger.Error(ctx, "UnpinMessage :: failed to get user", logger.Field("error", err.Error()))
+		return err
+	}
+
+	_, err = requireChannelAdmin(ctx, svc.repo, user.ID, req.ChannelId)
+	if err != nil {
+		logger.Error(ctx, "UnpinMessage :: channel admin required", logger.Field("error", err.Error()))
+		return err
+	}
+
+	pin, err := svc.repo.DeletePin(ctx, &db.DeletePinParams{ChannelID: req.ChannelId, MessageID: req.MessageId})
+	if err != nil {
+		logger.Error(ctx, "UnpinMessage :: failed to delete pin", logger.Field("error", err.Error()))
+		return err
+	}
+
+	svc.hub.WriteBroadcast <- &chat.Message{
+		Type:      chat.EVENT_PIN_REMOVED,
+		ChannelId: req.ChannelId,
+		Username:  user.Username,
+		Payload:   map[string]int64{"id": pin.ID, "messageId": pin.MessageID},
+	}
+
+	return nil
+}
+
+// GetSavedItems implements service.PinService.
+func (svc *PinServiceImpl) GetSavedItems(ctx context.Context, req *request.GetSavedItemsRequest) (*[]response.SavedItemResponse, error) {
+	user, err := svc.repo.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		logger.Error(ctx, "GetSavedItems :: failed to get user", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	savedItems, err := svc.repo.GetSavedItemsByUserId(ctx, user.ID)
+	if err != nil {
+		logger.Error(ctx, "GetSavedItems :: failed to get saved items", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	savedItemResp := make([]response.SavedItemResponse, 0)
+	for _, savedItem := range savedItems {
+		savedItemResp = append(savedItemResp, *response.BuildSavedItemResponseFromRow(savedItem))
+	}
+
+	return &savedItemResp, nil
+}
+
+// SaveMessage implements service.PinService.
+func (svc *PinServiceImpl) SaveMessage(ctx context.Context, req *request.SaveMessageRequest) (*response.SavedItemResponse, error) {
+	user, err := svc.repo.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		logger.Error(ctx, "SaveMessage :: failed to get user", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	message, err := svc.repo.GetMessageById(ctx, req.MessageId)
+	if err != nil {
+		logger.Error(ctx, "SaveMessage :: failed to get message", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	_, err = getChannelMembership(ctx, svc.repo, user.ID, message.ChannelID)
+	if err != nil {
+		logger.Error(ctx, "SaveMessage :: failed to get membership", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	author, err := svc.repo.GetUserById(ctx, message.UserID)
+	if err != nil {
+		logger.Error(ctx, "SaveMessage :: failed to get message author", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	savedItem, err := svc.repo.CreateSavedItem(ctx, &db.CreateSavedItemParams{UserID: user.ID, MessageID: message.ID})
+	if err != nil {
+		logger.Error(ctx, "SaveMessage :: failed to create saved item", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	return response.BuildSavedItemResponse(savedItem, message, author), nil
+}
+
+// UnsaveMessage implements service.PinService.
+func (svc *PinServiceImpl) UnsaveMessage(ctx context.Context, req *request.UnsaveMessageRequest) error {
+	user, err := svc.repo.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		logger.Error(ctx, "UnsaveMessage :: failed to get user", logger.Field("error", err.Error()))
+		return err
+	}
+
+	_, err = svc.repo.DeleteSavedItem(ctx, &db.DeleteSavedItemParams{UserID: user.ID, MessageID: req.MessageId})
+	if err != nil {
+		logger.Error(ctx, "UnsaveMessage :: failed to delete saved item", logger.Field("error", err.Error()))
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,383 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"project/chat"
+	"project/config"
+	"project/constants"
+	db "project/db/sqlc"
+	"project/logger"
+	"project/models/request"
+	"project/models/response"
+	"project/service"
+	"time"
+)
+
+type PollServiceImpl struct {
+	repo      db.Repository
+	hub       *chat.Hub
+	batchSize int32
+}
+
+func ConfigurePollService(cfg *config.StartupConfig, repo db.Repository, hub *chat.Hub) service.PollService {
+	return &PollServiceImpl{repo, hub, cfg.Jobs.BatchSize}
+}
+
+// buildPollResponse loads the options and current tallies of the poll.
+func (svc *PollServiceImpl) buildPollResponse(ctx context.Context, poll *db.Poll) (*response.PollResponse, error) {
+	options, err := svc.repo.GetPollOptions(ctx, poll.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	votes, err := svc.repo.CountPollVotes(ctx, poll.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	totalVoters, err := svc.repo.CountPollVoters(ctx, poll.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var voters []*db.GetPollVotersRow
+	if !poll.Anonymous {
+		voters, err = svc.repo.GetPollVoters(ctx, poll.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return response.BuildPollResponse(poll, options, votes, voters, totalVoters), nil
+}
+
+// getPollForMember returns the poll when the user belongs to its channel.
+func (svc *PollServiceImpl) getPollForMember(ctx context.Context, userId int64, pollId int64) (*db.Poll, *db.Membership, error) {
+	poll, err := svc.repo.GetPollById(ctx, pollId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	membership, err := getChannelMembership(ctx, svc.repo, userId, poll.ChannelID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return poll, membership, nil
+}
+
+// broadcastPoll pushes the tallies to the channel and returns the response
+// of the requesting user. Broadcasts carry no username so anonymous votes
+// cannot be traced back to the voter.
+func (svc *PollServiceImpl) broadcastPoll(ctx context.Context, eventType string, poll *db.Poll, userId int64) (*response.PollResponse, error) {
+	pollResp, err := svc.buildPollResponse(ctx, poll)
+	if err != nil {
+		return nil, err
+	}
+
+	svc.hub.WriteBroadcast <- &chat.Message{
+		Type:      eventType,
+		ChannelId: poll.ChannelID,
+		Payload:   pollResp,
+	}
+
+	myVotes, err := svc.repo.GetUserPollVotes(ctx, &db.GetUserPollVotesParams{PollID: poll.ID, UserID: userId})
+	if err != nil {
+		return nil, err
+	}
+
+	// the broadcast payload is marshalled by the hub, answer with a copy
+	userResp := *pollResp
+	userResp.MyVotes = myVotes
+	return &userResp, nil
+}
+
+// CreatePoll implements service.PollService. The poll is posted as a message
+// of type poll whose payload carries the options.
+func (svc *PollServiceImpl) CreatePoll(ctx context.Context, req *request.CreatePollRequest) (*response.PollResponse, error) {
+	user, err := svc.repo.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		logger.Error(ctx, "CreatePoll :: failed to get user", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	membership, err := getChannelMembership(ctx, svc.repo, user.ID, req.ChannelId)
+	if err != nil {
+		logger.Error(ctx, "CreatePoll :: failed to get membership", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	if membership.MutedUntil != nil && time.Now().Before(*membership.MutedUntil) {
+		return nil, constants.ErrChannelMuted
+	}
+	if req.ClosesAt != nil && !req.ClosesAt.After(time.Now()) {
+		return nil, constants.ErrPollClosesInPast
+	}
+
+	arg := &db.CreateMessageParams{
+		ChannelID: req.ChannelId,
+		UserID:    user.ID,
+		Content:   req.Question,
+		IsBot:     user.IsBot,
+	}
+	msg, err := svc.hub.PostMessageWith(ctx, user.Username, arg, chat.MESSAGE_POLL, func(q *db.Queries, message *db.Message) (interface{}, error) {
+		poll, err := q.CreatePoll(ctx, &db.CreatePollParams{
+			MessageID:      message.ID,
+			ChannelID:      message.ChannelID,
+			CreatedBy:      user.ID,
+			Question:       req.Question,
+			MultipleChoice: req.MultipleChoice,
+			Anonymous:      req.Anonymous,
+			ClosesAt:       req.ClosesAt,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		options := make([]*db.PollOption, 0, len(req.Options))
+		for i, text := range req.Options {
+			option, err := q.CreatePollOption(ctx, &db.CreatePollOptionParams{
+				PollID:   poll.ID,
+				Position: int32(i),
+				Text:     text,
+			})
+			if err != nil {
+				return nil, err
+			}
+			options = append(options, option)
+		}
+
+		return response.BuildPollResponse(poll, options, nil, nil, 0), nil
+	})
+	if err != nil {
+		logger.Error(ctx, "CreatePoll :: failed to create poll", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	return msg.Payload.(*response.PollResponse), nil
+}
+
+// GetPoll implements service.PollService.
+func (svc *PollServiceImpl) GetPoll(ctx context.Context, req *request.GetPollRequest) (*response.PollResponse, error) {
+	user, err := svc.repo.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		logger.Error(ctx, "GetPoll :: failed to get user", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	poll, _, err := svc.getPollForMember(ctx, user.ID, req.PollId)
+	if err != nil {
+		logger.Error(ctx, "GetPoll :: failed to get poll", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	pollResp, err := svc.buildPollResponse(ctx, poll)
+	if err != nil {
+		logger.Error(ctx, "GetPoll :: failed to build poll", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	pollResp.MyVotes, err = svc.repo.GetUserPollVotes(ctx, &db.GetUserPollVotesParams{PollID: poll.ID, UserID: user.ID})
+	if err != nil {
+		logger.Error(ctx, "GetPoll :: failed to get votes", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	return pollResp, nil
+}
+
+// VotePoll implements service.PollService. A vote replaces the previous
+// choice of the user. The poll row is locked while voting, so concurrent
+// votes of one user cannot end up with two choices on a single choice poll.
+func (svc *PollServiceImpl) VotePoll(ctx context.Context, req *request.VotePollRequest) (*response.PollResponse, error) {
+	user, err := svc.repo.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		logger.Error(ctx, "VotePoll :: failed to get user", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	_, _, err = svc.getPollForMember(ctx, user.ID, req.PollId)
+	if err != nil {
+		logger.Error(ctx, "VotePoll :: failed to get poll", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	optionIds := make([]int64, 0, len(req.OptionIds))
+	seen := make(map[int64]bool)
+	for _, id := range req.OptionIds {
+		if !seen[id] {
+			seen[id] = true
+			optionIds = append(optionIds, id)
+		}
+	}
+
+	var poll *db.Poll
+	err = svc.repo.ExecTx(ctx, func(q *db.Queries) error {
+		var err error
+		poll, err = q.GetPollByIdForUpdate(ctx, req.PollId)
+		if err != nil {
+			return err
+		}
+
+		if response.IsPollClosed(poll) {
+			return constants.ErrPollClosed
+		}
+		if !poll.MultipleChoice && len(optionIds) > 1 {
+			return constants.ErrSingleChoicePoll
+		}
+
+		options, err := q.GetPollOptions(ctx, poll.ID)
+		if err != nil {
+			return err
+		}
+		valid := make(map[int64]bool)
+		for _, option := range options {
+			valid[option.ID] = true
+		}
+		for _, id := range optionIds {
+			if !valid[id] {
+				return constants.ErrInvalidPollOption
+			}
+		}
+
+		err = q.DeleteUserPollVotes(ctx, &db.DeleteUserPollVotesParams{PollID: poll.ID, UserID: user.ID})
+		if err != nil {
+			return err
+		}
+
+		for _, id := range optionIds {
+			err = q.CreatePollVote(ctx, &db.CreatePollVoteParams{PollID: poll.ID, OptionID: id, UserID: user.ID})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		logger.Error(ctx, "VotePoll :: failed to vote", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	pollResp, err := svc.broadcastPoll(ctx, chat.EVENT_POLL_UPDATED, poll, user.ID)
+	if err != nil {
+		logger.Error(ctx, "VotePoll :: failed to broadcast poll", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	return pollResp, nil
+}
+
+// RetractPollVote implements service.PollService.
+func (svc *PollServiceImpl) RetractPollVote(ctx context.Context, req *request.RetractPollVoteRequest) (*response.PollResponse, error) {
+	user, err := svc.repo.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		logger.Error(ctx, "RetractPollVote :: failed to get user", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	_, _, err = svc.getPollForMember(ctx, user.ID, req.PollId)
+	if err != nil {
+		logger.Error(ctx, "RetractPollVote :: failed to get poll", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	var poll *db.Poll
+	err = svc.repo.ExecTx(ctx, func(q *db.Queries) error {
+		var err error
+		poll, err = q.GetPollByIdForUpdate(ctx, req.PollId)
+		if err != nil {
+			return err
+		}
+
+		if response.IsPollClosed(poll) {
+			return constants.ErrPollClosed
+		}
+
+		return q.DeleteUserPollVotes(ctx, &db.DeleteUserPollVotesParams{PollID: poll.ID, UserID: user.ID})
+	})
+	if err != nil {
+		logger.Error(ctx, "RetractPollVote :: failed to retract vote", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	pollResp, err := svc.broadcastPoll(ctx, chat.EVENT_POLL_UPDATED, poll, user.ID)
+	if err != nil {
+		logger.Error(ctx, "RetractPollVote :: failed to broadcast poll", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	return pollResp, nil
+}
+
+// ClosePoll implements service.PollService. Polls are closed by their
+// creator or a channel admin.
+func (svc *PollServiceImpl) ClosePoll(ctx context.Context, req *request.ClosePollRequest) (*response.PollResponse, error) {
+	user, err := svc.repo.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		logger.Error(ctx, "ClosePoll :: failed to get user", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	poll, membership, err := svc.getPollForMember(ctx, user.ID, req.PollId)
+	if err != nil {
+		logger.Error(ctx, "ClosePoll :: failed to get poll", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	if poll.CreatedBy != user.ID && membership.Role != constants.MembershipRoleAdmin {
+		return nil, constants.ErrNotChannelAdmin
+	}
+	if response.IsPollClosed(poll) {
+		return nil, constants.ErrPollClosed
+	}
+
+	poll, err = svc.repo.ClosePoll(ctx, poll.ID)
+	if err != nil {
+		logger.Error(ctx, "ClosePoll :: failed to close poll", logger.Field("error", err.Error()))
+		// closed concurrently
+		if errors.Is(err, constants.ErrNoRows) {
+			return nil, constants.ErrPollClosed
+		}
+		return nil, err
+	}
+
+	pollResp, err := svc.broadcastPoll(ctx, chat.EVENT_POLL_CLOSED, poll, user.ID)
+	if err != nil {
+		logger.Error(ctx, "ClosePoll :: failed to broadcast poll", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	return pollResp, nil
+}
+
+// CloseDuePolls implements service.PollService. It closes polls whose
+// closing time has passed and announces the final tallies, in batches until a
+// short one shows the backlog is drained.
+func (svc *PollServiceImpl) CloseDuePolls(ctx context.Context) error {
+	for {
+		polls, err := svc.repo.CloseDuePolls(ctx, svc.batchSize)
+		if err != nil {
+			logger.Error(ctx, "CloseDuePolls :: failed to close polls", logger.Field("error", err.Error()))
+			return err
+		}
+
+		for _, poll := range polls {
+			pollResp, err := svc.buildPollResponse(ctx, poll)
+			if err != nil {
+				logger.Error(ctx, "CloseDuePolls :: failed to build poll", logger.Field("pollId", poll.ID), logger.Field("error", err.Error()))
+				continue
+			}
+
+			svc.hub.WriteBroadcast <- &chat.Message{
+				Type:      chat.EVENT_POLL_CLOSED,
+				ChannelId: poll.ChannelID,
+				Payload:   pollResp,
+			}
+		}
+
+		if len(polls) < int(svc.batchSize) {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"io"
+	"os"
+	"project/avatar"
+	"project/chat"
+	"project/config"
+	"project/constants"
+	db "project/db/sqlc"
+	"project/logger"
+	"project/models/request"
+	"project/models/response"
+	"project/service"
+	"strings"
+	"time"
+	// time zones are checked against the embedded database, images may not
+	// ship one
+	_ "time/tzdata"
+)
+
+type ProfileServiceImpl struct {
+	cfg     *config.StartupConfig
+	repo    db.Repository
+	hub     *chat.Hub
+	avatars *avatar.Store
+}
+
+func ConfigureProfileService(cfg *config.StartupConfig, repo db.Repository, hub *chat.Hub, avatars *avatar.Store) service.ProfileService {
+	return &ProfileServiceImpl{cfg, repo, hub, avatars}
+}
+
+// UpdateProfile implements service.ProfileService.
+func (svc *ProfileServiceImpl) UpdateProfile(ctx context.Context, req *request.UpdateProfileRequest) (*response.UserResponse, error) {
+	user, err := svc.repo.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		logger.Error(ctx, "UpdateProfile :: failed to get user", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	timeZone := profileField(req.TimeZone, user.TimeZone)
+	if timeZone != nil {
+		// Local is the zone of the server, not one of the user
+		if _, err := time.LoadLocation(*timeZone); err != nil || *timeZone == "Local" {
+			return nil, constants.ErrInvalidTimeZone
+		}
+	}
+
+	user, err = svc.repo.UpdateUserProfile(ctx, &db.UpdateUserProfileParams{
+		DisplayName: profileField(req.DisplayName, user.DisplayName),
+		Bio:         profileField(req.Bio, user.Bio),
+		TimeZone:    timeZone,
+		Phone:       profileField(req.Phone, user.Phone),
+		ID:          user.ID,
+	})
+	if err != nil {
+		logger.Error(ctx, "UpdateProfile :: failed to update profile", logger.Field("userId", user.ID), logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	svc.publishProfile(ctx, user)
+	return response.BuildUserResponse(user), nil
+}
+
+// UpdateAvatar implements service.ProfileService. Every upload gets a new
+// url, the previous avatar is deleted.
+func (svc *ProfileServiceImpl) UpdateAvatar(ctx context.Context, req *request.UpdateAvatarRequest, r io.Reader) (*response.UserResponse, error) {
+	user, err := svc.repo.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		logger.Error(ctx, "UpdateAvatar :: failed to get user", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	image, err := avatar.Resize(r, svc.cfg.Profile)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := svc.avatars.Save(image)
+	if err != nil {
+		logger.Error(ctx, "UpdateAvatar :: failed to store avatar", logger.Field("userId", user.ID), logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	return svc.replaceAvatar(ctx, user, &key)
+}
+
+// DeleteAvatar implements service.ProfileService.
+func (svc *ProfileServiceImpl) DeleteAvatar(ctx context.Context, req *request.DeleteAvatarRequest) (*response.UserResponse, error) {
+	user, err := svc.repo.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		logger.Error(ctx, "DeleteAvatar :: failed to get user", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	if user.AvatarKey == nil {
+		return response.BuildUserResponse(user), nil
+	}
+	return svc.replaceAvatar(ctx, user, nil)
+}
+
+// GetAvatar implements service.ProfileService. It returns the file of the
+// avatar named in the url.
+func (svc *ProfileServiceImpl) GetAvatar(ctx context.Context, req *request.GetAvatarRequest) (string, error) {
+	path, ok := svc.avatars.Path(req.File)
+	if !ok {
+		return "", constants.ErrNoRows
+	}
+	if _, err := os.Stat(path); err != nil {
+		return "", constants.ErrNoRows
+	}
+	return path, nil
+}
+
+// replaceAvatar sets the avatar of user to key, nil for none, and deletes
+// the file of the one it replaces.
+func (svc *ProfileServiceImpl) replaceAvatar(ctx context.Context, user *db.User, key *string) (*response.UserResponse, error) {
+	previousKey := user.AvatarKey
+
+	user, err := svc.repo.UpdateUserAvatar(ctx, &db.UpdateUserAvatarParams{AvatarKey: key, ID: user.ID})
+	if err != nil {
+		logger.Error(ctx, "replaceAvatar :: failed to update avatar", logger.Field("error", err.Error()))
+		svc.removeAvatar(ctx, key)
+		return nil, err
+	}
+
+	svc.removeAvatar(ctx, previousKey)
+	svc.publishProfile(ctx, user)
+	return response.BuildUserResponse(user), nil
+}
+
+func (svc *ProfileServiceImpl) removeAvatar(ctx context.Context, key *string) {
+	if key == nil {
+		return
+	}
+	if err := svc.avatars.Remove(*key); err != nil {
+		logger.Error(ctx, "removeAvatar :: failed to delete avatar", logger.Field("key", *key), logger.Field("error", err.Error()))
+	}
+}
+
+// publishProfile pushes the profile of user to the members of the channels
+// the user is in.
+func (svc *ProfileServiceImpl) publishProfile(ctx context.Context, user *db.User) {
+	memberships, err := svc.repo.GetMembershipsByUserId(ctx, user.ID)
+	if err != nil {
+		logger.Error(ctx, "publishProfile :: failed to get memberships", logger.Field("userId", user.ID), logger.Field("error", err.Error()))
+		return
+	}
+
+	profile := response.BuildProfileResponse(user)
+	for _, membership := range memberships {
+		svc.hub.WriteBroadcast <- &chat.Message{
+			Type:      chat.EVENT_USER_UPDATED,
+			ChannelId: membership.ChannelID,
+			Username:  user.Username,
+			Payload:   profile,
+		}
+	}
+}
+
+// profileField returns the value of a field after an update: current when
+// the update leaves it out, nil when it clears it.
+func profileField(update *string, current *string) *string {
+	if update == nil {
+		return current
+	}
+	value := strings.TrimSpace(*update)
+	if len(value) == 0 {
+		return nil
+	}
+	return &value
+}
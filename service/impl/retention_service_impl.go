@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"project/chat"
+	"project/config"
+	"project/constants"
+	db "project/db/sqlc"
+	"project/logger"
+	"project/models/response"
+	"project/service"
+	"time"
+)
+
+type RetentionServiceImpl struct {
+	repo      db.Repository
+	hub       *chat.Hub
+	retention config.RetentionConfig
+}
+
+func ConfigureRetentionService(cfg *config.StartupConfig, repo db.Repository, hub *chat.Hub) service.RetentionService {
+	return &RetentionServiceImpl{repo, hub, cfg.Retention}
+}
+
+// effectivePolicy resolves the policy of the channel, falling back to the
+// deployment policy for channels that inherit it.
+func (svc *RetentionServiceImpl) effectivePolicy(channel *db.Channel) (policy string, value int32, inherited bool) {
+	if channel.RetentionPolicy == constants.RetentionPolicyInherit || channel.RetentionPolicy == "" {
+		return svc.retention.Policy, svc.retention.Value, true
+	}
+
+	if channel.RetentionValue != nil {
+		value = *channel.RetentionValue
+	}
+	return channel.RetentionPolicy, value, false
+}
+
+// GetRetentionReport implements service.RetentionService. It is a dry run of
+// PurgeMessages: nothing is deleted.
+func (svc *RetentionServiceImpl) GetRetentionReport(ctx context.Context) (*response.RetentionReportResponse, error) {
+	channels, err := svc.repo.GetChannels(ctx)
+	if err != nil {
+		logger.Error(ctx, "GetRetentionReport :: failed to get channels", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	report := &response.RetentionReportResponse{
+		DefaultPolicy: svc.retention.Policy,
+		DefaultValue:  svc.retention.Value,
+		Channels:      make([]response.ChannelRetentionReport, 0),
+		GeneratedAt:   time.Now(),
+	}
+
+	for _, channel := range channels {
+		policy, value, inherited := svc.effectivePolicy(channel)
+		purgeable, err := svc.countPurgeable(ctx, channel.ID, policy, value)
+		if err != nil {
+			logger.Error(ctx, "GetRetentionReport :: failed to count purgeable messages", logger.Field("channelId", channel.ID), logger.Field("error", err.Error()))
+			return nil, err
+		}
+
+		report.TotalPurgeableMessages += purgeable
+		report.Channels = append(report.Channels, response.ChannelRetentionReport{
+			ChannelId:         channel.ID,
+			Name:              channel.Name,
+			Policy:            policy,
+			Value:             value,
+			Inherited:         inherited,
+			PurgeableMessages: purgeable,
+		})
+	}
+
+	return report, nil
+}
+
+func (svc *RetentionServiceImpl) countPurgeable(ctx context.Context, channelId int64, policy string, value int32) (int64, error) {
+	if value <= 0 {
+		return 0, nil
+	}
+
+	switch policy {
+	case constants.RetentionPolicyDays:
+		before := time.Now().AddDate(0, 0, -int(value))
+		return svc.repo.CountChannelMessagesBefore(ctx, &db.CountChannelMessagesBeforeParams{ChannelID: channelId, Before: before})
+
+	case constants.RetentionPolicyMessages:
+		cutoffId, err := svc.repo.GetRetentionCutoffMessageId(ctx, &db.GetRetentionCutoffMessageIdParams{ChannelID: channelId, Keep: value})
+		if err != nil {
+			if errors.Is(err, constants.ErrNoRows) {
+				return 0, nil
+			}
+			return 0, err
+		}
+		return svc.repo.CountChannelMessagesUpTo(ctx, &db.CountChannelMessagesUpToParams{ChannelID: channelId, MaxID: cutoffId})
+
+	default:
+		return 0, nil
+	}
+}
+
+// PurgeMessages implements service.RetentionService. Messages are deleted in
+// small batches, each its own statement, so no lock is held for long; rows
+// referencing a message (pins, saved items, reminders) go with it through
+// ON DELETE CASCADE. A channel that fails to purge does not stop the others,
+// its error is returned along with the rest once every channel was tried.
+func (svc *RetentionServiceImpl) PurgeMessages(ctx context.Context) error {
+	channels, err := svc.repo.GetChannels(ctx)
+	if err != nil {
+		logger.Error(ctx, "PurgeMessages :: failed to get channels", logger.Field("error", err.Error()))
+		return err
+	}
+
+	var errs []error
+	for _, channel := range channels {
+		policy, value, _ := svc.effectivePolicy(channel)
+		err := svc.purgeChannel(ctx, channel.ID, policy, value)
+		if err != nil {
+			logger.Error(ctx, "PurgeMessages :: failed to purge channel", logger.Field("channelId", channel.ID), logger.Field("error", err.Error()))
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (svc *RetentionServiceImpl) purgeChannel(ctx context.Context, channelId int64, policy string, value int32) error {
+	// a zero value would wipe the whole channel, treat it as a misconfiguration
+	if value <= 0 {
+		return nil
+	}
+
+	var purgeBatch func() ([]int64, error)
+	switch policy {
+	case constants.RetentionPolicyDays:
+		before := time.Now().AddDate(0, 0, -int(value))
+		purgeBatch = func() ([]int64, error) {
+			return svc.repo.PurgeChannelMessagesBefore(ctx, &db.PurgeChannelMessagesBeforeParams{
+				ChannelID: channelId,
+				Before:    before,
+				BatchSize: svc.retention.BatchSize,
+			})
+		}
+
+	case constants.RetentionPolicyMessages:
+		cutoffId, err := svc.repo.GetRetentionCutoffMessageId(ctx, &db.GetRetentionCutoffMessageIdParams{ChannelID: channelId, Keep: value})
+		if err != nil {
+			if errors.Is(err, constants.ErrNoRows) {
+				return nil
+			}
+			return err
+		}
+		purgeBatch = func() ([]int64, error) {
+			return svc.repo.PurgeChannelMessagesUpTo(ctx, &db.PurgeChannelMessagesUpToParams{
+				ChannelID: channelId,
+				MaxID:     cutoffId,
+				BatchSize: svc.retention.BatchSize,
+			})
+		}
+
+	default:
+		return nil
+	}
+
+	for {
+		messageIds, err := purgeBatch()
+		if err != nil {
+			return err
+		}
+
+		if len(messageIds) > 0 {
+			svc.hub.WriteBroadcast <- &chat.Message{
+				Type:      chat.EVENT_MESSAGE_EXPIRED,
+				ChannelId: channelId,
+				Payload:   map[string][]int64{"messageIds": messageIds},
+			}
+		}
+
+		if len(messageIds) < int(svc.retention.BatchSize) {
+			return nil
+		}
+		time.Sleep(svc.retention.BatchPause)
+	}
+}
@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"project/config"
+	"project/constants"
+	db "project/db/sqlc"
+	"project/logger"
+	"project/markdown"
+	"project/models/request"
+	"project/models/response"
+	"project/service"
+	"time"
+)
+
+type ScheduleServiceImpl struct {
+	repo             db.Repository
+	maxMessageLength int
+}
+
+func ConfigureScheduleService(cfg *config.StartupConfig, repo db.Repository) service.ScheduleService {
+	return &ScheduleServiceImpl{repo, cfg.Chat.MaxMessageLength}
+}
+
+// ScheduleMessage implements service.ScheduleService.
+func (svc *ScheduleServiceImpl) ScheduleMessage(ctx context.Context, req *request.ScheduleMessageRequest) (*response.ScheduledMessageResponse, error) {
+	if !req.SendAt.After(time.Now()) {
+		return nil, constants.ErrScheduleInPast
+	}
+
+	// rejected now rather than when it is due
+	content, _, err := markdown.Format(req.Content, svc.maxMessageLength)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := svc.repo.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		logger.Error(ctx, "ScheduleMessage :: failed to get user", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	_, err = getChannelMembership(ctx, svc.repo, user.ID, req.ChannelId)
+	if err != nil {
+		logger.Error(ctx, "ScheduleMessage :: failed to get membership", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	arg := &db.CreateScheduledMessageParams{
+		ChannelID: req.ChannelId,
+		UserID:    user.ID,
+		Content:   content,
+		SendAt:    req.SendAt,
+	}
+	scheduledMessage, err := svc.repo.CreateScheduledMessage(ctx, arg)
+	if err != nil {
+		logger.Error(ctx, "ScheduleMessage :: failed to create scheduled message", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	return response.BuildScheduledMessageResponse(scheduledMessage), nil
+}
+
+// GetScheduledMessages implements service.ScheduleService.
+func (svc *ScheduleServiceImpl) GetScheduledMessages(ctx context.Context, req *request.GetScheduledMessagesRequest) (*[]response.ScheduledMessageResponse, error) {
+	user, err := svc.repo.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		logger.Error(ctx, "GetScheduledMessages :: failed to get user", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	scheduledMessages, err := svc.repo.GetPendingScheduledMessagesByUserId(ctx, user.ID)
+	if err != nil {
+		logger.Error(ctx, "GetScheduledMessages :: failed to get scheduled messages", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	scheduledMessageResp := make([]response.ScheduledMessageResponse, 0)
+	for _, scheduledMessage := range scheduledMessages {
+		scheduledMessageResp = append(scheduledMessageResp, *response.BuildScheduledMessageResponse(scheduledMessage))
+	}
+
+	return &scheduledMessageResp, nil
+}
+
+// CancelScheduledMessage implements service.ScheduleService.
+func (svc *ScheduleServiceImpl) CancelScheduledMessage(ctx context.Context, req *request.CancelScheduledMessageRequest) error {
+	user, err := svc.repo.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		logger.Error(ctx, "CancelScheduledMessage :: failed to get user", logger.Field("error", err.Error()))
+		return err
+	}
+
+	// only pending rows owned by the user match, so sent messages cannot be cancelled
+	_, err = svc.repo.CancelScheduledMessage(ctx, &db.CancelScheduledMessageParams{ID: req.Id, UserID: user.ID})
+	if err != nil {
+		logger.Error(ctx, "CancelScheduledMessage :: failed to cancel scheduled message", logger.Field("error", err.Error()))
+		return err
+	}
+
+	return nil
+}
+
+// CreateReminder implements service.ScheduleService.
+func (svc *ScheduleServiceImpl) CreateReminder(ctx context.Context, req *request.CreateReminderRequest) (*response.ReminderResponse, error) {
+	if !req.RemindAt.After(time.Now()) {
+		return nil, constants.ErrScheduleInPast
+	}
+
+	user, err := svc.repo.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		logger.Error(ctx, "CreateReminder :: failed to get user", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	message, err := svc.repo.GetMessageById(ctx, req.MessageId)
+	if err != nil {
+		logger.Error(ctx, "CreateReminder :: failed to get message", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	_, err = getChannelMembership(ctx, svc.repo, user.ID, message.ChannelID)
+	if err != nil {
+		logger.Error(ctx, "CreateReminder :: failed to get membership", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	arg := &db.CreateReminderParams{
+		UserID:    user.ID,
+		MessageID: message.ID,
+		RemindAt:  req.RemindAt,
+	}
+	reminder, err := svc.repo.CreateReminder(ctx, arg)
+	if err != nil {
+		logger.Error(ctx, "CreateReminder :: failed to create reminder", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	return response.BuildReminderResponse(reminder), nil
+}
+
+// GetReminders implements service.ScheduleService.
+func (svc *ScheduleServiceImpl) GetReminders(ctx context.Context, req *request.GetRemindersRequest) (*[]response.ReminderResponse, error) {
+	user, err := svc.repo.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		logger.Error(ctx, "GetReminders :: failed to get user", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	reminders, err := svc.repo.GetPendingRemindersByUserId(ctx, user.ID)
+	if err != nil {
+		logger.Error(ctx, "GetReminders :: failed to get reminders", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	reminderResp := make([]response.ReminderResponse, 0)
+	for _, reminder := range reminders {
+		reminderResp = append(reminderResp, *response.BuildReminderResponse(reminder))
+	}
+
+	return &reminderResp, nil
+}
+
+// CancelReminder implements service.ScheduleService.
+func (svc *ScheduleServiceImpl) CancelReminder(ctx context.Context, req *request.CancelReminderRequest) error {
+	user, err := svc.repo.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		logger.Error(ctx, "CancelReminder :: failed to get user", logger.Field("error", err.Error()))
+		return err
+	}
+
+	_, err = svc.repo.CancelReminder(ctx, &db.CancelReminderParams{ID: req.Id, UserID: user.ID})
+	if err != nil {
+		logger.Error(ctx, "CancelReminder :: failed to cancel reminder", logger.Field("error", err.Error()))
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"project/chat"
+	"project/config"
+	"project/constants"
+	db "project/db/sqlc"
+	"project/denylist"
+	"project/logger"
+	"project/models/request"
+	"project/models/response"
+	"project/service"
+	"project/utils"
+
+	"github.com/google/uuid"
+)
+
+type SessionServiceImpl struct {
+	repo          db.Repository
+	hub           *chat.Hub
+	tokenDenylist *denylist.Denylist
+}
+
+func ConfigureSessionService(cfg *config.StartupConfig, repo db.Repository, hub *chat.Hub, tokenDenylist *denylist.Denylist) service.SessionService {
+	return &SessionServiceImpl{repo, hub, tokenDenylist}
+}
+
+// GetSessions implements service.SessionService. Only sessions that can
+// still renew tokens are listed.
+func (svc *SessionServiceImpl) GetSessions(ctx context.Context, req *request.GetSessionsRequest) (*[]response.SessionResponse, error) {
+	sessions, err := svc.repo.GetActiveSessionsByEmail(ctx, req.Email)
+	if err != nil {
+		logger.Error(ctx, "GetSessions :: failed to get sessions", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	sessionResp := make([]response.SessionResponse, 0)
+	for _, session := range sessions {
+		sessionResp = append(sessionResp, *response.BuildSessionResponse(session, req.CurrentSessionId))
+	}
+
+	return &sessionResp, nil
+}
+
+// RevokeSession implements service.SessionService. Sessions of other users
+// are reported as not found.
+func (svc *SessionServiceImpl) RevokeSession(ctx context.Context, req *request.RevokeSessionRequest) error {
+	sessionId, err := uuid.Parse(req.SessionId)
+	if err != nil {
+		return constants.ErrNoRows
+	}
+
+	session, err := svc.repo.GetSession(ctx, sessionId)
+	if err != nil {
+		logger.Error(ctx, "RevokeSession :: failed to get session", logger.Field("error", err.Error()))
+		return err
+	}
+
+	if session.Email != req.Email {
+		return constants.ErrNoRows
+	}
+
+	if session.IsLoggedOut || session.IsBlocked {
+		return nil
+	}
+
+	_, err = svc.repo.UpdateSession(ctx, &db.UpdateSessionParams{IsLoggedOut: utils.BoolPtr(true), ID: session.ID})
+	if err != nil {
+		logger.Error(ctx, "RevokeSession :: failed to update session", logger.Field("error", err.Error()))
+		return err
+	}
+
+	svc.terminate(ctx, session.ID)
+	return nil
+}
+
+// RevokeOtherSessions implements service.SessionService. It logs out every
+// session of the user but the one of the calling token.
+func (svc *SessionServiceImpl) RevokeOtherSessions(ctx context.Context, req *request.RevokeOtherSessionsRequest) (*response.RevokedSessionsResponse, error) {
+	sessionIds, err := svc.repo.LogoutOtherSessions(ctx, &db.LogoutOtherSessionsParams{Email: req.Email, ID: req.CurrentSessionId})
+	if err != nil {
+		logger.Error(ctx, "RevokeOtherSessions :: failed to log out sessions", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	svc.terminate(ctx, sessionIds...)
+	return &response.RevokedSessionsResponse{Revoked: len(sessionIds)}, nil
+}
+
+// AdminRevokeSession implements service.SessionService. The session is
+// blocked rather than logged out, so it shows as revoked by an admin.
+func (svc *SessionServiceImpl) AdminRevokeSession(ctx context.Context, req *request.AdminRevokeSessionRequest) error {
+	sessionId, err := uuid.Parse(req.SessionId)
+	if err != nil {
+		return constants.ErrNoRows
+	}
+
+	admin, user, err := svc.adminAndUser(ctx, req.Email, req.UserId)
+	if err != nil {
+		return err
+	}
+
+	session, err := svc.repo.GetSession(ctx, sessionId)
+	if err != nil {
+		logger.Error(ctx, "AdminRevokeSession :: failed to get session", logger.Field("error", err.Error()))
+		return err
+	}
+
+	if session.Email != user.Email {
+		return constants.ErrNoRows
+	}
+
+	_, err = svc.repo.UpdateSession(ctx, &db.UpdateSessionParams{IsBlocked: utils.BoolPtr(true), ID: session.ID})
+	if err != nil {
+		logger.Error(ctx, "AdminRevokeSession :: failed to update session", logger.Field("error", err.Error()))
+		return err
+	}
+
+	svc.terminate(ctx, session.ID)
+	writeAuditLog(ctx, svc.repo, &db.CreateAuditLogParams{
+		ActorID:      &admin.ID,
+		Action:       constants.AuditSessionsRevoked,
+		TargetUserID: &user.ID,
+		ClientIp:     &req.ClientIp,
+	}, map[string]any{"sessionIds": []uuid.UUID{session.ID}})
+
+	return nil
+}
+
+// AdminRevokeSessions implements service.SessionService. It blocks every
+// session of the user.
+func (svc *SessionServiceImpl) AdminRevokeSessions(ctx context.Context, req *request.AdminRevokeSessionsRequest) (*response.RevokedSessionsResponse, error) {
+	admin, user, err := svc.adminAndUser(ctx, req.Email, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	sessionIds, err := svc.repo.BlockSessionsByEmail(ctx, user.Email)
+	if err != nil {
+		logger.Error(ctx, "AdminRevokeSessions :: failed to block sessions", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	svc.terminate(ctx, sessionIds...)
+	writeAuditLog(ctx, svc.repo, &db.CreateAuditLogParams{
+		ActorID:      &admin.ID,
+		Action:       constants.AuditSessionsRevoked,
+		TargetUserID: &user.ID,
+		ClientIp:     &req.ClientIp,
+	}, map[string]any{"sessionIds": sessionIds})
+
+	return &response.RevokedSessionsResponse{Revoked: len(sessionIds)}, nil
+}
+
+func (svc *SessionServiceImpl) adminAndUser(ctx context.Context, adminEmail string, userId int64) (*db.User, *db.User, error) {
+	admin, err := svc.repo.GetUserByEmail(ctx, adminEmail)
+	if err != nil {
+		logger.Error(ctx, "adminAndUser :: failed to get admin", logger.Field("error", err.Error()))
+		return nil, nil, err
+	}
+
+	user, err := svc.repo.GetUserById(ctx, userId)
+	if err != nil {
+		logger.Error(ctx, "adminAndUser :: failed to get user", logger.Field("userId", userId), logger.Field("error", err.Error()))
+		return nil, nil, err
+	}
+
+	return admin, user, nil
+}
+
+func (svc *SessionServiceImpl) terminate(ctx context.Context, sessionIds ...uuid.UUID) {
+	terminateSessions(ctx, svc.tokenDenylist, svc.hub, sessionIds...)
+}
+
+// terminateSessions denies the access tokens of revoked sessions and closes
+// their websocket connections. The sessions are revoked already, a failure
+// only leaves tokens and connections working until they expire or drop.
+func terminateSessions(ctx context.Context, tokenDenylist *denylist.Denylist, hub *chat.Hub, sessionIds ...uuid.UUID) {
+	if len(sessionIds) == 0 {
+		return
+	}
+
+	err := tokenDenylist.DenySessions(ctx, sessionIds...)
+	if err != nil {
+		logger.Error(ctx, "terminateSessions :: failed to deny session tokens", logger.Field("error", err.Error()))
+	}
+
+	err = hub.RevokeSessions(ctx, sessionIds...)
+	if err != nil {
+		logger.Error(ctx, "terminateSessions :: failed to publish session revocation", logger.Field("error", err.Error()))
+	}
+}
@@ -0,0 +1,445 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"project/config"
+	"project/constants"
+	db "project/db/sqlc"
+	"project/logger"
+	"project/models/request"
+	"project/models/response"
+	"project/oidc"
+	"project/service"
+	"project/totp"
+	"project/utils"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const ssoStateKeyPrefix = "oidc:state:"
+
+// ssoState is what a login needs to remember while the user is away at the
+// identity provider. LinkUserId is set when linking an identity to an
+// account instead of logging in.
+type ssoState struct {
+	Provider     string `json:"provider"`
+	CodeVerifier string `json:"codeVerifier"`
+	Nonce        string `json:"nonce"`
+	LinkUserId   int64  `json:"linkUserId,omitempty"`
+}
+
+type SSOServiceImpl struct {
+	cfg         *config.StartupConfig
+	repo        db.Repository
+	redisClient *redis.Client
+	providers   map[string]*oidc.Provider
+	login       *loginIssuer
+}
+
+func ConfigureSSOService(cfg *config.StartupConfig, repo db.Repository, redisClient *redis.Client, tokenSvc service.TokenService, totpCipher *totp.Cipher) service.SSOService {
+	client := &http.Client{Timeout: cfg.OIDC.Timeout}
+	providers := make(map[string]*oidc.Provider, len(cfg.OIDC.Providers))
+	for _, providerCfg := range cfg.OIDC.Providers {
+		providers[providerCfg.Name] = oidc.NewProvider(providerCfg, client)
+	}
+	return &SSOServiceImpl{cfg, repo, redisClient, providers, newLoginIssuer(cfg, repo, redisClient, tokenSvc, totpCipher)}
+}
+
+// GetProviders implements service.SSOService.
+func (svc *SSOServiceImpl) GetProviders(ctx context.Context) (*[]response.SSOProviderResponse, error) {
+	providerResp := make([]response.SSOProviderResponse, 0, len(svc.cfg.OIDC.Providers))
+	for _, providerCfg := range svc.cfg.OIDC.Providers {
+		providerResp = append(providerResp, response.SSOProviderResponse{Name: providerCfg.Name, DisplayName: providerCfg.DisplayName})
+	}
+	return &providerResp, nil
+}
+
+// StartSSOLogin implements service.SSOService.
+func (svc *SSOServiceImpl) StartSSOLogin(ctx context.Context, req *request.StartSSOLoginRequest) (*response.AuthorizationResponse, error) {
+	provider, err := svc.provider(req.Provider)
+	if err != nil {
+		return nil, err
+	}
+	return svc.start(ctx, provider, 0)
+}
+
+// CompleteSSOLogin implements service.SSOService. The user is found by the
+// identity, or provisioned or linked by verified email on first login as the
+// provider allows. The login then ends like a password one, including the
+// second factor.
+func (svc *SSOServiceImpl) CompleteSSOLogin(ctx context.Context, req *request.CompleteSSOLoginRequest) (*response.LoginUserResponse, error) {
+	provider, err := svc.provider(req.Provider)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := svc.takeState(ctx, req.Provider, req.State)
+	if err != nil {
+		return nil, err
+	}
+	if state.LinkUserId != 0 {
+		return nil, constants.ErrInvalidSSOState
+	}
+
+	claims, err := svc.authenticate(ctx, provider, state, req.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := svc.resolveUser(ctx, provider.Config(), claims, req.ClientIp)
+	if err != nil {
+		return nil, err
+	}
+
+	// a locked account stays locked whichever way the user logs in
+	err = svc.login.loginGuard.check(ctx, user.Email, req.ClientIp)
+	if errors.Is(err, constants.ErrAccountLocked) || errors.Is(err, constants.ErrLoginThrottled) {
+		return nil, err
+	}
+	if err != nil {
+		logger.Error(ctx, "CompleteSSOLogin :: failed to check login attempts", logger.Field("error", err.Error()))
+	}
+
+	return svc.login.complete(ctx, user, req.UserAgent, req.ClientIp)
+}
+
+// StartIdentityLink implements service.SSOService.
+func (svc *SSOServiceImpl) StartIdentityLink(ctx context.Context, req *request.StartIdentityLinkRequest) (*response.AuthorizationResponse, error) {
+	provider, err := svc.provider(req.Provider)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := svc.repo.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		logger.Error(ctx, "StartIdentityLink :: failed to get user", logger.Field("error", err.Error()))
+		return nil, err
+	}
+	// bots authenticate with api keys only
+	if user.IsBot {
+		return nil, constants.ErrAccessDenied
+	}
+
+	return svc.start(ctx, provider, user.ID)
+}
+
+// CompleteIdentityLink implements service.SSOService. The user proved both
+// accounts, so the email of the identity need not match nor be verified.
+func (svc *SSOServiceImpl) CompleteIdentityLink(ctx context.Context, req *request.CompleteIdentityLinkRequest) (*response.IdentityResponse, error) {
+	provider, err := svc.provider(req.Provider)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := svc.repo.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		logger.Error(ctx, "CompleteIdentityLink :: failed to get user", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	state, err := svc.takeState(ctx, req.Provider, req.State)
+	if err != nil {
+		return nil, err
+	}
+	// a link started by someone else must not attach their identity to us
+	if state.LinkUserId != user.ID {
+		return nil, constants.ErrInvalidSSOState
+	}
+
+	claims, err := svc.authenticate(ctx, provider, state, req.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	identity, err := svc.repo.CreateUserIdentity(ctx, &db.CreateUserIdentityParams{
+		UserID:   user.ID,
+		Provider: req.Provider,
+		Subject:  claims.Subject,
+		Email:    claims.Email,
+	})
+	if utils.ErrorCode(err) == constants.UniqueViolation {
+		return nil, constants.ErrIdentityLinked
+	}
+	if err != nil {
+		logger.Error(ctx, "CompleteIdentityLink :: failed to link identity", logger.Field("userId", user.ID), logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	writeAuditLog(ctx, svc.repo, &db.CreateAuditLogParams{
+		ActorID:      &user.ID,
+		Action:       constants.AuditIdentityLinked,
+		TargetUserID: &user.ID,
+		ClientIp:     &req.ClientIp,
+	}, map[string]any{"provider": req.Provider, "email": claims.Email})
+
+	return response.BuildIdentityResponse(identity), nil
+}
+
+// GetIdentities implements service.SSOService.
+func (svc *SSOServiceImpl) GetIdentities(ctx context.Context, req *request.GetIdentitiesRequest) (*[]response.IdentityResponse, error) {
+	user, err := svc.repo.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		logger.Error(ctx, "GetIdentities :: failed to get user", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	identities, err := svc.repo.GetUserIdentitiesByUserId(ctx, user.ID)
+	if err != nil {
+		logger.Error(ctx, "GetIdentities :: failed to get identities", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	identityResp := make([]response.IdentityResponse, 0)
+	for _, identity := range identities {
+		identityResp = append(identityResp, *response.BuildIdentityResponse(identity))
+	}
+
+	return &identityResp, nil
+}
+
+// UnlinkIdentity implements service.SSOService. Accounts without a password
+// keep at least one identity, or nothing could log in to them.
+func (svc *SSOServiceImpl) UnlinkIdentity(ctx context.Context, req *request.UnlinkIdentityRequest) error {
+	user, err := svc.repo.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		logger.Error(ctx, "UnlinkIdentity :: failed to get user", logger.Field("error", err.Error()))
+		return err
+	}
+
+	err = svc.repo.ExecTx(ctx, func(q *db.Queries) error {
+		count, err := q.CountUserIdentities(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+		if count <= 1 && user.HashedPassword == constants.UnusablePasswordHash {
+			return constants.ErrLastLoginMethod
+		}
+
+		deleted, err := q.DeleteUserIdentity(ctx, &db.DeleteUserIdentityParams{ID: req.IdentityId, UserID: user.ID})
+		if err != nil {
+			return err
+		}
+		if deleted == 0 {
+			return constants.ErrNoRows
+		}
+		return nil
+	})
+	if err != nil {
+		logger.Error(ctx, "UnlinkIdentity :: failed to unlink identity", logger.Field("identityId", req.IdentityId), logger.Field("error", err.Error()))
+		return err
+	}
+
+	writeAuditLog(ctx, svc.repo, &db.CreateAuditLogParams{
+		ActorID:      &user.ID,
+		Action:       constants.AuditIdentityUnlinked,
+		TargetUserID: &user.ID,
+		ClientIp:     &req.ClientIp,
+	}, map[string]any{"identityId": req.IdentityId})
+
+	return nil
+}
+
+func (svc *SSOServiceImpl) provider(name string) (*oidc.Provider, error) {
+	provider, ok := svc.providers[name]
+	if !ok {
+		return nil, constants.ErrUnknownProvider
+	}
+	return provider, nil
+}
+
+// start remembers a new login for StateTTL and returns where to send the
+// user.
+func (svc *SSOServiceImpl) start(ctx context.Context, provider *oidc.Provider, linkUserId int64) (*response.AuthorizationResponse, error) {
+	var values [3]string
+	for i := range values {
+		value, err := oidc.RandomString()
+		if err != nil {
+			logger.Error(ctx, "start :: failed generating state", logger.Field("error", err.Error()))
+			return nil, err
+		}
+		values[i] = value
+	}
+	stateKey, codeVerifier, nonce := values[0], values[1], values[2]
+
+	state, err := json.Marshal(&ssoState{
+		Provider:     provider.Config().Name,
+		CodeVerifier: codeVerifier,
+		Nonce:        nonce,
+		LinkUserId:   linkUserId,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	authorizationURL, err := provider.AuthCodeURL(ctx, stateKey, nonce, codeVerifier)
+	if err != nil {
+		logger.Error(ctx, "start :: failed to reach identity provider", logger.Field("provider", provider.Config().Name), logger.Field("error", err.Error()))
+		return nil, fmt.Errorf("%w: %v", constants.ErrSSOFailed, err)
+	}
+
+	err = svc.redisClient.Set(ctx, ssoStateKeyPrefix+stateKey, state, svc.cfg.OIDC.StateTTL).Err()
+	if err != nil {
+		logger.Error(ctx, "start :: failed to store state", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	return &response.AuthorizationResponse{AuthorizationURL: authorizationURL, State: stateKey}, nil
+}
+
+// takeState returns the login of stateKey, at most once.
+func (svc *SSOServiceImpl) takeState(ctx context.Context, providerName string, stateKey string) (*ssoState, error) {
+	raw, err := svc.redisClient.GetDel(ctx, ssoStateKeyPrefix+stateKey).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, constants.ErrInvalidSSOState
+	}
+	if err != nil {
+		logger.Error(ctx, "takeState :: failed to get state", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	state := &ssoState{}
+	if err := json.Unmarshal(raw, state); err != nil || state.Provider != providerName {
+		return nil, constants.ErrInvalidSSOState
+	}
+	return state, nil
+}
+
+// authenticate completes the login at the provider and applies its domain
+// restriction.
+func (svc *SSOServiceImpl) authenticate(ctx context.Context, provider *oidc.Provider, state *ssoState, code string) (*oidc.Claims, error) {
+	claims, err := provider.Authenticate(ctx, code, state.CodeVerifier, state.Nonce)
+	if err != nil {
+		logger.Error(ctx, "authenticate :: identity provider login failed", logger.Field("provider", state.Provider), logger.Field("error", err.Error()))
+		return nil, fmt.Errorf("%w: %v", constants.ErrSSOFailed, err)
+	}
+
+	allowedDomains := provider.Config().AllowedDomains
+	if len(allowedDomains) == 0 {
+		return claims, nil
+	}
+	if !claims.EmailVerified {
+		return nil, constants.ErrSSOEmailNotVerified
+	}
+	_, domain, _ := strings.Cut(claims.Email, "@")
+	for _, allowed := range allowedDomains {
+		if strings.EqualFold(domain, allowed) {
+			return claims, nil
+		}
+	}
+	return nil, constants.ErrSSODomainNotAllowed
+}
+
+// resolveUser returns the user the identity is linked to. An identity seen
+// for the first time needs a verified email; it is linked to the account of
+// that email with LinkByEmail, or gets a new account with AutoProvision.
+func (svc *SSOServiceImpl) resolveUser(ctx context.Context, providerCfg config.OIDCProviderConfig, claims *oidc.Claims, clientIp string) (*db.User, error) {
+	identity, err := svc.repo.GetUserIdentity(ctx, &db.GetUserIdentityParams{Provider: providerCfg.Name, Subject: claims.Subject})
+	if err == nil {
+		user, err := svc.repo.GetUserById(ctx, identity.UserID)
+		if err != nil {
+			logger.Error(ctx, "resolveUser :: failed to get user", logger.Field("error", err.Error()))
+			return nil, err
+		}
+
+		email := claims.Email
+		if len(email) == 0 {
+			email = identity.Email
+		}
+		err = svc.repo.TouchUserIdentity(ctx, &db.TouchUserIdentityParams{Email: email, ID: identity.ID})
+		if err != nil {
+			logger.Error(ctx, "resolveUser :: failed to update identity", logger.Field("identityId", identity.ID), logger.Field("error", err.Error()))
+		}
+		return user, nil
+	}
+	if !errors.Is(err, constants.ErrNoRows) {
+		logger.Error(ctx, "resolveUser :: failed to get identity", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	if len(claims.Email) == 0 || !claims.EmailVerified {
+		return nil, constants.ErrSSOEmailNotVerified
+	}
+
+	var user *db.User
+	action := constants.AuditIdentityLinked
+	err = svc.repo.ExecTx(ctx, func(q *db.Queries) error {
+		existing, err := q.GetUserByEmail(ctx, claims.Email)
+		switch {
+		case err == nil:
+			if !providerCfg.LinkByEmail || existing.IsBot {
+				return constants.ErrSSOAccountExists
+			}
+			user = existing
+		case errors.Is(err, constants.ErrNoRows):
+			if !providerCfg.AutoProvision {
+				return constants.ErrSSOAccountNotFound
+			}
+			username, err := availableUsername(ctx, q, ssoUsername(claims))
+			if err != nil {
+				return err
+			}
+			user, err = q.CreateUser(ctx, &db.CreateUserParams{
+				Username:       username,
+				Email:          claims.Email,
+				HashedPassword: constants.UnusablePasswordHash,
+			})
+			if err != nil {
+				return err
+			}
+			action = constants.AuditUserProvisioned
+		default:
+			return err
+		}
+
+		// the provider verified the address
+		user, err = q.VerifyUserEmail(ctx, &db.VerifyUserEmailParams{ID: user.ID, Email: user.Email})
+		if err != nil {
+			return err
+		}
+
+		identity, err = q.CreateUserIdentity(ctx, &db.CreateUserIdentityParams{
+			UserID:   user.ID,
+			Provider: providerCfg.Name,
+			Subject:  claims.Subject,
+			Email:    claims.Email,
+		})
+		if err != nil {
+			return err
+		}
+		return q.TouchUserIdentity(ctx, &db.TouchUserIdentityParams{Email: claims.Email, ID: identity.ID})
+	})
+	err = uniqueUserError(err)
+	// the same identity logging in twice at once
+	if utils.ErrorCode(err) == constants.UniqueViolation {
+		return nil, constants.ErrIdentityLinked
+	}
+	if err != nil {
+		logger.Error(ctx, "resolveUser :: failed to link identity", logger.Field("provider", providerCfg.Name), logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	writeAuditLog(ctx, svc.repo, &db.CreateAuditLogParams{
+		ActorID:      &user.ID,
+		Action:       action,
+		TargetUserID: &user.ID,
+		ClientIp:     &clientIp,
+	}, map[string]any{"provider": providerCfg.Name, "email": claims.Email})
+
+	return user, nil
+}
+
+func ssoUsername(claims *oidc.Claims) string {
+	if len(claims.PreferredUsername) > 0 {
+		return claims.PreferredUsername
+	}
+	if len(claims.Name) > 0 {
+		return claims.Name
+	}
+	localPart, _, _ := strings.Cut(claims.Email, "@")
+	return localPart
+}
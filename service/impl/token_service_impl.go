@@ -2,14 +2,20 @@ package service
 
 import (
 	"context"
+	"errors"
+	"project/chat"
 	"project/config"
 	"project/constants"
 	db "project/db/sqlc"
+	"project/denylist"
+	"project/jwtkeys"
 	"project/logger"
 	"project/models"
 	"project/models/request"
 	"project/models/response"
 	"project/service"
+	"project/utils"
+	"strconv"
 	"strings"
 	"time"
 
@@ -18,42 +24,52 @@ import (
 )
 
 type TokenServiceImpl struct {
-	jwtSecret           string
+	keys                *jwtkeys.Keyring
+	issuer              string
+	audience            string
 	repo                db.Repository
+	hub                 *chat.Hub
 	accessTokenDuration time.Duration
+	tokenDenylist       *denylist.Denylist
 }
 
-func ConfigureTokenService(cfg *config.StartupConfig, repo db.Repository) service.TokenService {
-	return &TokenServiceImpl{cfg.Token.JWTSecret, repo, cfg.Token.AccessTokenDuration}
+func ConfigureTokenService(cfg *config.StartupConfig, repo db.Repository, hub *chat.Hub, keys *jwtkeys.Keyring, tokenDenylist *denylist.Denylist) service.TokenService {
+	return &TokenServiceImpl{keys, cfg.Token.Issuer, cfg.Token.Audience, repo, hub, cfg.Token.AccessTokenDuration, tokenDenylist}
 }
 
-func NewJWTClaims(ctx context.Context, req *request.CreateTokenRequest) (*models.JWTClaims, error) {
+func (svc *TokenServiceImpl) NewJWTClaims(ctx context.Context, req *request.CreateTokenRequest) (*models.JWTClaims, error) {
 	tokenId, err := uuid.NewRandom()
 	if err != nil {
 		logger.Error(ctx, "NewJWTClaims :: failed generating uuid", logger.Field("error", err.Error()))
 		return nil, err
 	}
 
+	now := time.Now()
 	return &models.JWTClaims{
-		Id:       tokenId,
-		Email:    req.Email,
-		IssuedAt: time.Now(),
+		Type:      req.Type,
+		SessionId: req.SessionId,
+		Email:     req.Email,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(req.ExpiresIn)),
+			ID:        tokenId.String(),
+			Subject:   strconv.FormatInt(req.UserId, 10),
+			Issuer:    svc.issuer,
+			Audience:  jwt.ClaimStrings{svc.audience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(req.ExpiresIn)),
 		},
+		UserId: req.UserId,
 	}, nil
 }
 
 // CreateToken implements service.TokenService.
 func (svc *TokenServiceImpl) CreateToken(ctx context.Context, req *request.CreateTokenRequest) (string, *models.JWTClaims, error) {
-	jwtClaim, err := NewJWTClaims(ctx, req)
+	jwtClaim, err := svc.NewJWTClaims(ctx, req)
 	if err != nil {
 		logger.Error(ctx, "CreateToken :: failed creating jwt claims", logger.Field("error", err.Error()))
 		return "", nil, err
 	}
 
-	jwtToken := jwt.NewWithClaims(jwt.SigningMethodHS256, jwtClaim)
-	signedToken, err := jwtToken.SignedString([]byte(svc.jwtSecret))
+	signedToken, err := svc.keys.Sign(jwtClaim)
 	if err != nil {
 		logger.Error(ctx, "CreateToken :: failed signing jwt token", logger.Field("error", err.Error()))
 		return "", nil, err
@@ -62,15 +78,20 @@ func (svc *TokenServiceImpl) CreateToken(ctx context.Context, req *request.Creat
 	return signedToken, jwtClaim, nil
 }
 
-// VerifyToken implements service.TokenService.
-func (svc *TokenServiceImpl) VerifyToken(ctx context.Context, signedToken string) (*models.JWTClaims, error) {
+// VerifyToken implements service.TokenService. Tokens of another type than
+// tokenType are rejected, so refresh tokens cannot be used as bearer tokens
+// nor access tokens to renew.
+func (svc *TokenServiceImpl) VerifyToken(ctx context.Context, signedToken string, tokenType string) (*models.JWTClaims, error) {
 	jwtClaims := &models.JWTClaims{}
-	options := []jwt.ParserOption{jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Name}), jwt.WithExpirationRequired()}
-	keyfunc := func(t *jwt.Token) (interface{}, error) {
-		return []byte(svc.jwtSecret), nil
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods(svc.keys.Methods()),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithIssuer(svc.issuer),
+		jwt.WithAudience(svc.audience),
 	}
 
-	jwtToken, err := jwt.ParseWithClaims(signedToken, jwtClaims, keyfunc, options...)
+	jwtToken, err := jwt.ParseWithClaims(signedToken, jwtClaims, svc.keys.Keyfunc, options...)
 	if err != nil {
 		logger.Error(ctx, "VerifyToken :: failed to parse token with claims", logger.Field("error", err.Error()))
 		if strings.Contains(err.Error(), jwt.ErrTokenExpired.Error()) {
@@ -84,18 +105,29 @@ func (svc *TokenServiceImpl) VerifyToken(ctx context.Context, signedToken string
 		return nil, constants.ErrTokenInvalid
 	}
 
+	if jwtClaims.Type != tokenType {
+		logger.Error(ctx, "VerifyToken :: unexpected token type", logger.Field("expected", tokenType), logger.Field("type", jwtClaims.Type))
+		return nil, constants.ErrWrongTokenType
+	}
+
+	jwtClaims.UserId, err = strconv.ParseInt(jwtClaims.Subject, 10, 64)
+	if err != nil || len(jwtClaims.ID) == 0 {
+		logger.Error(ctx, "VerifyToken :: token is missing its subject or id")
+		return nil, constants.ErrTokenInvalid
+	}
+
 	return jwtClaims, nil
 }
 
 // RenewAccessToken implements service.TokenService.
 func (svc *TokenServiceImpl) RenewAccessToken(ctx context.Context, req *request.RenewAccessTokenRequest) (*response.RenewAccessTokenResponse, error) {
-	refreshClaims, err := svc.VerifyToken(ctx, req.RefreshToken)
+	refreshClaims, err := svc.VerifyToken(ctx, req.RefreshToken, constants.TokenTypeRefresh)
 	if err != nil {
 		logger.Error(ctx, "RenewAccessToken :: failed getting refresh claims", logger.Field("error", err.Error()))
 		return nil, err
 	}
 
-	session, err := svc.repo.GetSession(ctx, refreshClaims.Id)
+	session, err := svc.repo.GetSession(ctx, refreshClaims.SessionId)
 	if err != nil {
 		logger.Error(ctx, "RenewAccessToken :: failed getting session", logger.Field("error", err.Error()))
 		return nil, err
@@ -109,10 +141,6 @@ func (svc *TokenServiceImpl) RenewAccessToken(ctx context.Context, req *request.
 		return nil, constants.ErrIncorrectSessionUser
 	}
 
-	if session.RefreshToken != req.RefreshToken {
-		return nil, constants.ErrIncorrectSessionToken
-	}
-
 	if time.Now().After(session.ExpiresAt) {
 		return nil, constants.ErrSessionExpired
 	}
@@ -121,17 +149,117 @@ func (svc *TokenServiceImpl) RenewAccessToken(ctx context.Context, req *request.
 		return nil, constants.ErrLoggedOutSession
 	}
 
-	accessToken, accessClaims, err := svc.CreateToken(ctx, &request.CreateTokenRequest{Email: refreshClaims.Email, ExpiresIn: svc.accessTokenDuration})
+	tokenId, err := uuid.Parse(refreshClaims.ID)
+	if err != nil {
+		return nil, constants.ErrTokenInvalid
+	}
+
+	// every renewal uses up the presented refresh token and issues the next
+	// one, which expires with the session
+	var refreshToken string
+	var nextClaims *models.JWTClaims
+	err = svc.repo.ExecTx(ctx, func(q *db.Queries) error {
+		_, err := q.UseRefreshToken(ctx, tokenId)
+		if err != nil {
+			return err
+		}
+
+		refreshToken, nextClaims, err = svc.CreateToken(ctx, &request.CreateTokenRequest{
+			Type:      constants.TokenTypeRefresh,
+			UserId:    refreshClaims.UserId,
+			Email:     refreshClaims.Email,
+			SessionId: session.ID,
+			ExpiresIn: time.Until(session.ExpiresAt),
+		})
+		if err != nil {
+			return err
+		}
+
+		nextId, err := uuid.Parse(nextClaims.ID)
+		if err != nil {
+			return err
+		}
+
+		err = q.CreateRefreshToken(ctx, &db.CreateRefreshTokenParams{
+			ID:        nextId,
+			SessionID: session.ID,
+			ExpiresAt: nextClaims.ExpiresAt.Time,
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = q.UpdateSession(ctx, &db.UpdateSessionParams{RefreshToken: &refreshToken, ID: session.ID})
+		return err
+	})
+	if errors.Is(err, constants.ErrNoRows) {
+		return nil, svc.refreshTokenReused(ctx, req, refreshClaims, tokenId)
+	}
+	if err != nil {
+		logger.Error(ctx, "RenewAccessToken :: failed to rotate refresh token", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	accessToken, accessClaims, err := svc.CreateToken(ctx, &request.CreateTokenRequest{
+		Type:      constants.TokenTypeAccess,
+		UserId:    refreshClaims.UserId,
+		Email:     refreshClaims.Email,
+		SessionId: session.ID,
+		ExpiresIn: svc.accessTokenDuration,
+	})
 	if err != nil {
 		logger.Error(ctx, "RenewAccessToken :: failed to create access token", logger.Field("error", err.Error()))
 		return nil, err
 	}
 
 	res := &response.RenewAccessTokenResponse{
-		AccessToken:          accessToken,
-		AccessTokenExpiresAt: accessClaims.ExpiresAt.Time,
+		AccessToken:           accessToken,
+		AccessTokenExpiresAt:  accessClaims.ExpiresAt.Time,
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: nextClaims.ExpiresAt.Time,
 	}
 
 	return res, nil
 
 }
+
+// refreshTokenReused handles a refresh token that could not be used. A token
+// used before means it leaked, whoever presents it, so the whole session is
+// blocked: neither the thief nor the owner can renew with it anymore.
+func (svc *TokenServiceImpl) refreshTokenReused(ctx context.Context, req *request.RenewAccessTokenRequest, refreshClaims *models.JWTClaims, tokenId uuid.UUID) error {
+	token, err := svc.repo.GetRefreshToken(ctx, tokenId)
+	if errors.Is(err, constants.ErrNoRows) || (err == nil && token.SessionID != refreshClaims.SessionId) {
+		return constants.ErrTokenInvalid
+	}
+	if err != nil {
+		logger.Error(ctx, "refreshTokenReused :: failed getting refresh token", logger.Field("error", err.Error()))
+		return err
+	}
+
+	_, err = svc.repo.UpdateSession(ctx, &db.UpdateSessionParams{IsBlocked: utils.BoolPtr(true), ID: token.SessionID})
+	if err != nil {
+		logger.Error(ctx, "refreshTokenReused :: failed blocking session", logger.Field("error", err.Error()))
+		return err
+	}
+
+	terminateSessions(ctx, svc.tokenDenylist, svc.hub, token.SessionID)
+
+	logger.Error(ctx, "refreshTokenReused :: refresh token reused, session blocked", logger.Field("sessionId", token.SessionID), logger.Field("clientIp", req.ClientIp))
+	writeAuditLog(ctx, svc.repo, &db.CreateAuditLogParams{
+		Action:       constants.AuditRefreshTokenReused,
+		TargetUserID: &refreshClaims.UserId,
+		ClientIp:     &req.ClientIp,
+	}, map[string]any{
+		"sessionId": token.SessionID,
+		"tokenId":   token.ID,
+		"usedAt":    token.UsedAt,
+		"userAgent": req.UserAgent,
+	})
+
+	return constants.ErrRefreshTokenReused
+}
+
+// GetJWKS implements service.TokenService.
+func (svc *TokenServiceImpl) GetJWKS(ctx context.Context) (*response.JWKSResponse, error) {
+	return &response.JWKSResponse{Keys: svc.keys.JWKS(time.Now())}, nil
+}
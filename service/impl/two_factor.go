@@ -0,0 +1,183 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"project/config"
+	"project/constants"
+	db "project/db/sqlc"
+	"project/models/response"
+	"project/totp"
+	"strings"
+	"time"
+)
+
+// recoveryCodeBytes is the entropy of a recovery code, 48 bits make 10
+// base32 characters, shown as two groups of 5.
+const recoveryCodeBytes = 6
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// twoFactor holds the TOTP logic shared by logins and the 2FA settings of
+// users. q is the repository or the transaction the check belongs to.
+type twoFactor struct {
+	cipher *totp.Cipher // nil when no encryption key is configured
+	cfg    config.TwoFactorConfig
+}
+
+// required reports whether the deployment enforces 2FA for user. Bots log
+// in with api keys only.
+func (tf *twoFactor) required(user *db.User) bool {
+	switch tf.cfg.Enforce {
+	case constants.TwoFactorEnforceAll:
+		return !user.IsBot
+	case constants.TwoFactorEnforceAdmins:
+		return user.IsAdmin
+	default:
+		return false
+	}
+}
+
+// enabled returns the confirmed TOTP of the user, nil when 2FA is off.
+func (tf *twoFactor) enabled(ctx context.Context, q db.Querier, userId int64) (*db.UserTotp, error) {
+	userTotp, err := q.GetTOTPByUserId(ctx, userId)
+	if errors.Is(err, constants.ErrNoRows) || (err == nil && userTotp.ConfirmedAt == nil) {
+		return nil, nil
+	}
+	return userTotp, err
+}
+
+// enroll generates a new secret for user, replacing one that was never
+// confirmed.
+func (tf *twoFactor) enroll(ctx context.Context, q db.Querier, user *db.User) (*response.TOTPEnrollmentResponse, error) {
+	if tf.cipher == nil {
+		return nil, constants.ErrTwoFactorUnavailable
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := tf.cipher.Seal(secret, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = q.UpsertPendingTOTP(ctx, &db.UpsertPendingTOTPParams{UserID: user.ID, Secret: sealed})
+	if errors.Is(err, constants.ErrNoRows) {
+		return nil, constants.ErrTwoFactorEnabled
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &response.TOTPEnrollmentResponse{
+		Secret: totp.EncodeSecret(secret),
+		URI:    totp.URI(secret, tf.cfg.Issuer, user.Email),
+	}, nil
+}
+
+// confirm enables the pending TOTP of user with a first code and returns
+// fresh recovery codes. It must run in a transaction.
+func (tf *twoFactor) confirm(ctx context.Context, q db.Querier, user *db.User, code string) ([]string, error) {
+	userTotp, err := q.GetTOTPByUserId(ctx, user.ID)
+	if errors.Is(err, constants.ErrNoRows) {
+		return nil, constants.ErrTwoFactorNotEnabled
+	}
+	if err != nil {
+		return nil, err
+	}
+	if userTotp.ConfirmedAt != nil {
+		return nil, constants.ErrTwoFactorEnabled
+	}
+
+	err = tf.verifyTOTP(ctx, q, userTotp, code)
+	if err != nil {
+		return nil, err
+	}
+
+	err = q.ConfirmTOTP(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return tf.newRecoveryCodes(ctx, q, user.ID)
+}
+
+// verify checks a TOTP or recovery code of a user with 2FA enabled and
+// reports whether a recovery code was used up.
+func (tf *twoFactor) verify(ctx context.Context, q db.Querier, userTotp *db.UserTotp, code string) (bool, error) {
+	code = strings.TrimSpace(code)
+	if len(strings.ReplaceAll(code, " ", "")) == totp.Digits {
+		return false, tf.verifyTOTP(ctx, q, userTotp, code)
+	}
+
+	_, err := q.UseRecoveryCode(ctx, &db.UseRecoveryCodeParams{UserID: userTotp.UserID, CodeHash: hashRecoveryCode(code)})
+	if errors.Is(err, constants.ErrNoRows) {
+		return false, constants.ErrInvalidTwoFactorCode
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// verifyTOTP accepts each code once: the step of an accepted code is
+// recorded and codes of that step or earlier ones are refused after.
+func (tf *twoFactor) verifyTOTP(ctx context.Context, q db.Querier, userTotp *db.UserTotp, code string) error {
+	if tf.cipher == nil {
+		return constants.ErrTwoFactorUnavailable
+	}
+
+	secret, err := tf.cipher.Open(userTotp.Secret, userTotp.UserID)
+	if err != nil {
+		return err
+	}
+
+	step, ok := totp.Validate(secret, code, time.Now())
+	if !ok {
+		return constants.ErrInvalidTwoFactorCode
+	}
+
+	_, err = q.UseTOTPStep(ctx, &db.UseTOTPStepParams{Step: &step, UserID: userTotp.UserID})
+	if errors.Is(err, constants.ErrNoRows) {
+		return constants.ErrInvalidTwoFactorCode
+	}
+	return err
+}
+
+// newRecoveryCodes replaces the recovery codes of the user. They are only
+// ever shown in the response of the call.
+func (tf *twoFactor) newRecoveryCodes(ctx context.Context, q db.Querier, userId int64) ([]string, error) {
+	err := q.DeleteRecoveryCodes(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, tf.cfg.RecoveryCodes)
+	for i := range codes {
+		random := make([]byte, recoveryCodeBytes)
+		if _, err := rand.Read(random); err != nil {
+			return nil, err
+		}
+		code := strings.ToLower(recoveryCodeEncoding.EncodeToString(random))
+		codes[i] = code[:5] + "-" + code[5:]
+
+		err = q.CreateRecoveryCode(ctx, &db.CreateRecoveryCodeParams{UserID: userId, CodeHash: hashRecoveryCode(codes[i])})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return codes, nil
+}
+
+// hashRecoveryCode ignores case, spaces and dashes, the way codes get typed.
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
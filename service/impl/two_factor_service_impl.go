@@ -0,0 +1,239 @@
+package service
+
+import (
+	"context"
+	"project/config"
+	"project/constants"
+	db "project/db/sqlc"
+	"project/logger"
+	"project/models/request"
+	"project/models/response"
+	"project/password"
+	"project/service"
+	"project/totp"
+)
+
+type TwoFactorServiceImpl struct {
+	repo      db.Repository
+	twoFactor *twoFactor
+	passwords *password.Policy
+}
+
+func ConfigureTwoFactorService(cfg *config.StartupConfig, repo db.Repository, totpCipher *totp.Cipher, passwords *password.Policy) service.TwoFactorService {
+	return &TwoFactorServiceImpl{repo, &twoFactor{totpCipher, cfg.TwoFactor}, passwords}
+}
+
+// GetTwoFactorStatus implements service.TwoFactorService.
+func (svc *TwoFactorServiceImpl) GetTwoFactorStatus(ctx context.Context, req *request.GetTwoFactorStatusRequest) (*response.TwoFactorStatusResponse, error) {
+	user, err := svc.repo.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		logger.Error(ctx, "GetTwoFactorStatus :: failed to get user", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	userTotp, err := svc.twoFactor.enabled(ctx, svc.repo, user.ID)
+	if err != nil {
+		logger.Error(ctx, "GetTwoFactorStatus :: failed to get two-factor settings", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	status := &response.TwoFactorStatusResponse{Required: svc.twoFactor.required(user)}
+	if userTotp == nil {
+		return status, nil
+	}
+
+	status.RecoveryCodesLeft, err = svc.repo.CountUnusedRecoveryCodes(ctx, user.ID)
+	if err != nil {
+		logger.Error(ctx, "GetTwoFactorStatus :: failed to count recovery codes", logger.Field("error", err.Error()))
+		return nil, err
+	}
+	status.Enabled = true
+	status.EnabledAt = userTotp.ConfirmedAt
+
+	return status, nil
+}
+
+// EnrollTOTP implements service.TwoFactorService. 2FA is only on once the
+// secret is confirmed with a code.
+func (svc *TwoFactorServiceImpl) EnrollTOTP(ctx context.Context, req *request.EnrollTOTPRequest) (*response.TOTPEnrollmentResponse, error) {
+	user, err := svc.repo.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		logger.Error(ctx, "EnrollTOTP :: failed to get user", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	enrollment, err := svc.twoFactor.enroll(ctx, svc.repo, user)
+	if err != nil {
+		logger.Error(ctx, "EnrollTOTP :: failed to enroll totp", logger.Field("userId", user.ID), logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	return enrollment, nil
+}
+
+// ConfirmTOTP implements service.TwoFactorService.
+func (svc *TwoFactorServiceImpl) ConfirmTOTP(ctx context.Context, req *request.ConfirmTOTPRequest) (*response.RecoveryCodesResponse, error) {
+	user, err := svc.repo.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		logger.Error(ctx, "ConfirmTOTP :: failed to get user", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	var recoveryCodes []string
+	err = svc.repo.ExecTx(ctx, func(q *db.Queries) error {
+		recoveryCodes, err = svc.twoFactor.confirm(ctx, q, user, req.Code)
+		return err
+	})
+	if err != nil {
+		logger.Error(ctx, "ConfirmTOTP :: failed to confirm totp", logger.Field("userId", user.ID), logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	writeAuditLog(ctx, svc.repo, &db.CreateAuditLogParams{
+		ActorID:      &user.ID,
+		Action:       constants.AuditTwoFactorEnabled,
+		TargetUserID: &user.ID,
+		ClientIp:     &req.ClientIp,
+	}, map[string]any{})
+
+	return &response.RecoveryCodesResponse{RecoveryCodes: recoveryCodes}, nil
+}
+
+// DisableTOTP implements service.TwoFactorService. It takes the password and
+// a code, an access token alone is not enough to turn 2FA off.
+func (svc *TwoFactorServiceImpl) DisableTOTP(ctx context.Context, req *request.DisableTOTPRequest) error {
+	user, userTotp, err := svc.userWithTOTP(ctx, req.Email)
+	if err != nil {
+		return err
+	}
+
+	if svc.twoFactor.required(user) {
+		return constants.ErrTwoFactorRequired
+	}
+
+	_, err = svc.passwords.Verify(user.HashedPassword, req.Password)
+	if err != nil {
+		return constants.ErrPasswordIncorrect
+	}
+
+	_, err = svc.twoFactor.verify(ctx, svc.repo, userTotp, req.Code)
+	if err != nil {
+		logger.Error(ctx, "DisableTOTP :: failed to verify code", logger.Field("userId", user.ID), logger.Field("error", err.Error()))
+		return err
+	}
+
+	err = svc.repo.ExecTx(ctx, func(q *db.Queries) error {
+		_, err := q.DeleteTOTP(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+		return q.DeleteRecoveryCodes(ctx, user.ID)
+	})
+	if err != nil {
+		logger.Error(ctx, "DisableTOTP :: failed to disable totp", logger.Field("userId", user.ID), logger.Field("error", err.Error()))
+		return err
+	}
+
+	writeAuditLog(ctx, svc.repo, &db.CreateAuditLogParams{
+		ActorID:      &user.ID,
+		Action:       constants.AuditTwoFactorDisabled,
+		TargetUserID: &user.ID,
+		ClientIp:     &req.ClientIp,
+	}, map[string]any{})
+
+	return nil
+}
+
+// RegenerateRecoveryCodes implements service.TwoFactorService. The previous
+// codes stop working.
+func (svc *TwoFactorServiceImpl) RegenerateRecoveryCodes(ctx context.Context, req *request.RegenerateRecoveryCodesRequest) (*response.RecoveryCodesResponse, error) {
+	user, userTotp, err := svc.userWithTOTP(ctx, req.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	var recoveryCodes []string
+	err = svc.repo.ExecTx(ctx, func(q *db.Queries) error {
+		// a recovery code would replace itself, only the app proves the device
+		err := svc.twoFactor.verifyTOTP(ctx, q, userTotp, req.Code)
+		if err != nil {
+			return err
+		}
+
+		recoveryCodes, err = svc.twoFactor.newRecoveryCodes(ctx, q, user.ID)
+		return err
+	})
+	if err != nil {
+		logger.Error(ctx, "RegenerateRecoveryCodes :: failed to regenerate recovery codes", logger.Field("userId", user.ID), logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	writeAuditLog(ctx, svc.repo, &db.CreateAuditLogParams{
+		ActorID:      &user.ID,
+		Action:       constants.AuditRecoveryCodesReset,
+		TargetUserID: &user.ID,
+		ClientIp:     &req.ClientIp,
+	}, map[string]any{})
+
+	return &response.RecoveryCodesResponse{RecoveryCodes: recoveryCodes}, nil
+}
+
+// AdminResetTwoFactor implements service.TwoFactorService. It turns 2FA off
+// for a user who lost both the authenticator and the recovery codes; when
+// enforced, they enroll again at their next login.
+func (svc *TwoFactorServiceImpl) AdminResetTwoFactor(ctx context.Context, req *request.AdminResetTwoFactorRequest) error {
+	admin, err := svc.repo.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		logger.Error(ctx, "AdminResetTwoFactor :: failed to get admin", logger.Field("error", err.Error()))
+		return err
+	}
+
+	user, err := svc.repo.GetUserById(ctx, req.UserId)
+	if err != nil {
+		logger.Error(ctx, "AdminResetTwoFactor :: failed to get user", logger.Field("userId", req.UserId), logger.Field("error", err.Error()))
+		return err
+	}
+
+	err = svc.repo.ExecTx(ctx, func(q *db.Queries) error {
+		deleted, err := q.DeleteTOTP(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+		if deleted == 0 {
+			return constants.ErrTwoFactorNotEnabled
+		}
+		return q.DeleteRecoveryCodes(ctx, user.ID)
+	})
+	if err != nil {
+		logger.Error(ctx, "AdminResetTwoFactor :: failed to reset two-factor", logger.Field("userId", user.ID), logger.Field("error", err.Error()))
+		return err
+	}
+
+	writeAuditLog(ctx, svc.repo, &db.CreateAuditLogParams{
+		ActorID:      &admin.ID,
+		Action:       constants.AuditTwoFactorReset,
+		TargetUserID: &user.ID,
+		ClientIp:     &req.ClientIp,
+	}, map[string]any{})
+
+	return nil
+}
+
+func (svc *TwoFactorServiceImpl) userWithTOTP(ctx context.Context, email string) (*db.User, *db.UserTotp, error) {
+	user, err := svc.repo.GetUserByEmail(ctx, email)
+	if err != nil {
+		logger.Error(ctx, "userWithTOTP :: failed to get user", logger.Field("error", err.Error()))
+		return nil, nil, err
+	}
+
+	userTotp, err := svc.twoFactor.enabled(ctx, svc.repo, user.ID)
+	if err != nil {
+		logger.Error(ctx, "userWithTOTP :: failed to get two-factor settings", logger.Field("error", err.Error()))
+		return nil, nil, err
+	}
+	if userTotp == nil {
+		return nil, nil, constants.ErrTwoFactorNotEnabled
+	}
+
+	return user, userTotp, nil
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"project/constants"
+	db "project/db/sqlc"
+	"project/totp"
+	"testing"
+	"time"
+)
+
+// totpQuerier keeps the last used step the way UseTOTPStep does in the
+// database: a step is recorded only when it is later than the last one.
+type totpQuerier struct {
+	db.Querier
+	lastUsedStep *int64
+}
+
+func (q *totpQuerier) UseTOTPStep(ctx context.Context, arg *db.UseTOTPStepParams) (int64, error) {
+	if q.lastUsedStep != nil && *q.lastUsedStep >= *arg.Step {
+		return 0, constants.ErrNoRows
+	}
+	q.lastUsedStep = arg.Step
+	return arg.UserID, nil
+}
+
+func newTestTwoFactor(t *testing.T) (*twoFactor, *db.UserTotp, []byte) {
+	t.Helper()
+
+	cipher, err := totp.NewCipher(base64.StdEncoding.EncodeToString(make([]byte, 32)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed, err := cipher.Seal(secret, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &twoFactor{cipher: cipher}, &db.UserTotp{UserID: 1, Secret: sealed}, secret
+}
+
+func TestVerifyTOTPRefusesReplays(t *testing.T) {
+	tf, userTotp, secret := newTestTwoFactor(t)
+	q := &totpQuerier{}
+	ctx := context.Background()
+	current := totp.Step(time.Now())
+
+	err := tf.verifyTOTP(ctx, q, userTotp, totp.Code(secret, current))
+	if err != nil {
+		t.Fatalf("first use of a code: %v", err)
+	}
+
+	err = tf.verifyTOTP(ctx, q, userTotp, totp.Code(secret, current))
+	if !errors.Is(err, constants.ErrInvalidTwoFactorCode) {
+		t.Errorf("second use of a code = %v, want ErrInvalidTwoFactorCode", err)
+	}
+
+	err = tf.verifyTOTP(ctx, q, userTotp, totp.Code(secret, current-1))
+	if !errors.Is(err, constants.ErrInvalidTwoFactorCode) {
+		t.Errorf("code of an earlier step = %v, want ErrInvalidTwoFactorCode", err)
+	}
+
+	err = tf.verifyTOTP(ctx, q, userTotp, totp.Code(secret, current+1))
+	if err != nil {
+		t.Errorf("code of a later step: %v", err)
+	}
+}
+
+func TestHashRecoveryCode(t *testing.T) {
+	want := hashRecoveryCode("abcde-fghij")
+
+	for _, code := range []string{"abcdefghij", "ABCDE-FGHIJ", "abcde fghij", " abc-de fgh-ij ", "AbCdEfGhIj"} {
+		if got := hashRecoveryCode(code); got != want {
+			t.Errorf("hashRecoveryCode(%q) differs from the code as shown", code)
+		}
+	}
+	if hashRecoveryCode("abcde-fghik") == want {
+		t.Error("hashRecoveryCode matched a different code")
+	}
+}
@@ -2,32 +2,52 @@ package service
 
 import (
 	"context"
+	"errors"
+	"project/chat"
 	"project/config"
 	"project/constants"
 	db "project/db/sqlc"
+	"project/denylist"
 	"project/logger"
+	"project/models"
 	"project/models/request"
 	"project/models/response"
+	"project/password"
 	"project/service"
+	"project/totp"
 	"project/utils"
+	"strings"
 	"time"
+
+	"github.com/redis/go-redis/v9"
 )
 
 type UserServiceImpl struct {
-	repo                 db.Repository
-	accessTokenDuration  time.Duration
-	refreshTokenDuration time.Duration
-	tokenSvc             service.TokenService
+	cfg           *config.StartupConfig
+	repo          db.Repository
+	hub           *chat.Hub
+	tokenSvc      service.TokenService
+	loginGuard    *loginGuard
+	tokenDenylist *denylist.Denylist
+	twoFactor     *twoFactor
+	login         *loginIssuer
+	passwords     *password.Policy
 }
 
-func ConfigureUserService(cfg *config.StartupConfig, repo db.Repository, tokenSvc service.TokenService) service.UserService {
-	return &UserServiceImpl{repo, cfg.Token.AccessTokenDuration, cfg.Token.RefreshTokenDuration, tokenSvc}
+func ConfigureUserService(cfg *config.StartupConfig, repo db.Repository, hub *chat.Hub, redisClient *redis.Client, tokenSvc service.TokenService, tokenDenylist *denylist.Denylist, totpCipher *totp.Cipher, passwords *password.Policy) service.UserService {
+	login := newLoginIssuer(cfg, repo, redisClient, tokenSvc, totpCipher)
+	return &UserServiceImpl{cfg, repo, hub, tokenSvc, login.loginGuard, tokenDenylist, login.twoFactor, login, passwords}
 }
 
-// CreateUser implements service.UserService.
+// CreateUser implements service.UserService. The verification email is
+// queued with the user, an account never misses it.
 func (svc *UserServiceImpl) CreateUser(ctx context.Context, req *request.CreateUserRequest) (*response.UserResponse, error) {
+	err := svc.passwords.Check(req.Password)
+	if err != nil {
+		return nil, err
+	}
 
-	hashedPassword, err := utils.HashPassword(req.Password)
+	hashedPassword, err := svc.passwords.Hash(req.Password)
 	if err != nil {
 		logger.Error(ctx, "CreateUser :: failed to hash password", logger.Field("error", err.Error()))
 		return nil, err
@@ -40,7 +60,15 @@ func (svc *UserServiceImpl) CreateUser(ctx context.Context, req *request.CreateU
 		Phone:          req.Phone,
 	}
 
-	user, err := svc.repo.CreateUser(ctx, arg)
+	var user *db.User
+	err = svc.repo.ExecTx(ctx, func(q *db.Queries) error {
+		user, err = q.CreateUser(ctx, arg)
+		if err != nil {
+			return uniqueUserError(err)
+		}
+
+		return sendVerificationEmail(ctx, q, svc.cfg, user)
+	})
 	if err != nil {
 		logger.Error(ctx, "CreateUser :: failed to create user", logger.Field("error", err.Error()))
 		return nil, err
@@ -73,64 +101,277 @@ func (svc *UserServiceImpl) GetUserById(ctx context.Context, req *request.GetUse
 
 // LoginUser implements service.UserService.
 func (svc *UserServiceImpl) LoginUser(ctx context.Context, req *request.LoginUserRequest) (*response.LoginUserResponse, error) {
+	// the guard fails open, logins keep working while redis is unavailable
+	err := svc.loginGuard.check(ctx, req.Email, req.ClientIp)
+	if errors.Is(err, constants.ErrAccountLocked) || errors.Is(err, constants.ErrLoginThrottled) {
+		return nil, err
+	}
+	if err != nil {
+		logger.Error(ctx, "LoginUser :: failed to check login attempts", logger.Field("error", err.Error()))
+	}
+
+	// unknown emails fail like wrong passwords, with the same status, delays
+	// and lockouts, and hash the password to take as long, so logins do not
+	// tell which accounts exist
 	user, err := svc.repo.GetUserByEmail(ctx, req.Email)
+	if errors.Is(err, constants.ErrNoRows) {
+		logger.Error(ctx, "LoginUser :: unknown email", logger.Field("error", err.Error()))
+		svc.passwords.Hash(req.Password)
+		if lockErr := svc.loginFailed(ctx, req, nil); lockErr != nil {
+			return nil, lockErr
+		}
+		return nil, constants.ErrPasswordIncorrect
+	}
 	if err != nil {
 		logger.Error(ctx, "LoginUser :: failed to get user", logger.Field("error", err.Error()))
 		return nil, err
 	}
 
-	err = utils.VerifyPassword(user.HashedPassword, req.Password)
+	rehash, err := svc.passwords.Verify(user.HashedPassword, req.Password)
 	if err != nil {
 		logger.Error(ctx, "LoginUser :: incorrect password", logger.Field("error", err.Error()))
+		if lockErr := svc.loginFailed(ctx, req, user); lockErr != nil {
+			return nil, lockErr
+		}
 		return nil, constants.ErrPasswordIncorrect
 	}
+	if rehash {
+		svc.rehashPassword(ctx, user, req.Password)
+	}
+
+	// checked after the password, so it does not tell which accounts exist
+	if svc.cfg.Account.RequireVerifiedEmail && user.VerifiedAt == nil {
+		return nil, constants.ErrEmailNotVerified
+	}
+
+	return svc.login.complete(ctx, user, req.UserAgent, req.ClientIp)
+}
+
+// LoginSecondFactor implements service.UserService. It exchanges a login
+// challenge and a TOTP or recovery code for the tokens of a new session.
+// Accounts that must enroll confirm their TOTP with the code and get their
+// recovery codes in the response.
+func (svc *UserServiceImpl) LoginSecondFactor(ctx context.Context, req *request.LoginSecondFactorRequest) (*response.LoginUserResponse, error) {
+	claims, user, err := svc.verifyChallenge(ctx, req.ChallengeToken)
+	if err != nil {
+		return nil, err
+	}
+
+	err = svc.loginGuard.check(ctx, user.Email, req.ClientIp)
+	if errors.Is(err, constants.ErrAccountLocked) || errors.Is(err, constants.ErrLoginThrottled) {
+		return nil, err
+	}
+	if err != nil {
+		logger.Error(ctx, "LoginSecondFactor :: failed to check login attempts", logger.Field("error", err.Error()))
+	}
 
-	accessToken, accessClaims, err := svc.tokenSvc.CreateToken(ctx, &request.CreateTokenRequest{Email: req.Email, ExpiresIn: svc.accessTokenDuration})
+	userTotp, err := svc.twoFactor.enabled(ctx, svc.repo, user.ID)
 	if err != nil {
-		logger.Error(ctx, "LoginUser :: failed to create access token", logger.Field("error", err.Error()))
+		logger.Error(ctx, "LoginSecondFactor :: failed to get two-factor settings", logger.Field("error", err.Error()))
 		return nil, err
 	}
 
-	refreshToken, refreshClaims, err := svc.tokenSvc.CreateToken(ctx, &request.CreateTokenRequest{Email: req.Email, ExpiresIn: svc.refreshTokenDuration})
+	var recoveryCodes []string
+	usedRecoveryCode := false
+	switch {
+	case userTotp != nil:
+		usedRecoveryCode, err = svc.twoFactor.verify(ctx, svc.repo, userTotp, req.Code)
+	case svc.twoFactor.required(user):
+		err = svc.repo.ExecTx(ctx, func(q *db.Queries) error {
+			recoveryCodes, err = svc.twoFactor.confirm(ctx, q, user, req.Code)
+			return err
+		})
+	default:
+		// 2FA was turned off since the challenge was issued
+		err = constants.ErrTwoFactorNotEnabled
+	}
+	if errors.Is(err, constants.ErrInvalidTwoFactorCode) {
+		if lockErr := svc.loginFailed(ctx, &request.LoginUserRequest{Email: user.Email, ClientIp: req.ClientIp}, user); lockErr != nil {
+			return nil, lockErr
+		}
+		return nil, err
+	}
+	if err != nil {
+		logger.Error(ctx, "LoginSecondFactor :: failed to verify code", logger.Field("userId", user.ID), logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	// spent only once the code is right, a typo does not cost the password
+	used, err := svc.tokenDenylist.UseToken(ctx, claims.ID, claims.ExpiresAt.Time)
+	if err != nil {
+		logger.Error(ctx, "LoginSecondFactor :: failed to use challenge", logger.Field("error", err.Error()))
+		return nil, err
+	}
+	if !used {
+		return nil, constants.ErrChallengeUsed
+	}
+
+	if recoveryCodes != nil {
+		writeAuditLog(ctx, svc.repo, &db.CreateAuditLogParams{
+			ActorID:      &user.ID,
+			Action:       constants.AuditTwoFactorEnabled,
+			TargetUserID: &user.ID,
+			ClientIp:     &req.ClientIp,
+		}, map[string]any{"enforced": true})
+	}
+	if usedRecoveryCode {
+		writeAuditLog(ctx, svc.repo, &db.CreateAuditLogParams{
+			ActorID:      &user.ID,
+			Action:       constants.AuditRecoveryCodeUsed,
+			TargetUserID: &user.ID,
+			ClientIp:     &req.ClientIp,
+		}, map[string]any{})
+	}
+
+	loginUserResponse, err := svc.login.startSession(ctx, user, req.UserAgent, req.ClientIp)
+	if err != nil {
+		return nil, err
+	}
+	loginUserResponse.RecoveryCodes = recoveryCodes
+	return loginUserResponse, nil
+}
+
+// EnrollLoginSecondFactor implements service.UserService. Accounts the
+// deployment enforces 2FA for get their TOTP secret with their login
+// challenge, they have no access token to enroll with yet.
+func (svc *UserServiceImpl) EnrollLoginSecondFactor(ctx context.Context, req *request.EnrollLoginSecondFactorRequest) (*response.TOTPEnrollmentResponse, error) {
+	_, user, err := svc.verifyChallenge(ctx, req.ChallengeToken)
 	if err != nil {
-		logger.Error(ctx, "LoginUser :: failed to create refresh token", logger.Field("error", err.Error()))
 		return nil, err
 	}
 
-	arg := &db.CreateSessionParams{
-		ID:           refreshClaims.Id,
-		Email:        req.Email,
-		UserAgent:    req.UserAgent,
-		ClientIp:     req.ClientIp,
-		RefreshToken: refreshToken,
-		ExpiresAt:    refreshClaims.ExpiresAt.Time,
+	if !svc.twoFactor.required(user) {
+		return nil, constants.ErrAccessDenied
 	}
-	session, err := svc.repo.CreateSession(ctx, arg)
+
+	enrollment, err := svc.twoFactor.enroll(ctx, svc.repo, user)
 	if err != nil {
-		logger.Error(ctx, "LoginUser :: failed to create session", logger.Field("error", err.Error()))
+		logger.Error(ctx, "EnrollLoginSecondFactor :: failed to enroll totp", logger.Field("userId", user.ID), logger.Field("error", err.Error()))
 		return nil, err
 	}
 
-	loginUserResponse := response.LoginUserResponse{
-		SessionId:             session.ID, // session.ID = refreshClaims.Id
-		AccessToken:           accessToken,
-		AccessTokenExpiresAt:  accessClaims.ExpiresAt.Time,
-		RefreshToken:          refreshToken,
-		RefreshTokenExpiresAt: refreshClaims.ExpiresAt.Time,
-		User:                  response.BuildUserResponse(user),
+	return enrollment, nil
+}
+
+// verifyChallenge returns the claims of a login challenge that was not used
+// yet and the user it was issued to.
+func (svc *UserServiceImpl) verifyChallenge(ctx context.Context, challengeToken string) (*models.JWTClaims, *db.User, error) {
+	claims, err := svc.tokenSvc.VerifyToken(ctx, challengeToken, constants.TokenTypeChallenge)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	denied, err := svc.tokenDenylist.IsDenied(ctx, claims)
+	if err != nil {
+		logger.Error(ctx, "verifyChallenge :: failed to check denylist", logger.Field("error", err.Error()))
+		return nil, nil, err
+	}
+	if denied {
+		return nil, nil, constants.ErrChallengeUsed
+	}
+
+	user, err := svc.repo.GetUserById(ctx, claims.UserId)
+	if err != nil {
+		logger.Error(ctx, "verifyChallenge :: failed to get user", logger.Field("error", err.Error()))
+		return nil, nil, err
+	}
+	if user.Email != claims.Email {
+		return nil, nil, constants.ErrTokenInvalid
+	}
+
+	return claims, user, nil
+}
+
+// rehashPassword replaces the hash of a verified password, made with bcrypt
+// or outdated parameters, with one of the current parameters. The login
+// goes on if it fails, the next one tries again.
+func (svc *UserServiceImpl) rehashPassword(ctx context.Context, user *db.User, plainPassword string) {
+	hashedPassword, err := svc.passwords.Hash(plainPassword)
+	if err == nil {
+		err = svc.repo.RehashUserPassword(ctx, &db.RehashUserPasswordParams{
+			HashedPassword:    hashedPassword,
+			ID:                user.ID,
+			OldHashedPassword: user.HashedPassword,
+		})
+	}
+	if err != nil {
+		logger.Error(ctx, "rehashPassword :: failed to rehash password", logger.Field("userId", user.ID), logger.Field("error", err.Error()))
+	}
+}
+
+// loginFailed counts a failed login, user is nil when the email is unknown.
+// It audits the lockouts the attempt started and returns the error to answer
+// with when the account itself got locked.
+func (svc *UserServiceImpl) loginFailed(ctx context.Context, req *request.LoginUserRequest, user *db.User) error {
+	locked, err := svc.loginGuard.fail(ctx, req.Email, req.ClientIp)
+	if err != nil {
+		logger.Error(ctx, "loginFailed :: failed to count login attempt", logger.Field("error", err.Error()))
+		return nil
+	}
+
+	var targetUserId *int64
+	if user != nil {
+		targetUserId = &user.ID
+	}
+
+	if locked.address {
+		writeAuditLog(ctx, svc.repo, &db.CreateAuditLogParams{
+			Action:       constants.AuditAddressLocked,
+			TargetUserID: targetUserId,
+			ClientIp:     &req.ClientIp,
+		}, map[string]any{"email": req.Email, "lockedFor": svc.loginGuard.cfg.LockoutDuration.String()})
+	}
+
+	if locked.account {
+		writeAuditLog(ctx, svc.repo, &db.CreateAuditLogParams{
+			Action:       constants.AuditAccountLocked,
+			TargetUserID: targetUserId,
+			ClientIp:     &req.ClientIp,
+		}, map[string]any{"failures": locked.failures, "lockedFor": svc.loginGuard.cfg.LockoutDuration.String()})
+		return utils.RetryAfterError(constants.ErrAccountLocked, svc.loginGuard.cfg.LockoutDuration)
+	}
+
+	return nil
+}
+
+// UnlockUser implements service.UserService.
+func (svc *UserServiceImpl) UnlockUser(ctx context.Context, req *request.UnlockUserRequest) error {
+	admin, err := svc.repo.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		logger.Error(ctx, "UnlockUser :: failed to get admin", logger.Field("error", err.Error()))
+		return err
+	}
+
+	user, err := svc.repo.GetUserById(ctx, req.UserId)
+	if err != nil {
+		logger.Error(ctx, "UnlockUser :: failed to get user", logger.Field("userId", req.UserId), logger.Field("error", err.Error()))
+		return err
 	}
-	return &loginUserResponse, nil
+
+	err = svc.loginGuard.unlock(ctx, user.Email)
+	if err != nil {
+		logger.Error(ctx, "UnlockUser :: failed to unlock user", logger.Field("userId", req.UserId), logger.Field("error", err.Error()))
+		return err
+	}
+
+	writeAuditLog(ctx, svc.repo, &db.CreateAuditLogParams{
+		ActorID:      &admin.ID,
+		Action:       constants.AuditAccountUnlocked,
+		TargetUserID: &user.ID,
+	}, map[string]any{})
+
+	return nil
 }
 
 // LogoutUser implements service.UserService.
 func (svc *UserServiceImpl) LogoutUser(ctx context.Context, req *request.LogoutUserRequest) error {
-	refreshClaims, err := svc.tokenSvc.VerifyToken(ctx, req.RefreshToken)
+	refreshClaims, err := svc.tokenSvc.VerifyToken(ctx, req.RefreshToken, constants.TokenTypeRefresh)
 	if err != nil {
 		logger.Error(ctx, "LogoutUser :: failed getting refresh claims", logger.Field("error", err.Error()))
 		return err
 	}
 
-	session, err := svc.repo.GetSession(ctx, refreshClaims.Id)
+	session, err := svc.repo.GetSession(ctx, refreshClaims.SessionId)
 	if err != nil {
 		logger.Error(ctx, "LogoutUser :: failed getting session", logger.Field("error", err.Error()))
 		return err
@@ -162,6 +403,10 @@ func (svc *UserServiceImpl) LogoutUser(ctx context.Context, req *request.LogoutU
 		return err
 	}
 
+	// access tokens and websockets of the session would work until they
+	// expire or drop otherwise
+	terminateSessions(ctx, svc.tokenDenylist, svc.hub, session.ID)
+
 	return nil
 }
 
@@ -180,3 +425,33 @@ func (svc *UserServiceImpl) GetUsers(ctx context.Context) (*[]response.UserRespo
 
 	return &userResp, nil
 }
+
+// uniqueUserError tells which of username and email is taken when err is a
+// unique violation on users, and returns err otherwise.
+func uniqueUserError(err error) error {
+	switch utils.ConstraintName(err) {
+	case constants.UsersUsernameKey:
+		return constants.ErrUsernameTaken
+	case constants.UsersEmailKey:
+		return constants.ErrEmailTaken
+	}
+	return err
+}
+
+// availableUsername returns username, with a random suffix when another
+// account has it, for accounts created without anyone to pick another.
+func availableUsername(ctx context.Context, q db.Querier, username string) (string, error) {
+	_, err := q.GetUserByUsername(ctx, username)
+	if errors.Is(err, constants.ErrNoRows) {
+		return username, nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	suffix, err := utils.RandomToken(3)
+	if err != nil {
+		return "", err
+	}
+	return username + "-" + strings.ToLower(suffix), nil
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"project/config"
+	"project/constants"
+	db "project/db/sqlc"
+	"project/mailer"
+	"time"
+)
+
+// userTokenBytes is the entropy of the tokens mailed to users.
+const userTokenBytes = 32
+
+// pages of the web client that take a mailed token
+const (
+	passwordResetPath     = "/reset-password"
+	emailVerificationPath = "/verify-email"
+	emailChangePath       = "/confirm-email"
+)
+
+// hashUserToken returns the hash user tokens are stored and looked up by,
+// so a leaked table does not hand out working links.
+func hashUserToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// issueUserToken voids the unused tokens of the user for purpose and returns
+// a new one valid for ttl, bound to email.
+func issueUserToken(ctx context.Context, q *db.Queries, user *db.User, email string, purpose string, ttl time.Duration) (string, error) {
+	random := make([]byte, userTokenBytes)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(random)
+
+	err := q.ExpireUserTokens(ctx, &db.ExpireUserTokensParams{UserID: user.ID, Purpose: purpose})
+	if err != nil {
+		return "", err
+	}
+
+	_, err = q.CreateUserToken(ctx, &db.CreateUserTokenParams{
+		UserID:    user.ID,
+		Purpose:   purpose,
+		TokenHash: hashUserToken(token),
+		Email:     email,
+		ExpiresAt: time.Now().Add(ttl),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// expireAccountTokens voids the password reset and email change links of the
+// user, on a password change: whoever knew the old password may have asked
+// for them.
+func expireAccountTokens(ctx context.Context, q *db.Queries, userId int64) error {
+	for _, purpose := range []string{constants.UserTokenPasswordReset, constants.UserTokenEmailChange} {
+		err := q.ExpireUserTokens(ctx, &db.ExpireUserTokensParams{UserID: userId, Purpose: purpose})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sendVerificationEmail issues an email verification token and queues its
+// mail, on the transaction of q.
+func sendVerificationEmail(ctx context.Context, q *db.Queries, cfg *config.StartupConfig, user *db.User) error {
+	token, err := issueUserToken(ctx, q, user, user.Email, constants.UserTokenEmailVerification, cfg.Account.EmailVerificationTTL)
+	if err != nil {
+		return err
+	}
+
+	return mailer.Enqueue(ctx, q, &mailer.Mail{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Hi %s,\n\nconfirm that this is your email address by opening the link below:\n\n%s\n\nThe link expires in %s. If you did not sign up, ignore this mail.\n",
+			user.Username, mailer.Link(cfg.Mail.BaseURL, emailVerificationPath, token), cfg.Account.EmailVerificationTTL),
+	})
+}
+
+// sendPasswordResetEmail issues a password reset token and queues its mail,
+// on the transaction of q.
+func sendPasswordResetEmail(ctx context.Context, q *db.Queries, cfg *config.StartupConfig, user *db.User) error {
+	token, err := issueUserToken(ctx, q, user, user.Email, constants.UserTokenPasswordReset, cfg.Account.PasswordResetTTL)
+	if err != nil {
+		return err
+	}
+
+	return mailer.Enqueue(ctx, q, &mailer.Mail{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\nchoose a new password by opening the link below:\n\n%s\n\nThe link expires in %s and logs you out everywhere once used. If you did not ask for it, ignore this mail, your password stays the same.\n",
+			user.Username, mailer.Link(cfg.Mail.BaseURL, passwordResetPath, token), cfg.Account.PasswordResetTTL),
+	})
+}
+
+// sendEmailChangeEmail issues an email change token bound to newEmail and
+// queues its mail to newEmail, along with a notice to the current address,
+// on the transaction of q.
+func sendEmailChangeEmail(ctx context.Context, q *db.Queries, cfg *config.StartupConfig, user *db.User, newEmail string) error {
+	token, err := issueUserToken(ctx, q, user, newEmail, constants.UserTokenEmailChange, cfg.Account.EmailChangeTTL)
+	if err != nil {
+		return err
+	}
+
+	err = mailer.Enqueue(ctx, q, &mailer.Mail{
+		To:      newEmail,
+		Subject: "Confirm your new email address",
+		Body: fmt.Sprintf("Hi %s,\n\nconfirm that your account should use this email address from now on by opening the link below:\n\n%s\n\nThe link expires in %s and logs you out everywhere once used. If you did not ask for it, ignore this mail.\n",
+			user.Username, mailer.Link(cfg.Mail.BaseURL, emailChangePath, token), cfg.Account.EmailChangeTTL),
+	})
+	if err != nil {
+		return err
+	}
+
+	return mailer.Enqueue(ctx, q, &mailer.Mail{
+		To:      user.Email,
+		Subject: "Your email address is about to change",
+		Body: fmt.Sprintf("Hi %s,\n\nsomeone asked to change the email address of your account to %s. It changes once the link mailed there is opened.\n\nIf this was not you, change your password now, your account stays on this address until the link is used.\n",
+			user.Username, newEmail),
+	})
+}
@@ -0,0 +1,334 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"project/chat"
+	"project/config"
+	"project/constants"
+	db "project/db/sqlc"
+	"project/logger"
+	"project/models/request"
+	"project/models/response"
+	"project/ratelimit"
+	"project/service"
+	"project/utils"
+	"project/webhooks"
+	"strings"
+)
+
+// webhookTokenSize is the number of random bytes in an incoming webhook token
+// and in an outgoing webhook signing secret.
+const webhookTokenSize = 32
+
+// defaultDeliveriesLimit is the number of delivery logs returned when the
+// request does not set one.
+const defaultDeliveriesLimit = 50
+
+type WebhookServiceImpl struct {
+	repo          db.Repository
+	hub           *chat.Hub
+	limiter       *ratelimit.Limiter
+	incomingLimit ratelimit.Limit
+	webhooks      config.WebhooksConfig
+}
+
+func ConfigureWebhookService(cfg *config.StartupConfig, repo db.Repository, hub *chat.Hub, limiter *ratelimit.Limiter) service.WebhookService {
+	incomingLimit := ratelimit.PerPeriod(int(cfg.Webhooks.RateLimit), cfg.Webhooks.RateWindow)
+	return &WebhookServiceImpl{repo, hub, limiter, incomingLimit, cfg.Webhooks}
+}
+
+// CreateIncomingWebhook implements service.WebhookService. Only the hash of
+// the token is stored, the URL is returned once and cannot be recovered.
+func (svc *WebhookServiceImpl) CreateIncomingWebhook(ctx context.Context, req *request.CreateIncomingWebhookRequest) (*response.IncomingWebhookResponse, error) {
+	user, err := svc.repo.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		logger.Error(ctx, "CreateIncomingWebhook :: failed to get user", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	_, err = requireChannelAdmin(ctx, svc.repo, user.ID, req.ChannelId)
+	if err != nil {
+		logger.Error(ctx, "CreateIncomingWebhook :: channel admin required", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	token, err := utils.RandomToken(webhookTokenSize)
+	if err != nil {
+		logger.Error(ctx, "CreateIncomingWebhook :: failed to generate token", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	arg := &db.CreateIncomingWebhookParams{
+		ChannelID: req.ChannelId,
+		Name:      req.Name,
+		TokenHash: utils.HashToken(token),
+		CreatedBy: user.ID,
+	}
+	webhook, err := svc.repo.CreateIncomingWebhook(ctx, arg)
+	if err != nil {
+		logger.Error(ctx, "CreateIncomingWebhook :: failed to create webhook", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	webhookResponse := response.BuildIncomingWebhookResponse(webhook)
+	webhookResponse.Url = fmt.Sprintf("%s/hooks/%s", strings.TrimRight(svc.webhooks.BaseURL, "/"), token)
+	return webhookResponse, nil
+}
+
+// GetIncomingWebhooks implements service.WebhookService.
+func (svc *WebhookServiceImpl) GetIncomingWebhooks(ctx context.Context, req *request.GetIncomingWebhooksRequest) (*[]response.IncomingWebhookResponse, error) {
+	user, err := svc.repo.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		logger.Error(ctx, "GetIncomingWebhooks :: failed to get user", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	_, err = requireChannelAdmin(ctx, svc.repo, user.ID, req.ChannelId)
+	if err != nil {
+		logger.Error(ctx, "GetIncomingWebhooks :: channel admin required", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	webhooks, err := svc.repo.GetIncomingWebhooksByChannelId(ctx, req.ChannelId)
+	if err != nil {
+		logger.Error(ctx, "GetIncomingWebhooks :: failed to get webhooks", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	webhooksResponse := make([]response.IncomingWebhookResponse, 0)
+	for _, webhook := range webhooks {
+		webhooksResponse = append(webhooksResponse, *response.BuildIncomingWebhookResponse(webhook))
+	}
+
+	return &webhooksResponse, nil
+}
+
+// RevokeIncomingWebhook implements service.WebhookService. Messages already
+// posted through the webhook are kept.
+func (svc *WebhookServiceImpl) RevokeIncomingWebhook(ctx context.Context, req *request.RevokeIncomingWebhookRequest) error {
+	user, err := svc.repo.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		logger.Error(ctx, "RevokeIncomingWebhook :: failed to get user", logger.Field("error", err.Error()))
+		return err
+	}
+
+	_, err = requireChannelAdmin(ctx, svc.repo, user.ID, req.ChannelId)
+	if err != nil {
+		logger.Error(ctx, "RevokeIncomingWebhook :: channel admin required", logger.Field("error", err.Error()))
+		return err
+	}
+
+	_, err = svc.repo.RevokeIncomingWebhook(ctx, &db.RevokeIncomingWebhookParams{ID: req.WebhookId, ChannelID: req.ChannelId})
+	if err != nil {
+		logger.Error(ctx, "RevokeIncomingWebhook :: failed to revoke webhook", logger.Field("error", err.Error()))
+		return err
+	}
+
+	return nil
+}
+
+// PostIncomingWebhook implements service.WebhookService. The message is
+// attributed to the admin who created the webhook, flagged as a bot message
+// and shown under the username override or the webhook name.
+func (svc *WebhookServiceImpl) PostIncomingWebhook(ctx context.Context, req *request.PostIncomingWebhookRequest) (*response.PostIncomingWebhookResponse, error) {
+	webhook, err := svc.repo.GetIncomingWebhookByTokenHash(ctx, utils.HashToken(req.Token))
+	if err != nil {
+		logger.Error(ctx, "PostIncomingWebhook :: failed to get webhook", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	err = svc.allowIncoming(ctx, webhook.ID)
+	if err != nil {
+		logger.Error(ctx, "PostIncomingWebhook :: webhook rate limited", logger.Field("webhookId", webhook.ID), logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	username := webhook.Name
+	if req.Username != "" {
+		username = req.Username
+	}
+
+	arg := &db.CreateMessageParams{
+		ChannelID:        webhook.ChannelID,
+		UserID:           webhook.CreatedBy,
+		Content:          req.Text,
+		IsBot:            true,
+		UsernameOverride: &username,
+		WebhookID:        &webhook.ID,
+	}
+	if len(req.Attachments) > 0 {
+		arg.Attachments, err = json.Marshal(req.Attachments)
+		if err != nil {
+			logger.Error(ctx, "PostIncomingWebhook :: failed to marshal attachments", logger.Field("error", err.Error()))
+			return nil, err
+		}
+	}
+
+	message, err := svc.hub.PostMessage(ctx, username, arg)
+	if err != nil {
+		logger.Error(ctx, "PostIncomingWebhook :: failed to post message", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	return &response.PostIncomingWebhookResponse{MessageId: message.Id}, nil
+}
+
+// CreateOutgoingWebhook implements service.WebhookService. The signing secret
+// is returned once, receivers use it to verify the X-Chat-Signature header.
+func (svc *WebhookServiceImpl) CreateOutgoingWebhook(ctx context.Context, req *request.CreateOutgoingWebhookRequest) (*response.OutgoingWebhookResponse, error) {
+	user, err := svc.repo.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		logger.Error(ctx, "CreateOutgoingWebhook :: failed to get user", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	_, err = requireChannelAdmin(ctx, svc.repo, user.ID, req.ChannelId)
+	if err != nil {
+		logger.Error(ctx, "CreateOutgoingWebhook :: channel admin required", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	err = webhooks.CheckURL(ctx, req.Url)
+	if err != nil {
+		logger.Error(ctx, "CreateOutgoingWebhook :: invalid url", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	secret, err := utils.RandomToken(webhookTokenSize)
+	if err != nil {
+		logger.Error(ctx, "CreateOutgoingWebhook :: failed to generate secret", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	triggerWords := req.TriggerWords
+	if triggerWords == nil {
+		triggerWords = make([]string, 0)
+	}
+
+	arg := &db.CreateOutgoingWebhookParams{
+		ChannelID:    req.ChannelId,
+		Url:          req.Url,
+		Secret:       secret,
+		TriggerWords: triggerWords,
+		CreatedBy:    user.ID,
+	}
+	webhook, err := svc.repo.CreateOutgoingWebhook(ctx, arg)
+	if err != nil {
+		logger.Error(ctx, "CreateOutgoingWebhook :: failed to create webhook", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	webhookResponse := response.BuildOutgoingWebhookResponse(webhook)
+	webhookResponse.Secret = webhook.Secret
+	return webhookResponse, nil
+}
+
+// GetOutgoingWebhooks implements service.WebhookService.
+func (svc *WebhookServiceImpl) GetOutgoingWebhooks(ctx context.Context, req *request.GetOutgoingWebhooksRequest) (*[]response.OutgoingWebhookResponse, error) {
+	user, err := svc.repo.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		logger.Error(ctx, "GetOutgoingWebhooks :: failed to get user", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	_, err = requireChannelAdmin(ctx, svc.repo, user.ID, req.ChannelId)
+	if err != nil {
+		logger.Error(ctx, "GetOutgoingWebhooks :: channel admin required", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	webhooks, err := svc.repo.GetOutgoingWebhooksByChannelId(ctx, req.ChannelId)
+	if err != nil {
+		logger.Error(ctx, "GetOutgoingWebhooks :: failed to get webhooks", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	webhooksResponse := make([]response.OutgoingWebhookResponse, 0)
+	for _, webhook := range webhooks {
+		webhooksResponse = append(webhooksResponse, *response.BuildOutgoingWebhookResponse(webhook))
+	}
+
+	return &webhooksResponse, nil
+}
+
+// RevokeOutgoingWebhook implements service.WebhookService. Pending deliveries
+// are dropped by the dispatcher.
+func (svc *WebhookServiceImpl) RevokeOutgoingWebhook(ctx context.Context, req *request.RevokeOutgoingWebhookRequest) error {
+	user, err := svc.repo.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		logger.Error(ctx, "RevokeOutgoingWebhook :: failed to get user", logger.Field("error", err.Error()))
+		return err
+	}
+
+	_, err = requireChannelAdmin(ctx, svc.repo, user.ID, req.ChannelId)
+	if err != nil {
+		logger.Error(ctx, "RevokeOutgoingWebhook :: channel admin required", logger.Field("error", err.Error()))
+		return err
+	}
+
+	_, err = svc.repo.RevokeOutgoingWebhook(ctx, &db.RevokeOutgoingWebhookParams{ID: req.WebhookId, ChannelID: req.ChannelId})
+	if err != nil {
+		logger.Error(ctx, "RevokeOutgoingWebhook :: failed to revoke webhook", logger.Field("error", err.Error()))
+		return err
+	}
+
+	return nil
+}
+
+// GetWebhookDeliveries implements service.WebhookService. Deliveries are
+// returned newest first, one entry per attempt.
+func (svc *WebhookServiceImpl) GetWebhookDeliveries(ctx context.Context, req *request.GetWebhookDeliveriesRequest) (*[]response.WebhookDeliveryResponse, error) {
+	user, err := svc.repo.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		logger.Error(ctx, "GetWebhookDeliveries :: failed to get user", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	_, err = requireChannelAdmin(ctx, svc.repo, user.ID, req.ChannelId)
+	if err != nil {
+		logger.Error(ctx, "GetWebhookDeliveries :: channel admin required", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	webhook, err := svc.repo.GetOutgoingWebhookById(ctx, req.WebhookId)
+	if err != nil {
+		logger.Error(ctx, "GetWebhookDeliveries :: failed to get webhook", logger.Field("error", err.Error()))
+		return nil, err
+	}
+	if webhook.ChannelID != req.ChannelId {
+		return nil, constants.ErrNoRows
+	}
+
+	limit := req.Limit
+	if limit == 0 {
+		limit = defaultDeliveriesLimit
+	}
+	deliveries, err := svc.repo.GetWebhookDeliveries(ctx, &db.GetWebhookDeliveriesParams{WebhookID: webhook.ID, PageSize: limit})
+	if err != nil {
+		logger.Error(ctx, "GetWebhookDeliveries :: failed to get deliveries", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	deliveriesResponse := make([]response.WebhookDeliveryResponse, 0)
+	for _, delivery := range deliveries {
+		deliveriesResponse = append(deliveriesResponse, *response.BuildWebhookDeliveryResponseFromRow(delivery))
+	}
+
+	return &deliveriesResponse, nil
+}
+
+// allowIncoming takes a token from the bucket of the webhook, shared by every
+// app node through redis.
+func (svc *WebhookServiceImpl) allowIncoming(ctx context.Context, webhookId int64) error {
+	result, err := svc.limiter.Allow(ctx, fmt.Sprintf("webhook:incoming:%d", webhookId), svc.incomingLimit)
+	if err != nil {
+		return err
+	}
+
+	if !result.Allowed {
+		return constants.ErrRateLimited
+	}
+	return nil
+}
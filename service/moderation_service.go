@@ -0,0 +1,16 @@
+package service
+
+import (
+	"context"
+	"project/models/request"
+	"project/models/response"
+)
+
+type ModerationService interface {
+	CreateModerationRule(ctx context.Context, req *request.CreateModerationRuleRequest) (*response.ModerationRuleResponse, error)
+	GetModerationRules(ctx context.Context, req *request.GetModerationRulesRequest) (*[]response.ModerationRuleResponse, error)
+	DeleteModerationRule(ctx context.Context, req *request.DeleteModerationRuleRequest) error
+	GetHeldMessages(ctx context.Context, req *request.GetHeldMessagesRequest) (*[]response.HeldMessageResponse, error)
+	ApproveHeldMessage(ctx context.Context, req *request.ApproveHeldMessageRequest) (*response.HeldMessageResponse, error)
+	RejectHeldMessage(ctx context.Context, req *request.RejectHeldMessageRequest) (*response.HeldMessageResponse, error)
+}
@@ -0,0 +1,16 @@
+package service
+
+import (
+	"context"
+	"project/models/request"
+	"project/models/response"
+)
+
+type PinService interface {
+	GetPins(ctx context.Context, req *request.GetPinsRequest) (*[]response.PinResponse, error)
+	PinMessage(ctx context.Context, req *request.PinMessageRequest) (*response.PinResponse, error)
+	UnpinMessage(ctx context.Context, req *request.UnpinMessageRequest) error
+	GetSavedItems(ctx context.Context, req *request.GetSavedItemsRequest) (*[]response.SavedItemResponse, error)
+	SaveMessage(ctx context.Context, req *request.SaveMessageRequest) (*response.SavedItemResponse, error)
+	UnsaveMessage(ctx context.Context, req *request.UnsaveMessageRequest) error
+}
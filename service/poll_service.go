@@ -0,0 +1,16 @@
+package service
+
+import (
+	"context"
+	"project/models/request"
+	"project/models/response"
+)
+
+type PollService interface {
+	CreatePoll(ctx context.Context, req *request.CreatePollRequest) (*response.PollResponse, error)
+	GetPoll(ctx context.Context, req *request.GetPollRequest) (*response.PollResponse, error)
+	VotePoll(ctx context.Context, req *request.VotePollRequest) (*response.PollResponse, error)
+	RetractPollVote(ctx context.Context, req *request.RetractPollVoteRequest) (*response.PollResponse, error)
+	ClosePoll(ctx context.Context, req *request.ClosePollRequest) (*response.PollResponse, error)
+	CloseDuePolls(ctx context.Context) error
+}
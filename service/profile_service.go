@@ -0,0 +1,15 @@
+package service
+
+import (
+	"context"
+	"io"
+	"project/models/request"
+	"project/models/response"
+)
+
+type ProfileService interface {
+	UpdateProfile(ctx context.Context, req *request.UpdateProfileRequest) (*response.UserResponse, error)
+	UpdateAvatar(ctx context.Context, req *request.UpdateAvatarRequest, r io.Reader) (*response.UserResponse, error)
+	DeleteAvatar(ctx context.Context, req *request.DeleteAvatarRequest) (*response.UserResponse, error)
+	GetAvatar(ctx context.Context, req *request.GetAvatarRequest) (string, error)
+}
@@ -0,0 +1,11 @@
+package service
+
+import (
+	"context"
+	"project/models/response"
+)
+
+type RetentionService interface {
+	GetRetentionReport(ctx context.Context) (*response.RetentionReportResponse, error)
+	PurgeMessages(ctx context.Context) error
+}
@@ -0,0 +1,16 @@
+package service
+
+import (
+	"context"
+	"project/models/request"
+	"project/models/response"
+)
+
+type ScheduleService interface {
+	ScheduleMessage(ctx context.Context, req *request.ScheduleMessageRequest) (*response.ScheduledMessageResponse, error)
+	GetScheduledMessages(ctx context.Context, req *request.GetScheduledMessagesRequest) (*[]response.ScheduledMessageResponse, error)
+	CancelScheduledMessage(ctx context.Context, req *request.CancelScheduledMessageRequest) error
+	CreateReminder(ctx context.Context, req *request.CreateReminderRequest) (*response.ReminderResponse, error)
+	GetReminders(ctx context.Context, req *request.GetRemindersRequest) (*[]response.ReminderResponse, error)
+	CancelReminder(ctx context.Context, req *request.CancelReminderRequest) error
+}
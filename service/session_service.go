@@ -0,0 +1,15 @@
+package service
+
+import (
+	"context"
+	"project/models/request"
+	"project/models/response"
+)
+
+type SessionService interface {
+	GetSessions(ctx context.Context, req *request.GetSessionsRequest) (*[]response.SessionResponse, error)
+	RevokeSession(ctx context.Context, req *request.RevokeSessionRequest) error
+	RevokeOtherSessions(ctx context.Context, req *request.RevokeOtherSessionsRequest) (*response.RevokedSessionsResponse, error)
+	AdminRevokeSession(ctx context.Context, req *request.AdminRevokeSessionRequest) error
+	AdminRevokeSessions(ctx context.Context, req *request.AdminRevokeSessionsRequest) (*response.RevokedSessionsResponse, error)
+}
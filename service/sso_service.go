@@ -0,0 +1,17 @@
+package service
+
+import (
+	"context"
+	"project/models/request"
+	"project/models/response"
+)
+
+type SSOService interface {
+	GetProviders(ctx context.Context) (*[]response.SSOProviderResponse, error)
+	StartSSOLogin(ctx context.Context, req *request.StartSSOLoginRequest) (*response.AuthorizationResponse, error)
+	CompleteSSOLogin(ctx context.Context, req *request.CompleteSSOLoginRequest) (*response.LoginUserResponse, error)
+	StartIdentityLink(ctx context.Context, req *request.StartIdentityLinkRequest) (*response.AuthorizationResponse, error)
+	CompleteIdentityLink(ctx context.Context, req *request.CompleteIdentityLinkRequest) (*response.IdentityResponse, error)
+	GetIdentities(ctx context.Context, req *request.GetIdentitiesRequest) (*[]response.IdentityResponse, error)
+	UnlinkIdentity(ctx context.Context, req *request.UnlinkIdentityRequest) error
+}
@@ -9,6 +9,7 @@ import (
 
 type TokenService interface {
 	CreateToken(ctx context.Context, req *request.CreateTokenRequest) (string, *models.JWTClaims, error)
-	VerifyToken(ctx context.Context, signedToken string) (*models.JWTClaims, error)
+	VerifyToken(ctx context.Context, signedToken string, tokenType string) (*models.JWTClaims, error)
 	RenewAccessToken(ctx context.Context, req *request.RenewAccessTokenRequest) (*response.RenewAccessTokenResponse, error)
+	GetJWKS(ctx context.Context) (*response.JWKSResponse, error)
 }
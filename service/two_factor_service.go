@@ -0,0 +1,16 @@
+package service
+
+import (
+	"context"
+	"project/models/request"
+	"project/models/response"
+)
+
+type TwoFactorService interface {
+	GetTwoFactorStatus(ctx context.Context, req *request.GetTwoFactorStatusRequest) (*response.TwoFactorStatusResponse, error)
+	EnrollTOTP(ctx context.Context, req *request.EnrollTOTPRequest) (*response.TOTPEnrollmentResponse, error)
+	ConfirmTOTP(ctx context.Context, req *request.ConfirmTOTPRequest) (*response.RecoveryCodesResponse, error)
+	DisableTOTP(ctx context.Context, req *request.DisableTOTPRequest) error
+	RegenerateRecoveryCodes(ctx context.Context, req *request.RegenerateRecoveryCodesRequest) (*response.RecoveryCodesResponse, error)
+	AdminResetTwoFactor(ctx context.Context, req *request.AdminResetTwoFactorRequest) error
+}
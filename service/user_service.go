@@ -11,6 +11,9 @@ type UserService interface {
 	GetUserByEmail(ctx context.Context, req *request.GetUserByEmailRequest) (*response.UserResponse, error)
 	GetUserById(ctx context.Context, req *request.GetUserByIdRequest) (*response.UserResponse, error)
 	LoginUser(ctx context.Context, req *request.LoginUserRequest) (*response.LoginUserResponse, error)
+	LoginSecondFactor(ctx context.Context, req *request.LoginSecondFactorRequest) (*response.LoginUserResponse, error)
+	EnrollLoginSecondFactor(ctx context.Context, req *request.EnrollLoginSecondFactorRequest) (*response.TOTPEnrollmentResponse, error)
 	LogoutUser(ctx context.Context, req *request.LogoutUserRequest) error
 	GetUsers(ctx context.Context) (*[]response.UserResponse, error)
+	UnlockUser(ctx context.Context, req *request.UnlockUserRequest) error
 }
@@ -0,0 +1,18 @@
+package service
+
+import (
+	"context"
+	"project/models/request"
+	"project/models/response"
+)
+
+type WebhookService interface {
+	CreateIncomingWebhook(ctx context.Context, req *request.CreateIncomingWebhookRequest) (*response.IncomingWebhookResponse, error)
+	GetIncomingWebhooks(ctx context.Context, req *request.GetIncomingWebhooksRequest) (*[]response.IncomingWebhookResponse, error)
+	RevokeIncomingWebhook(ctx context.Context, req *request.RevokeIncomingWebhookRequest) error
+	PostIncomingWebhook(ctx context.Context, req *request.PostIncomingWebhookRequest) (*response.PostIncomingWebhookResponse, error)
+	CreateOutgoingWebhook(ctx context.Context, req *request.CreateOutgoingWebhookRequest) (*response.OutgoingWebhookResponse, error)
+	GetOutgoingWebhooks(ctx context.Context, req *request.GetOutgoingWebhooksRequest) (*[]response.OutgoingWebhookResponse, error)
+	RevokeOutgoingWebhook(ctx context.Context, req *request.RevokeOutgoingWebhookRequest) error
+	GetWebhookDeliveries(ctx context.Context, req *request.GetWebhookDeliveriesRequest) (*[]response.WebhookDeliveryResponse, error)
+}
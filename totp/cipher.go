@@ -0,0 +1,58 @@
+package totp
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// Cipher seals secrets at rest with AES-256-GCM. The id of the owning user
+// is authenticated with the secret, so a sealed secret copied to another row
+// does not open.
+type Cipher struct {
+	aead cipher.AEAD
+}
+
+// NewCipher returns a Cipher for a base64 encoded 32 byte key.
+func NewCipher(encodedKey string) (*Cipher, error) {
+	key, err := base64.StdEncoding.DecodeString(encodedKey)
+	if err != nil {
+		return nil, fmt.Errorf("totp encryption key is not base64: %w", err)
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("totp encryption key must be 32 bytes, got %d", len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Cipher{aead}, nil
+}
+
+func (c *Cipher) Seal(secret []byte, userId int64) ([]byte, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return c.aead.Seal(nonce, nonce, secret, additionalData(userId)), nil
+}
+
+func (c *Cipher) Open(sealed []byte, userId int64) ([]byte, error) {
+	if len(sealed) < c.aead.NonceSize() {
+		return nil, errors.New("sealed secret is too short")
+	}
+	nonce, ciphertext := sealed[:c.aead.NonceSize()], sealed[c.aead.NonceSize():]
+	return c.aead.Open(nil, nonce, ciphertext, additionalData(userId))
+}
+
+func additionalData(userId int64) []byte {
+	return []byte(fmt.Sprintf("user_totp:%d", userId))
+}
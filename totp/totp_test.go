@@ -0,0 +1,60 @@
+package totp
+
+import (
+	"testing"
+	"time"
+)
+
+// rfc6238Secret is the SHA1 key of the RFC 6238 test vectors.
+var rfc6238Secret = []byte("12345678901234567890")
+
+func TestCode(t *testing.T) {
+	// the RFC lists 8 digit codes, these are their last 6 digits
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, test := range tests {
+		if got := Code(rfc6238Secret, Step(time.Unix(test.unix, 0))); got != test.code {
+			t.Errorf("Code at %d = %s, want %s", test.unix, got, test.code)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	current := Step(now)
+
+	tests := []struct {
+		name string
+		code string
+		step int64
+		ok   bool
+	}{
+		{"current step", Code(rfc6238Secret, current), current, true},
+		{"with spaces", "050 471", current, true},
+		{"previous step", Code(rfc6238Secret, current-1), current - 1, true},
+		{"next step", Code(rfc6238Secret, current+1), current + 1, true},
+		{"two steps back", Code(rfc6238Secret, current-2), 0, false},
+		{"two steps ahead", Code(rfc6238Secret, current+2), 0, false},
+		{"wrong code", "000000", 0, false},
+		{"too short", "05047", 0, false},
+		{"too long", "0504710", 0, false},
+		{"empty", "", 0, false},
+	}
+
+	for _, test := range tests {
+		step, ok := Validate(rfc6238Secret, test.code, now)
+		if ok != test.ok || step != test.step {
+			t.Errorf("%s: Validate(%q) = %d, %v, want %d, %v", test.name, test.code, step, ok, test.step, test.ok)
+		}
+	}
+}
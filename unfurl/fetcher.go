@@ -0,0 +1,109 @@
+package unfurl
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"project/config"
+	"project/constants"
+	"project/safehttp"
+	"strings"
+)
+
+// HTTPFetcher fetches previews over HTTP, refusing non-public addresses.
+type HTTPFetcher struct {
+	client       *http.Client
+	maxBodyBytes int64
+	userAgent    string
+}
+
+func NewHTTPFetcher(cfg config.UnfurlConfig) *HTTPFetcher {
+	maxRedirects := cfg.MaxRedirects
+	client := &http.Client{
+		Transport: safehttp.NewTransport(cfg.Timeout),
+		Timeout:   cfg.Timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) > maxRedirects {
+				return fmt.Errorf("%w: stopped after %d redirects", constants.ErrNoLinkPreview, maxRedirects)
+			}
+			return checkURL(req.URL)
+		},
+	}
+
+	return &HTTPFetcher{
+		client:       client,
+		maxBodyBytes: cfg.MaxBodyBytes,
+		userAgent:    cfg.UserAgent,
+	}
+}
+
+// Fetch implements Fetcher.
+func (f *HTTPFetcher) Fetch(ctx context.Context, rawURL string) (*Preview, error) {
+	target, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkURL(target); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+	if len(f.userAgent) > 0 {
+		req.Header.Set("User-Agent", f.userAgent)
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: unexpected status %d", constants.ErrNoLinkPreview, resp.StatusCode)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return nil, fmt.Errorf("%w: unsupported content type %q", constants.ErrNoLinkPreview, mediaType)
+	}
+
+	preview, err := parseMetadata(io.LimitReader(resp.Body, f.maxBodyBytes), resp.Request.URL)
+	if err != nil {
+		return nil, err
+	}
+	preview.URL = rawURL
+	return preview, nil
+}
+
+// checkURL accepts http and https urls without credentials.
+func checkURL(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("%w: unsupported scheme %q", constants.ErrNoLinkPreview, u.Scheme)
+	}
+	if len(u.Hostname()) == 0 || u.User != nil {
+		return fmt.Errorf("%w: invalid url", constants.ErrNoLinkPreview)
+	}
+	return nil
+}
+
+// truncate cuts s to at most max characters, at a word boundary when there is
+// one close enough.
+func truncate(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+
+	cut := string(runes[:max])
+	if i := strings.LastIndexByte(cut, ' '); i > len(cut)/2 {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, " .,;:") + "…"
+}
@@ -0,0 +1,87 @@
+package unfurl
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"project/config"
+	"project/constants"
+	"testing"
+	"time"
+)
+
+// newTestFetcher returns a fetcher that reaches server when asked for
+// public.test, standing in for a public host. Every other address goes through
+// the fetcher's own dialer and its checks.
+func newTestFetcher(t *testing.T, server *httptest.Server) *HTTPFetcher {
+	t.Helper()
+
+	fetcher := NewHTTPFetcher(config.UnfurlConfig{
+		Timeout:      5 * time.Second,
+		MaxBodyBytes: 1 << 20,
+		MaxRedirects: 3,
+		UserAgent:    "test",
+	})
+	transport := fetcher.client.Transport.(*http.Transport)
+	safeDial := transport.DialContext
+	transport.DialContext = func(ctx context.Context, network, address string) (net.Conn, error) {
+		if address == "public.test:80" {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, network, server.Listener.Addr().String())
+		}
+		return safeDial(ctx, network, address)
+	}
+	return fetcher
+}
+
+func TestFetchRefusesRedirectToPrivateAddress(t *testing.T) {
+	privateHit := false
+	mux := http.NewServeMux()
+	mux.HandleFunc("/page", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<html><head><title>A page</title></head></html>`))
+	})
+	mux.HandleFunc("/private", func(w http.ResponseWriter, r *http.Request) {
+		privateHit = true
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<html><head><title>Internal</title></head></html>`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	mux.HandleFunc("/redirect", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, server.URL+"/private", http.StatusFound)
+	})
+	fetcher := newTestFetcher(t, server)
+
+	preview, err := fetcher.Fetch(context.Background(), "http://public.test/page")
+	if err != nil {
+		t.Fatalf("Fetch of the stand-in public page: %v", err)
+	}
+	if preview.Title != "A page" {
+		t.Errorf("Title = %q, want %q", preview.Title, "A page")
+	}
+
+	_, err = fetcher.Fetch(context.Background(), "http://public.test/redirect")
+	if !errors.Is(err, constants.ErrBlockedAddress) {
+		t.Errorf("Fetch following a redirect to %s = %v, want ErrBlockedAddress", server.URL, err)
+	}
+	if privateHit {
+		t.Error("the private address was reached")
+	}
+}
+
+func TestFetchRefusesPrivateAddress(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("the private address was reached")
+	}))
+	defer server.Close()
+
+	fetcher := NewHTTPFetcher(config.UnfurlConfig{Timeout: 5 * time.Second, MaxBodyBytes: 1 << 20})
+	_, err := fetcher.Fetch(context.Background(), server.URL)
+	if !errors.Is(err, constants.ErrBlockedAddress) {
+		t.Errorf("Fetch(%s) = %v, want ErrBlockedAddress", server.URL, err)
+	}
+}
@@ -0,0 +1,124 @@
+package unfurl
+
+import (
+	"io"
+	"net/url"
+	"project/constants"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+const (
+	maxTitleLength       = 200
+	maxDescriptionLength = 500
+	maxSiteNameLength    = 100
+)
+
+// parseMetadata reads the head of a page. base is the url the page was served
+// from, after redirects, and resolves relative image urls.
+func parseMetadata(body io.Reader, base *url.URL) (*Preview, error) {
+	meta := make(map[string]string)
+	var title strings.Builder
+	inTitle := false
+
+	tokenizer := html.NewTokenizer(body)
+	for done := false; !done; {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			// io.EOF, or the size limit cut the page short; use what was read
+			done = true
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := tokenizer.Token()
+			switch token.DataAtom {
+			case atom.Title:
+				inTitle = true
+			case atom.Meta:
+				key, content := metaAttributes(token)
+				// the first occurrence wins, as with browsers
+				if _, ok := meta[key]; !ok && len(key) > 0 {
+					meta[key] = content
+				}
+			case atom.Body:
+				done = true
+			}
+
+		case html.EndTagToken:
+			switch tokenizer.Token().DataAtom {
+			case atom.Title:
+				inTitle = false
+			case atom.Head:
+				done = true
+			}
+
+		case html.TextToken:
+			if inTitle {
+				title.Write(tokenizer.Text())
+			}
+		}
+	}
+
+	preview := &Preview{
+		Title:       first(meta["og:title"], meta["twitter:title"], title.String()),
+		Description: first(meta["og:description"], meta["twitter:description"], meta["description"]),
+		SiteName:    first(meta["og:site_name"]),
+		ImageURL:    resolveImage(base, first(meta["og:image:secure_url"], meta["og:image:url"], meta["og:image"], meta["twitter:image"])),
+	}
+	if len(preview.Title) == 0 {
+		return nil, constants.ErrNoLinkPreview
+	}
+
+	preview.Title = truncate(preview.Title, maxTitleLength)
+	preview.Description = truncate(preview.Description, maxDescriptionLength)
+	preview.SiteName = truncate(preview.SiteName, maxSiteNameLength)
+	return preview, nil
+}
+
+// metaAttributes returns the lower cased property, or name, of a meta tag and
+// its content.
+func metaAttributes(token html.Token) (string, string) {
+	var key, content string
+	for _, attr := range token.Attr {
+		switch strings.ToLower(attr.Key) {
+		case "property":
+			key = attr.Val
+		case "name":
+			if len(key) == 0 {
+				key = attr.Val
+			}
+		case "content":
+			content = attr.Val
+		}
+	}
+	return strings.ToLower(strings.TrimSpace(key)), content
+}
+
+// first returns the first value that is not blank, with whitespace collapsed.
+func first(values ...string) string {
+	for _, value := range values {
+		if value = strings.Join(strings.Fields(value), " "); len(value) > 0 {
+			return value
+		}
+	}
+	return ""
+}
+
+// resolveImage makes the image url absolute and drops it unless it is http or
+// https. Clients fetch images themselves, so the address is not checked here.
+func resolveImage(base *url.URL, raw string) string {
+	if len(raw) == 0 {
+		return ""
+	}
+
+	ref, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	image := base.ResolveReference(ref)
+	if image.Scheme != "http" && image.Scheme != "https" {
+		return ""
+	}
+	return image.String()
+}
@@ -0,0 +1,111 @@
+// Package unfurl builds link previews from the OpenGraph and title metadata
+// of shared pages.
+//
+// Pages are fetched on behalf of whoever posted the link, so the fetcher
+// treats every URL as hostile: it only speaks http and https, refuses to
+// connect to loopback, private, link-local and other non-public addresses
+// (checked on every connection, redirects included, so DNS answers cannot
+// sneak one in), follows a bounded number of redirects and reads a bounded
+// number of bytes within a deadline.
+package unfurl
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"project/constants"
+	"project/logger"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const cacheKeyPrefix = "unfurl:"
+
+type Preview struct {
+	URL         string `json:"url"` // the link as shared
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	SiteName    string `json:"siteName,omitempty"`
+	ImageURL    string `json:"imageUrl,omitempty"`
+}
+
+// Fetcher returns the preview of a page. Pages without usable metadata return
+// constants.ErrNoLinkPreview.
+type Fetcher interface {
+	Fetch(ctx context.Context, url string) (*Preview, error)
+}
+
+// CachedFetcher remembers the previews of another Fetcher in redis, so a link
+// shared in several channels or on several nodes is fetched once. Failures
+// are remembered too, for a shorter while, so a dead link is not retried on
+// every message.
+type CachedFetcher struct {
+	fetcher     Fetcher
+	redisClient *redis.Client
+	ttl         time.Duration
+	failureTTL  time.Duration
+}
+
+func NewCachedFetcher(fetcher Fetcher, redisClient *redis.Client, ttl time.Duration, failureTTL time.Duration) *CachedFetcher {
+	return &CachedFetcher{
+		fetcher:     fetcher,
+		redisClient: redisClient,
+		ttl:         ttl,
+		failureTTL:  failureTTL,
+	}
+}
+
+// Fetch implements Fetcher. A redis outage only costs the cache, the page is
+// fetched anyway.
+func (f *CachedFetcher) Fetch(ctx context.Context, url string) (*Preview, error) {
+	key := cacheKey(url)
+
+	cached, err := f.redisClient.Get(ctx, key).Bytes()
+	switch {
+	case err == nil && len(cached) == 0:
+		return nil, constants.ErrNoLinkPreview
+	case err == nil:
+		preview := &Preview{}
+		if err := json.Unmarshal(cached, preview); err == nil {
+			return preview, nil
+		}
+	case !errors.Is(err, redis.Nil):
+		logger.Error(ctx, "Fetch :: failed to read preview cache", logger.Field("error", err.Error()))
+	}
+
+	preview, fetchErr := f.fetcher.Fetch(ctx, url)
+	if fetchErr != nil {
+		// cancellations say nothing about the link
+		if ctx.Err() == nil {
+			f.store(ctx, key, nil, f.failureTTL)
+		}
+		return nil, fetchErr
+	}
+
+	value, err := json.Marshal(preview)
+	if err != nil {
+		return nil, err
+	}
+	f.store(ctx, key, value, f.ttl)
+	return preview, nil
+}
+
+func (f *CachedFetcher) store(ctx context.Context, key string, value []byte, ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+
+	err := f.redisClient.Set(ctx, key, value, ttl).Err()
+	if err != nil {
+		logger.Error(ctx, "store :: failed to cache preview", logger.Field("error", err.Error()))
+	}
+}
+
+// cacheKey hashes the url, which keeps keys short whatever was shared.
+func cacheKey(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return cacheKeyPrefix + hex.EncodeToString(sum[:])
+}
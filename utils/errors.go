@@ -2,8 +2,11 @@ package utils
 
 import (
 	"errors"
+	"fmt"
+	"math"
 	"net/http"
 	"project/constants"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgconn"
 )
@@ -16,15 +19,112 @@ func ErrorCode(err error) string {
 	return ""
 }
 
+// ConstraintName returns the constraint a postgres error violated, the name
+// of the index for unique indexes.
+func ConstraintName(err error) string {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.ConstraintName
+	}
+	return ""
+}
+
+type retryAfterError struct {
+	err  error
+	wait time.Duration
+}
+
+func (e *retryAfterError) Error() string {
+	return fmt.Sprintf("%s, retry in %ds", e.err.Error(), RetryAfterSeconds(e.wait))
+}
+
+func (e *retryAfterError) Unwrap() error {
+	return e.err
+}
+
+// RetryAfterError wraps err with the time to wait before retrying.
+func RetryAfterError(err error, wait time.Duration) error {
+	return &retryAfterError{err, wait}
+}
+
+// RetryAfter returns the time to wait carried by err, if any.
+func RetryAfter(err error) (time.Duration, bool) {
+	var retryErr *retryAfterError
+	if errors.As(err, &retryErr) {
+		return retryErr.wait, true
+	}
+	return 0, false
+}
+
+// RetryAfterSeconds rounds wait up to whole seconds, as Retry-After expects.
+func RetryAfterSeconds(wait time.Duration) int {
+	return int(math.Ceil(wait.Seconds()))
+}
+
 func GetHTTPStatusCode(err error) int {
 	switch err {
-	case constants.ErrPasswordIncorrect, constants.ErrTokenExpired, constants.ErrTokenInvalid, constants.ErrSessionBlocked, constants.ErrSessionExpired,
-		constants.ErrLoggedOutSession, constants.ErrIncorrectSessionUser, constants.ErrIncorrectSessionToken, constants.ErrAccessDenied, constants.ErrEmptyAuthHeader,
-		constants.ErrInvalidAuthHeader:
+	case constants.ErrPasswordIncorrect, constants.ErrTokenExpired, constants.ErrTokenInvalid, constants.ErrWrongTokenType, constants.ErrTokenRevoked, constants.ErrSessionBlocked, constants.ErrSessionExpired,
+		constants.ErrLoggedOutSession, constants.ErrIncorrectSessionUser, constants.ErrIncorrectSessionToken, constants.ErrRefreshTokenReused, constants.ErrAccessDenied, constants.ErrEmptyAuthHeader,
+		constants.ErrInvalidAuthHeader, constants.ErrInvalidTwoFactorCode, constants.ErrChallengeUsed:
 		return http.StatusUnauthorized
-	case constants.ErrNoRows:
+	case constants.ErrScheduleInPast, constants.ErrInvalidRetentionPolicy, constants.ErrPollClosesInPast, constants.ErrInvalidPollOption,
+		constants.ErrSingleChoicePoll, constants.ErrInvalidMessageEncoding, constants.ErrInvalidUserToken, constants.ErrInvalidSSOState,
+		constants.ErrInvalidTimeZone, constants.ErrInvalidImage:
+		return http.StatusBadRequest
+	case constants.ErrNotChannelMember, constants.ErrNotChannelAdmin, constants.ErrAdminRequired, constants.ErrNotBotOwner, constants.ErrInsufficientScope,
+		constants.ErrChannelMuted, constants.ErrEmailNotVerified, constants.ErrTwoFactorRequired, constants.ErrSSOEmailNotVerified, constants.ErrSSODomainNotAllowed,
+		constants.ErrSSOAccountNotFound:
+		return http.StatusForbidden
+	case constants.ErrPinLimitReached, constants.ErrPollClosed, constants.ErrHeldMessageReviewed, constants.ErrTwoFactorEnabled, constants.ErrTwoFactorNotEnabled,
+		constants.ErrSSOAccountExists, constants.ErrIdentityLinked, constants.ErrLastLoginMethod, constants.ErrUsernameTaken, constants.ErrEmailTaken,
+		constants.ErrAlreadyChannelMember:
+		return http.StatusConflict
+	case constants.ErrImageTooLarge:
+		return http.StatusRequestEntityTooLarge
+	case constants.ErrRateLimited:
+		return http.StatusTooManyRequests
+	case constants.ErrTwoFactorUnavailable:
+		return http.StatusServiceUnavailable
+	case constants.ErrNoRows, constants.ErrMessageNotInChannel, constants.ErrUnknownProvider:
 		return http.StatusNotFound
 	default:
+		// archive errors are wrapped with the offending record
+		if errors.Is(err, constants.ErrInvalidArchive) {
+			return http.StatusBadRequest
+		}
+		// carries the length of the message
+		if errors.Is(err, constants.ErrMessageTooLong) {
+			return http.StatusBadRequest
+		}
+		// carries the rule the password breaks
+		if errors.Is(err, constants.ErrWeakPassword) {
+			return http.StatusBadRequest
+		}
+		// carry the host or address of the webhook url
+		if errors.Is(err, constants.ErrInvalidWebhookURL) || errors.Is(err, constants.ErrBlockedAddress) {
+			return http.StatusBadRequest
+		}
+		// moderation errors carry the reason given by the filter
+		if errors.Is(err, constants.ErrMessageRejected) || errors.Is(err, constants.ErrInvalidModerationRule) {
+			return http.StatusBadRequest
+		}
+		// carry the time to wait
+		if errors.Is(err, constants.ErrRateLimited) || errors.Is(err, constants.ErrSlowMode) || errors.Is(err, constants.ErrLoginThrottled) {
+			return http.StatusTooManyRequests
+		}
+		if errors.Is(err, constants.ErrAccountLocked) {
+			return http.StatusLocked
+		}
+		if errors.Is(err, constants.ErrMessageHeld) {
+			return http.StatusAccepted
+		}
+		if errors.Is(err, constants.ErrArchiveUserNotFound) {
+			return http.StatusUnprocessableEntity
+		}
+		// carries what the identity provider got wrong
+		if errors.Is(err, constants.ErrSSOFailed) {
+			return http.StatusBadGateway
+		}
 		errCode := ErrorCode(err)
 		if errCode == constants.ForeignKeyViolation || errCode == constants.UniqueViolation {
 			return http.StatusUnprocessableEntity
@@ -0,0 +1,67 @@
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"project/constants"
+	"project/safehttp"
+	"time"
+)
+
+// maxRedirects bounds the redirects followed by a webhook request.
+const maxRedirects = 3
+
+// NewClient returns the client webhook requests are sent with. Webhook urls
+// are given by users, so like link previews it only connects to public
+// addresses; the check runs on every dial, redirects included, and DNS
+// answers that change after CheckURL accepted the url are caught too.
+func NewClient(timeout time.Duration) *http.Client {
+	return &http.Client{
+		Transport: safehttp.NewTransport(timeout),
+		Timeout:   timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) > maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+			return checkScheme(req.URL)
+		},
+	}
+}
+
+// CheckURL accepts http and https urls whose host resolves to public
+// addresses only. It is meant for when a url is saved, NewClient still checks
+// each connection.
+func CheckURL(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("%w: %s", constants.ErrInvalidWebhookURL, err.Error())
+	}
+	if err := checkScheme(u); err != nil {
+		return err
+	}
+
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", u.Hostname())
+	if err != nil {
+		return fmt.Errorf("%w: %s", constants.ErrInvalidWebhookURL, err.Error())
+	}
+	for _, addr := range addrs {
+		if safehttp.IsBlockedAddress(addr) {
+			return fmt.Errorf("%w: %s", constants.ErrBlockedAddress, addr.Unmap())
+		}
+	}
+	return nil
+}
+
+// checkScheme accepts http and https urls without credentials.
+func checkScheme(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("%w: unsupported scheme %q", constants.ErrInvalidWebhookURL, u.Scheme)
+	}
+	if len(u.Hostname()) == 0 || u.User != nil {
+		return constants.ErrInvalidWebhookURL
+	}
+	return nil
+}
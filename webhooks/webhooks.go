@@ -0,0 +1,149 @@
+// Package webhooks holds the parts of outgoing webhooks shared by the message
+// write path and the dispatcher: the payload, the outbox writer and request
+// signing.
+//
+// Every request carries:
+//
+//	X-Chat-Event:     the event name, "message.created"
+//	X-Chat-Delivery:  the outbox entry id, stable across retries
+//	X-Chat-Timestamp: unix seconds at which the request was signed
+//	X-Chat-Signature: "sha256=" + hex(HMAC-SHA256(secret, timestamp + "." + body))
+//
+// Receivers should recompute the signature, compare it in constant time and
+// reject timestamps too far from their clock.
+package webhooks
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	db "project/db/sqlc"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	EventMessageCreated = "message.created"
+	EventCommand        = "command"
+
+	HeaderEvent     = "X-Chat-Event"
+	HeaderDelivery  = "X-Chat-Delivery"
+	HeaderTimestamp = "X-Chat-Timestamp"
+	HeaderSignature = "X-Chat-Signature"
+
+	signaturePrefix = "sha256="
+)
+
+type Payload struct {
+	Event       string         `json:"event"`
+	WebhookId   int64          `json:"webhookId"`
+	ChannelId   int64          `json:"channelId"`
+	TriggerWord string         `json:"triggerWord,omitempty"`
+	Message     PayloadMessage `json:"message"`
+}
+
+type PayloadMessage struct {
+	Id        int64     `json:"id"`
+	UserId    int64     `json:"userId"`
+	Username  string    `json:"username"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Enqueue writes an outbox entry for every active outgoing webhook of the
+// channel that the message triggers. It must run on the transaction that
+// inserts the message, so an entry exists if and only if the message does.
+// Bot messages never trigger webhooks, which keeps an integration from
+// answering itself in a loop.
+func Enqueue(ctx context.Context, q *db.Queries, message *db.Message, username string) error {
+	if message.IsBot {
+		return nil
+	}
+
+	webhooks, err := q.GetOutgoingWebhooksByChannelId(ctx, message.ChannelID)
+	if err != nil {
+		return err
+	}
+
+	for _, webhook := range webhooks {
+		triggerWord, ok := MatchTrigger(webhook.TriggerWords, message.Content)
+		if !ok {
+			continue
+		}
+
+		payload, err := json.Marshal(&Payload{
+			Event:       EventMessageCreated,
+			WebhookId:   webhook.ID,
+			ChannelId:   message.ChannelID,
+			TriggerWord: triggerWord,
+			Message: PayloadMessage{
+				Id:        message.ID,
+				UserId:    message.UserID,
+				Username:  username,
+				Content:   message.Content,
+				CreatedAt: message.CreatedAt,
+			},
+		})
+		if err != nil {
+			return err
+		}
+
+		err = q.CreateWebhookOutboxEntry(ctx, &db.CreateWebhookOutboxEntryParams{
+			WebhookID: webhook.ID,
+			MessageID: message.ID,
+			Payload:   payload,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// MatchTrigger reports whether content starts with one of the trigger words,
+// ignoring case. A webhook without trigger words matches every message.
+func MatchTrigger(triggerWords []string, content string) (string, bool) {
+	if len(triggerWords) == 0 {
+		return "", true
+	}
+
+	fields := strings.Fields(content)
+	if len(fields) == 0 {
+		return "", false
+	}
+	for _, triggerWord := range triggerWords {
+		if strings.EqualFold(fields[0], triggerWord) {
+			return triggerWord, true
+		}
+	}
+	return "", false
+}
+
+// Sign returns the X-Chat-Signature value of body sent at timestamp.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the signature and timestamp headers of a received request,
+// rejecting requests signed more than tolerance away from now.
+func Verify(secret string, signature string, timestamp string, body []byte, tolerance time.Duration) bool {
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+
+	age := time.Since(time.Unix(ts, 0))
+	if age > tolerance || age < -tolerance {
+		return false
+	}
+
+	return hmac.Equal([]byte(signature), []byte(Sign(secret, ts, body)))
+}